@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetRollout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/apps/foo/rollout" || r.URL.Query().Get("client_id") != "c1" {
+			http.Error(w, `{"error":"rota inesperada"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"app":"foo","version":"1.2.3","rollout_percent":50,"bucket":43,"eligible":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClientWithResponses(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.GetRolloutWithResponse(context.Background(), "foo", &GetRolloutParams{ClientID: "c1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RolloutStatus{App: "foo", Version: "1.2.3", RolloutPercent: 50, Bucket: 43, Eligible: true}
+	if resp.JSON200 == nil || *resp.JSON200 != want {
+		t.Errorf("resposta = %d %s", resp.StatusCode(), resp.Body)
+	}
+}
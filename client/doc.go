@@ -0,0 +1,9 @@
+// Package client é o cliente Go da API do updater-registry no modo servidor (generator serve),
+// gerado de cmd/generator/openapi.json. Exemplo:
+//
+//	c, err := client.NewClientWithResponses("https://updates.exemplo.org")
+//	resp, err := c.GetAppWithResponse(ctx, "foo")
+//	fmt.Println(resp.JSON200.LatestVersion)
+package client
+
+//go:generate go tool oapi-codegen -config oapi-codegen.yaml ../cmd/generator/openapi.json
@@ -0,0 +1,63 @@
+package main
+
+import "sort"
+
+// ==========================================
+// ALIASES (APPS RENOMEADOS)
+// ==========================================
+
+// aliasMap monta o mapa id antigo -> id atual declarado nas fontes (campo aliases), publicado
+// no catálogo para que clientes que ainda usam o id antigo encontrem a entrada nova.
+func aliasMap(sourceLists ...[]SourceApp) map[string]string {
+	aliases := make(map[string]string)
+	for _, list := range sourceLists {
+		for _, src := range list {
+			for _, alias := range src.Aliases {
+				aliases[alias] = src.ID
+			}
+		}
+	}
+	if len(aliases) == 0 {
+		return nil
+	}
+	return aliases
+}
+
+// migrateAliases move para o id atual as entradas do catálogo ainda gravadas sob um alias,
+// para que a renomeação não descarte a versão publicada (nem force um novo download). Se o
+// id atual já tiver entrada, a do alias é só descartada.
+func migrateAliases(catalog *Catalog, aliases map[string]string) {
+	olds := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		olds = append(olds, alias)
+	}
+	sort.Strings(olds)
+
+	for _, alias := range olds {
+		app, ok := catalog.Apps[alias]
+		if !ok {
+			continue
+		}
+		id := aliases[alias]
+		delete(catalog.Apps, alias)
+		if _, exists := catalog.Apps[id]; exists {
+			verbosef("     %s: entrada antiga sob o alias %s descartada", id, alias)
+			continue
+		}
+		app.ID = id
+		catalog.Apps[id] = app
+		infof(" [RENOMEADO] %s → %s: entrada migrada do id antigo.", alias, id)
+	}
+}
+
+// lookup encontra a entrada pelo id ou por um alias dele.
+func (c Catalog) lookup(id string) (CatalogApp, bool) {
+	if app, ok := c.Apps[id]; ok {
+		return app, true
+	}
+	if current, ok := c.Aliases[id]; ok {
+		app, ok := c.Apps[current]
+		return app, ok
+	}
+	return CatalogApp{}, false
+}
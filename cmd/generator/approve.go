@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+)
+
+// ==========================================
+// APROVAÇÃO MANUAL DE ATUALIZAÇÕES
+// ==========================================
+
+// PendingChanges é o arquivo de atualizações retidas (config "pending"): o generate grava aqui
+// o que detectou, em vez de publicar, e o "approve" aplica ao catálogo o que foi revisado.
+type PendingChanges struct {
+	Changes map[string]PendingChange `json:"changes"`
+}
+
+// PendingChange é uma atualização detectada aguardando aprovação.
+type PendingChange struct {
+	OldVersion string     `json:"old_version,omitempty"` // Versão publicada quando a mudança foi detectada (vazia = app novo)
+	DetectedAt time.Time  `json:"detected_at"`
+	App        CatalogApp `json:"app"` // Entrada completa, como seria publicada
+}
+
+// loadPending lê o arquivo de pendências; inexistente equivale a nenhuma pendência.
+func loadPending(location string) (PendingChanges, error) {
+	pending := PendingChanges{Changes: make(map[string]PendingChange)}
+	data, err := readBlob(location)
+	if errors.Is(err, os.ErrNotExist) {
+		return pending, nil
+	}
+	if err != nil {
+		return pending, err
+	}
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return pending, fmt.Errorf("%s: %w", location, err)
+	}
+	if pending.Changes == nil {
+		pending.Changes = make(map[string]PendingChange)
+	}
+	return pending, nil
+}
+
+func savePending(location string, pending PendingChanges) error {
+	data, _ := json.MarshalIndent(pending, "", "  ")
+	return writeBlob(location, data, "application/json")
+}
+
+// pendingOverlay carrega as pendências quando a aprovação manual está ativa e devolve também a
+// base de comparação das checagens (ver overlay); sem aprovação, nil e o próprio catálogo.
+func pendingOverlay(catalog Catalog) (*PendingChanges, Catalog) {
+	if cfg.PendingPath == "" {
+		return nil, catalog
+	}
+	pending, err := loadPending(cfg.PendingPath)
+	if err != nil {
+		log.Fatalf("Falha ao ler as pendências %s: %v", cfg.PendingPath, err)
+	}
+	return &pending, pending.overlay(catalog)
+}
+
+// overlay devolve uma cópia do catálogo com as entradas pendentes no lugar das publicadas. É
+// a base de comparação do generate: uma atualização já retida não é detectada (e baixada) de novo.
+func (p PendingChanges) overlay(catalog Catalog) Catalog {
+	out := catalog
+	out.Apps = make(map[string]CatalogApp, len(catalog.Apps))
+	for id, app := range catalog.Apps {
+		out.Apps[id] = app
+	}
+	for id, change := range p.Changes {
+		out.Apps[id] = change.App
+	}
+	return out
+}
+
+// hold retém a atualização de res nas pendências e devolve o resultado com a entrada
+// publicada atual, da qual só last_checked avança. Uma checagem sem mudança de um app já
+// pendente atualiza a entrada pendente.
+func (p PendingChanges) hold(res appResult, published Catalog) appResult {
+	change, isPending := p.Changes[res.ID]
+	if !res.HasApp || (!res.Changed && !isPending) {
+		return res
+	}
+
+	if res.Changed || !isPending {
+		oldVersion := ""
+		if old, ok := published.Apps[res.ID]; ok {
+			oldVersion = old.Version
+		}
+		change = PendingChange{OldVersion: oldVersion, DetectedAt: time.Now().UTC()}
+		infof(" [PENDENTE] %s: %s aguardando aprovação (approve %s).", res.ID, res.App.Version, res.ID)
+	}
+	change.App = res.App
+	p.Changes[res.ID] = change
+
+	old, exists := published.Apps[res.ID]
+	if exists && res.Checked {
+		old.LastChecked = res.App.LastChecked
+	}
+	res.App, res.HasApp = old, exists
+	res.Pending, res.Changed = res.Changed, false
+	return res
+}
+
+// ------------------------------------------
+// Subcomando approve
+// ------------------------------------------
+
+// Flags do approve, registradas por approveFlags.
+var (
+	approveAll    *bool
+	approveReject *bool
+)
+
+func approveFlags(fs *flag.FlagSet) {
+	approveAll = fs.Bool("all", false, "approve: aplica todas as atualizações pendentes")
+	approveReject = fs.Bool("reject", false, "approve: descarta as pendências indicadas em vez de aplicá-las")
+}
+
+// runApprove aplica (ou descarta, com -reject) atualizações pendentes. Sem ids nem -all,
+// lista o que está pendente.
+func runApprove(ids []string) {
+	if cfg.PendingPath == "" {
+		log.Fatal("approve: nenhum arquivo de pendências configurado (pending, UPDATER_PENDING ou -pending)")
+	}
+	pending, err := loadPending(cfg.PendingPath)
+	if err != nil {
+		log.Fatalf("Falha ao ler as pendências %s: %v", cfg.PendingPath, err)
+	}
+
+	if *approveAll {
+		ids = ids[:0]
+		for id := range pending.Changes {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+	}
+	if len(ids) == 0 {
+		listPending(pending)
+		return
+	}
+	for _, id := range ids {
+		if _, ok := pending.Changes[id]; !ok {
+			log.Fatalf("approve: %s não tem atualização pendente", id)
+		}
+	}
+
+	target, catalog := workingCatalog()
+	var approved []ChangelogEntry
+	for _, id := range ids {
+		change := pending.Changes[id]
+		delete(pending.Changes, id)
+		if *approveReject {
+			infof(">>> %s: atualização para %s descartada", id, change.App.Version)
+			continue
+		}
+		catalog.Apps[id] = change.App
+		approved = append(approved, ChangelogEntry{
+			ID:   id,
+			Name: change.App.Name,
+			From: change.OldVersion,
+			To:   change.App.Version,
+			Size: change.App.Size,
+		})
+		infof(">>> %s: %s aprovado", id, change.App.Version)
+	}
+
+	// O catálogo primeiro: se falhar, as pendências continuam lá para uma nova tentativa
+	if !*approveReject {
+		catalog.LastUpdated = time.Now()
+		catalog.Stats = computeStats(catalog.Apps)
+		saveCatalog(target, catalog)
+		infof(">>> Catálogo %s atualizado", target)
+	}
+	if err := savePending(cfg.PendingPath, pending); err != nil {
+		log.Fatalf("Falha ao gravar as pendências %s: %v", cfg.PendingPath, err)
+	}
+	if cfg.ChangelogPath != "" {
+		if err := recordChangelog(cfg.ChangelogPath, catalog.LastUpdated, approved); err != nil {
+			errorf(" [ERRO] Falha ao atualizar o changelog %s: %v", cfg.ChangelogPath, err)
+		}
+	}
+}
+
+func listPending(pending PendingChanges) {
+	if len(pending.Changes) == 0 {
+		infof(">>> Nenhuma atualização pendente.")
+		return
+	}
+	ids := make([]string, 0, len(pending.Changes))
+	for id := range pending.Changes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		change := pending.Changes[id]
+		from := change.OldVersion
+		if from == "" {
+			from = "novo"
+		}
+		fmt.Printf("%s\t%s → %s\t%s\tdetectado em %s\n", id, from, change.App.Version, change.App.DownloadURL, change.DetectedAt.Format(time.RFC3339))
+	}
+}
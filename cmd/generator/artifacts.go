@@ -0,0 +1,152 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ==========================================
+// ARTEFATOS EM DISCO
+// ==========================================
+
+// O download é gravado num arquivo temporário enquanto os hashes são calculados (ver
+// downloadAndHash), para que o que precisa dos bytes depois (extração de metadados, mirrors,
+// deltas) não baixe o artefato de novo. O arquivo vive até o fim da execução (ver
+// downloadMemo.cleanup) ou, fora do generate/apply, até o fim do app. Com artifacts.dir, a
+// versão publicada fica guardada em dir/{id}/{versão}/{arquivo}.
+
+// ArtifactsConfig configura a retenção dos artefatos baixados.
+type ArtifactsConfig struct {
+	Dir  string `json:"dir"`  // Onde guardar os artefatos publicados (vazio = apagados ao fim da execução)
+	Keep int    `json:"keep"` // Versões guardadas por app (padrão: 2, a atual e a anterior)
+}
+
+func (a ArtifactsConfig) enabled() bool {
+	return a.Dir != ""
+}
+
+func (a ArtifactsConfig) keep() int {
+	if a.Keep > 0 {
+		return a.Keep
+	}
+	return 2
+}
+
+// spoolFile cria o arquivo temporário de um download. Com retenção ele fica dentro de
+// artifacts.dir, para que guardar a versão seja só um link.
+func spoolFile() (*os.File, error) {
+	dir := os.TempDir()
+	if cfg.Artifacts.enabled() {
+		dir = filepath.Join(cfg.Artifacts.Dir, ".spool")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	return os.CreateTemp(dir, "download-*")
+}
+
+// discard apaga o arquivo do download (sem efeito quando não há arquivo).
+func (d downloadResult) discard() {
+	if d.Path != "" {
+		os.Remove(d.Path)
+	}
+}
+
+// versionDirReplacer deixa a versão segura como nome de diretório.
+var versionDirReplacer = strings.NewReplacer("/", "_", "\\", "_", "..", "_")
+
+// retainArtifact guarda o artefato de app em artifacts.dir (com o nome do arquivo na URL) e
+// apaga as versões além de artifacts.keep, das mais antigas para as mais novas.
+func retainArtifact(app CatalogApp, dl downloadResult) error {
+	if !cfg.Artifacts.enabled() || dl.Path == "" {
+		return nil
+	}
+	appDir := filepath.Join(cfg.Artifacts.Dir, app.ID)
+	dir := filepath.Join(appDir, versionDirReplacer.Replace(app.Version))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	target := filepath.Join(dir, artifactName(app.DownloadURL))
+	os.Remove(target) // Mesma versão baixada de novo (ex: versão provisória)
+	if err := os.Link(dl.Path, target); err != nil {
+		if err := copyArtifact(dl.Path, target); err != nil {
+			return err
+		}
+	}
+	debugf("     %s: artefato guardado em %s", app.ID, target)
+	return pruneArtifacts(appDir, dir)
+}
+
+// artifactName extrai o nome do arquivo da URL (ex: foo_1.2.3_amd64.deb).
+func artifactName(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		if name := path.Base(u.Path); name != "/" && name != "." && name != "" {
+			return name
+		}
+	}
+	return "artifact"
+}
+
+// copyArtifact copia o arquivo quando o link não é possível (ex: outro sistema de arquivos).
+func copyArtifact(from, to string) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(to)
+		return err
+	}
+	return out.Close()
+}
+
+// pruneArtifacts mantém em appDir só as artifacts.keep versões mais recentes (pela data de
+// modificação); current nunca é apagada.
+func pruneArtifacts(appDir, current string) error {
+	entries, err := os.ReadDir(appDir)
+	if err != nil {
+		return err
+	}
+	type version struct {
+		dir   string
+		mtime int64
+	}
+	var versions []version
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		versions = append(versions, version{filepath.Join(appDir, entry.Name()), info.ModTime().UnixNano()})
+	}
+	slices.SortFunc(versions, func(a, b version) int { return cmp.Compare(b.mtime, a.mtime) })
+
+	kept := 1 // current
+	for _, v := range versions {
+		if v.dir == current {
+			continue
+		}
+		if kept < cfg.Artifacts.keep() {
+			kept++
+			continue
+		}
+		if err := os.RemoveAll(v.dir); err != nil {
+			return fmt.Errorf("falha ao apagar %s: %w", v.dir, err)
+		}
+		debugf("     artefatos: %s apagado (artifacts.keep = %d)", v.dir, cfg.Artifacts.keep())
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ==========================================
+// AUTENTICAÇÃO HTTP POR FONTE
+// ==========================================
+
+// applySourceAuth autentica uma requisição da fonte (HEAD da checagem, GET do download,
+// http.get/http.head de scripts). Config aceita:
+//   - username_env, password_env: HTTP Basic (as mesmas chaves do FTP)
+//   - token_env: variável de ambiente com um token enviado como Bearer (ex: access token do Artifactory)
+//   - oauth_token_url, oauth_client_id_env, oauth_client_secret_env: token Bearer obtido por
+//     OAuth2 client credentials (ver oauthToken); oauth_scope e oauth_audience são opcionais
+//   - auth_hosts: hosts que recebem a credencial, separados por vírgula (padrão: o host da 'url')
+//
+// O download costuma sair de uma CDN (URL final após redirects) que não deve receber a
+// credencial — e o net/http já não repete o header Authorization em redirects para outro domínio.
+func applySourceAuth(req *http.Request, conf map[string]string) error {
+	if !slices.Contains(authHosts(conf), req.URL.Hostname()) {
+		return nil
+	}
+
+	if conf["oauth_token_url"] != "" {
+		token, err := oauthToken(req.Context(), conf)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+	if env := conf["token_env"]; env != "" {
+		token := os.Getenv(env)
+		if token == "" {
+			return fmt.Errorf("token: variável %s vazia", env)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+	if env := conf["username_env"]; env != "" {
+		user := os.Getenv(env)
+		if user == "" {
+			return fmt.Errorf("basic auth: variável %s vazia", env)
+		}
+		req.SetBasicAuth(user, os.Getenv(conf["password_env"]))
+	}
+	return nil
+}
+
+// authHosts lista os hosts autorizados a receber credenciais da fonte.
+func authHosts(conf map[string]string) []string {
+	if v := conf["auth_hosts"]; v != "" {
+		var hosts []string
+		for _, h := range strings.Split(v, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				hosts = append(hosts, h)
+			}
+		}
+		return hosts
+	}
+	if u, err := url.Parse(conf["url"]); err == nil && u.Hostname() != "" {
+		return []string{u.Hostname()}
+	}
+	return nil
+}
+
+// ------------------------------------------
+// OAuth2 client credentials
+// ------------------------------------------
+
+// oauthRefreshMargin antecipa a renovação para o token não expirar no meio de um download.
+const oauthRefreshMargin = time.Minute
+
+// oauthTokens guarda os tokens por (token_url, client_id, scope, audience) durante o processo:
+// fontes do mesmo fornecedor compartilham o token, e o modo servidor o renova ao expirar.
+var oauthTokens sync.Map // string -> *oauthEntry
+
+type oauthEntry struct {
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// oauthToken devolve um token válido, pedindo um novo ao token_url quando não há ou está
+// perto de expirar. Por padrão as credenciais vão em HTTP Basic, como recomenda a RFC 6749;
+// oauth_client_auth: "body" as envia no corpo, para servidores que só aceitam assim.
+func oauthToken(ctx context.Context, conf map[string]string) (string, error) {
+	clientID := os.Getenv(conf["oauth_client_id_env"])
+	secret := os.Getenv(conf["oauth_client_secret_env"])
+	if clientID == "" || secret == "" {
+		return "", fmt.Errorf("oauth: 'oauth_client_id_env' e 'oauth_client_secret_env' devem apontar para variáveis preenchidas")
+	}
+
+	key := strings.Join([]string{conf["oauth_token_url"], clientID, conf["oauth_scope"], conf["oauth_audience"]}, "\x00")
+	v, _ := oauthTokens.LoadOrStore(key, &oauthEntry{})
+	entry := v.(*oauthEntry)
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.token != "" && time.Now().Add(oauthRefreshMargin).Before(entry.expires) {
+		return entry.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if v := conf["oauth_scope"]; v != "" {
+		form.Set("scope", v)
+	}
+	if v := conf["oauth_audience"]; v != "" {
+		form.Set("audience", v)
+	}
+	bodyAuth := conf["oauth_client_auth"] == "body"
+	if bodyAuth {
+		form.Set("client_id", clientID)
+		form.Set("client_secret", secret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", conf["oauth_token_url"], strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("oauth: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !bodyAuth {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(secret))
+	}
+	debugf("     POST %s (oauth)", conf["oauth_token_url"])
+
+	client := &http.Client{Timeout: time.Duration(cfg.HTTPTimeout)}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("oauth: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("oauth: %w", err)
+	}
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("oauth: token_url respondeu %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return "", fmt.Errorf("oauth: resposta inválida: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return "", fmt.Errorf("oauth: resposta sem access_token")
+	}
+	if tok.TokenType != "" && !strings.EqualFold(tok.TokenType, "bearer") {
+		return "", fmt.Errorf("oauth: token_type %q não suportado", tok.TokenType)
+	}
+
+	// Sem expires_in, assume a validade mais comum (1h)
+	lifetime := time.Hour
+	if tok.ExpiresIn > 0 {
+		lifetime = time.Duration(tok.ExpiresIn) * time.Second
+	}
+	entry.token, entry.expires = tok.AccessToken, time.Now().Add(lifetime)
+	verbosef("     Token OAuth obtido (expira em %s)", lifetime)
+	return entry.token, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// ==========================================
+// BADGES (SVG)
+// ==========================================
+
+// serveBadge responde /badge/{id}.svg com um badge no estilo do shields.io mostrando a versão
+// publicada do app. ?label= troca o texto da esquerda (padrão: o id do app).
+func serveBadge(w http.ResponseWriter, r *http.Request, cache *catalogCache) {
+	id, ok := strings.CutSuffix(r.PathValue("file"), ".svg")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	label := r.URL.Query().Get("label")
+	if label == "" {
+		label = id
+	}
+
+	// Mesmo nos erros o corpo é um badge, para que o <img> do README não quebre
+	status, value, color := http.StatusOK, "", "#007ec6"
+	catalog, err := cache.get()
+	app, found := catalog.lookup(id)
+	switch {
+	case err != nil:
+		status, value, color = http.StatusServiceUnavailable, "indisponível", "#9f9f9f"
+	case !found:
+		status, value, color = http.StatusNotFound, "não encontrado", "#9f9f9f"
+	case app.Version == "":
+		value, color = "desconhecida", "#9f9f9f"
+	case app.VersionScheme == "" || app.VersionScheme == schemeSemver:
+		value = "v" + strings.TrimPrefix(app.Version, "v")
+	default:
+		// debian, calver e string são exibidos como publicados ("2024.05", "1:2.0-3")
+		value = app.Version
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "max-age=300")
+	w.WriteHeader(status)
+	fmt.Fprint(w, renderBadge(label, value, color))
+}
+
+// renderBadge monta o SVG "flat". A largura do texto é estimada (~7px por caractere a 11px),
+// o que basta para rótulos curtos sem depender de métricas de fonte.
+func renderBadge(label, value, color string) string {
+	lw, vw := badgeTextWidth(label), badgeTextWidth(value)
+	total := lw + vw
+	label, value = html.EscapeString(label), html.EscapeString(value)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[7]d" y="14">%[4]s</text>
+<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text><text x="%[8]d" y="14">%[5]s</text>
+</g></svg>
+`, total, lw, vw, label, value, color, lw/2, lw+vw/2)
+}
+
+func badgeTextWidth(s string) int {
+	return len([]rune(s))*7 + 10
+}
@@ -0,0 +1,28 @@
+package main
+
+import "time"
+
+// ==========================================
+// CIRCUIT BREAKER POR FONTE
+// ==========================================
+
+// Com breaker_after, uma fonte que falha N vezes seguidas (consecutive_failures, persistido
+// na entrada) tem as checagens suspensas por breaker_cooldown: a execução não gasta tempo nem
+// rate limit com ela, e a entrada segue publicada como está. Passada a pausa, a próxima
+// checagem é uma tentativa: se falhar, o breaker abre de novo; se der certo, tudo zera.
+
+// breakerOpen diz se as checagens de app estão suspensas. Uma checagem forçada (ver
+// forcedCheck) passa por cima, para conferir uma correção sem esperar o fim da pausa.
+func breakerOpen(app CatalogApp) bool {
+	return cfg.BreakerAfter > 0 && time.Now().Before(app.BreakerUntil)
+}
+
+// tripBreaker abre o breaker da entrada quando as falhas seguidas chegam a breaker_after.
+func tripBreaker(res *appResult) {
+	if cfg.BreakerAfter <= 0 || res.App.ConsecutiveFailures < cfg.BreakerAfter {
+		return
+	}
+	res.App.BreakerUntil = time.Now().Add(time.Duration(cfg.BreakerCooldown)).UTC().Truncate(time.Second)
+	errorf(" [BREAKER] %s: %d falhas seguidas; checagens suspensas até %s.",
+		res.ID, res.App.ConsecutiveFailures, res.App.BreakerUntil.Format(time.RFC3339))
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// CHANGELOG DO CATÁLOGO
+// ==========================================
+
+// Changelog é o histórico acumulado das versões publicadas no catálogo, da execução mais
+// recente para a mais antiga. Fica em cfg.ChangelogPath (JSON) e é renderizado ao lado em
+// Markdown, com a mesma base e extensão .md.
+type Changelog struct {
+	Runs []ChangelogRun `json:"runs"`
+}
+
+// ChangelogRun agrupa as transições de versão de uma execução (ou de um approve).
+type ChangelogRun struct {
+	Date    time.Time        `json:"date"`
+	Changes []ChangelogEntry `json:"changes"`
+}
+
+type ChangelogEntry struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	From      string `json:"from,omitempty"` // Vazio quando o app entrou no catálogo nesta execução
+	To        string `json:"to"`
+	Downgrade bool   `json:"downgrade,omitempty"`
+	Size      int64  `json:"size,omitempty"`
+}
+
+// changelogEntries extrai as transições dos apps atualizados na execução.
+func changelogEntries(results []appResult) []ChangelogEntry {
+	var entries []ChangelogEntry
+	for _, res := range results {
+		if res.Status() != "updated" {
+			continue
+		}
+		entries = append(entries, ChangelogEntry{
+			ID:        res.ID,
+			Name:      res.App.Name,
+			From:      res.OldVersion,
+			To:        res.App.Version,
+			Downgrade: res.Downgrade,
+			Size:      res.App.Size,
+		})
+	}
+	return entries
+}
+
+// recordChangelog acrescenta uma execução ao changelog e regrava o JSON e o Markdown.
+// Sem transições, nada é gravado.
+func recordChangelog(path string, date time.Time, entries []ChangelogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var changelog Changelog
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(data, &changelog); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	changelog.Runs = append([]ChangelogRun{{Date: date.UTC(), Changes: entries}}, changelog.Runs...)
+
+	data, err = json.MarshalIndent(changelog, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(changelogMarkdownPath(path), []byte(changelogMarkdown(changelog)), 0644)
+}
+
+// changelogMarkdownPath troca a extensão do JSON por .md (CHANGELOG.json -> CHANGELOG.md).
+func changelogMarkdownPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".md"
+}
+
+// changelogMarkdown renderiza o histórico com uma seção por execução.
+func changelogMarkdown(changelog Changelog) string {
+	var b strings.Builder
+	b.WriteString("# Changelog do catálogo\n")
+
+	for _, run := range changelog.Runs {
+		fmt.Fprintf(&b, "\n## %s\n\n", run.Date.Format("2006-01-02 15:04 UTC"))
+		for _, entry := range run.Changes {
+			name := entry.ID
+			if entry.Name != "" && entry.Name != entry.ID {
+				name = fmt.Sprintf("%s (`%s`)", entry.Name, entry.ID)
+			}
+			switch {
+			case entry.From == "":
+				fmt.Fprintf(&b, "- **%s**: adicionado em %s\n", name, entry.To)
+			case entry.Downgrade:
+				fmt.Fprintf(&b, "- **%s**: %s → %s (downgrade)\n", name, entry.From, entry.To)
+			default:
+				fmt.Fprintf(&b, "- **%s**: %s → %s\n", name, entry.From, entry.To)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// ==========================================
+// CANAIS DE ATUALIZAÇÃO (STABLE, BETA...)
+// ==========================================
+
+// SourceChannel declara um canal extra do app. A entrada principal do catálogo é o canal
+// estável; cada canal roda a própria checagem, com a estratégia da fonte (ou outra) e a config
+// da fonte acrescida da config do canal. Ex: um canal beta do mesmo repositório do GitHub só
+// com "include_prereleases": "true", ou com outro tag_pattern.
+type SourceChannel struct {
+	Strategy string    `json:"strategy,omitempty"` // Vazio = a mesma da fonte
+	Config   ConfigMap `json:"config,omitempty"`   // Sobrepõe a config da fonte
+}
+
+// ChannelRelease é a versão publicada num canal, com os mesmos campos da entrada principal.
+type ChannelRelease struct {
+	Version     string    `json:"latest_version"`
+	DownloadURL string    `json:"download_url"`
+	Checksum    string    `json:"checksum"`
+	SHA512      string    `json:"sha512,omitempty"`
+	Size        int64     `json:"size"`
+	Mirrors     []string  `json:"mirrors,omitempty"`
+	ReleasedAt  time.Time `json:"released_at,omitzero"`
+	Magnet      string    `json:"magnet,omitempty"`
+	TorrentURL  string    `json:"torrent_url,omitempty"`
+	IPFSCID     string    `json:"ipfs_cid,omitempty"`
+	LastChecked time.Time `json:"last_checked,omitzero"`
+	LastUpdated time.Time `json:"last_updated,omitzero"`
+
+	Requirements *Requirements `json:"requirements,omitempty"`
+}
+
+// stableChannel é o nome reservado para a entrada principal.
+const stableChannel = "stable"
+
+var channelNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+func validChannelName(name string) bool {
+	return name != stableChannel && channelNamePattern.MatchString(name)
+}
+
+// channel devolve a fonte que a checagem do canal usa: a própria fonte, com a estratégia e a
+// config do canal.
+func (src SourceApp) channel(name string) SourceApp {
+	ch := src.Channels[name]
+	derived := src
+	derived.Name = fmt.Sprintf("%s [%s]", src.Name, name)
+	derived.Channels, derived.Fallbacks = nil, nil
+	derived.CheckInterval = "" // O canal é checado sempre que a entrada principal é
+	if ch.Strategy != "" {
+		derived.Strategy = ch.Strategy
+	}
+	derived.Config = maps.Clone(src.Config)
+	if derived.Config == nil {
+		derived.Config = make(map[string]string)
+	}
+	maps.Copy(derived.Config, ch.Config)
+	return derived
+}
+
+// refreshChannels checa os canais da fonte, na ordem dos nomes, partindo das versões já
+// publicadas em app. Um canal com falha mantém a versão anterior; o primeiro erro é devolvido
+// para o resultado do app.
+func refreshChannels(ctx context.Context, src SourceApp, app CatalogApp) (map[string]ChannelRelease, error) {
+	if len(src.Channels) == 0 {
+		return nil, nil
+	}
+
+	channels := make(map[string]ChannelRelease, len(src.Channels))
+	var firstErr error
+	for _, name := range slices.Sorted(maps.Keys(src.Channels)) {
+		if !validChannelName(name) {
+			continue // O lint já acusa
+		}
+		derived, oldCatalog := src.channelCheck(name, app)
+		res, online, ok := resolveApp(ctx, derived, oldCatalog)
+		if ok {
+			res = applyApp(ctx, derived, oldCatalog, online, res)
+		}
+		if res.Err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("canal %s: %w", name, res.Err)
+		}
+		if res.HasApp {
+			channels[name] = res.App.release()
+		}
+	}
+	return channels, firstErr
+}
+
+// channelCheck devolve a fonte derivada do canal e o catálogo contra o qual ela é checada: a
+// checagem do canal reaproveita resolveApp/applyApp sobre um catálogo só com o canal.
+func (src SourceApp) channelCheck(name string, app CatalogApp) (SourceApp, Catalog) {
+	oldCatalog := Catalog{Apps: make(map[string]CatalogApp)}
+	if previous, exists := app.Channels[name]; exists {
+		oldCatalog.Apps[src.ID] = previous.entry(app)
+	}
+	return src.channel(name), oldCatalog
+}
+
+// planChannels é o refreshChannels do "check": só os passos A e B de cada canal, devolvendo
+// uma ação por canal com versão nova. O primeiro erro é devolvido, como em refreshChannels.
+func planChannels(ctx context.Context, src SourceApp, app CatalogApp) ([]PlanAction, error) {
+	var actions []PlanAction
+	var firstErr error
+	for _, name := range slices.Sorted(maps.Keys(src.Channels)) {
+		if !validChannelName(name) {
+			continue
+		}
+		derived, oldCatalog := src.channelCheck(name, app)
+		res, online, ok := resolveApp(ctx, derived, oldCatalog)
+		if res.Err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("canal %s: %w", name, res.Err)
+		}
+		if ok {
+			actions = append(actions, PlanAction{ID: src.ID, Channel: name, OldVersion: res.OldVersion, Downgrade: res.Downgrade, Online: online})
+		}
+	}
+	return actions, firstErr
+}
+
+// applyChannelAction é o applyApp de uma ação de canal do plano. O resultado traz na entrada só
+// o canal; mergeChannelResults o leva para o canal da entrada do app.
+func applyChannelAction(ctx context.Context, src SourceApp, catalog Catalog, action PlanAction) appResult {
+	derived, oldCatalog := src.channelCheck(action.Channel, catalog.Apps[action.ID])
+	previous, exists := oldCatalog.Apps[action.ID]
+	res := appResult{ID: action.ID, App: previous, HasApp: exists, OldVersion: previous.Version, OldSize: previous.Size,
+		NewVersion: action.Online.Version, Downgrade: action.Downgrade}
+	if _, ok := src.Channels[action.Channel]; !ok {
+		res.Err = fmt.Errorf("canal %s não encontrado (removido depois do check?)", action.Channel)
+		errorf(" [ERRO] %s: %v", action.ID, res.Err)
+		return res
+	}
+	if previous.Version != action.OldVersion {
+		infof(" [SKIP] %s [%s]: plano desatualizado (canal em %s, plano partiu de %s).", action.ID, action.Channel, previous.Version, action.OldVersion)
+		return res
+	}
+	runDownloads.claimURL(derived.ID, action.Online.URL)
+	return applyApp(ctx, derived, oldCatalog, action.Online, res)
+}
+
+// mergeChannelResults junta os resultados das ações de canal (mesmo índice de actions) ao
+// resultado da entrada do app: o canal vai para App.Channels e uma falha conta como falha do
+// app, como no generate. Um app sem ação principal no plano parte da entrada do catálogo.
+func mergeChannelResults(actions []PlanAction, results []appResult, catalog Catalog) []appResult {
+	merged := make([]appResult, 0, len(results))
+	byID := make(map[string]int)
+	for i, action := range actions {
+		if action.Channel == "" {
+			byID[action.ID] = len(merged)
+			merged = append(merged, results[i])
+		}
+	}
+	for i, action := range actions {
+		if action.Channel == "" {
+			continue
+		}
+		idx, ok := byID[action.ID]
+		if !ok {
+			app, exists := catalog.Apps[action.ID]
+			if !exists {
+				continue // App novo cuja ação principal saiu do plano
+			}
+			idx = len(merged)
+			byID[action.ID] = idx
+			merged = append(merged, appResult{ID: action.ID, App: app, HasApp: true, OldVersion: app.Version, OldSize: app.Size, NewVersion: app.Version})
+		}
+		main, ch := &merged[idx], results[i]
+		if !main.HasApp {
+			continue // A entrada principal falhou sem versão anterior: não há onde pôr o canal
+		}
+		if ch.HasApp {
+			main.App.Channels = maps.Clone(main.App.Channels) // Não altera o mapa do catálogo antigo
+			if main.App.Channels == nil {
+				main.App.Channels = make(map[string]ChannelRelease)
+			}
+			main.App.Channels[action.Channel] = ch.App.release()
+			main.ChannelChanged = main.ChannelChanged || ch.Changed
+		}
+		if ch.Err != nil && main.Err == nil {
+			main.Err = fmt.Errorf("canal %s: %w", action.Channel, ch.Err)
+		}
+	}
+	return merged
+}
+
+// entry monta a entrada que resolveApp/applyApp comparam com a checagem do canal.
+func (r ChannelRelease) entry(app CatalogApp) CatalogApp {
+	app.Version, app.DownloadURL, app.Checksum, app.SHA512, app.Size = r.Version, r.DownloadURL, r.Checksum, r.SHA512, r.Size
+	app.Mirrors = r.Mirrors
+	app.ReleasedAt, app.Magnet, app.TorrentURL, app.IPFSCID = r.ReleasedAt, r.Magnet, r.TorrentURL, r.IPFSCID
+	app.LastChecked, app.LastUpdated = r.LastChecked, r.LastUpdated
+	app.Requirements = r.Requirements
+	return app
+}
+
+// release extrai da entrada os campos publicados num canal.
+func (app CatalogApp) release() ChannelRelease {
+	return ChannelRelease{
+		Version:     app.Version,
+		DownloadURL: app.DownloadURL,
+		Checksum:    app.Checksum,
+		SHA512:      app.SHA512,
+		Size:        app.Size,
+		Mirrors:     app.Mirrors,
+		ReleasedAt:  app.ReleasedAt,
+		Magnet:      app.Magnet,
+		TorrentURL:  app.TorrentURL,
+		IPFSCID:     app.IPFSCID,
+		LastChecked: app.LastChecked,
+		LastUpdated: app.LastUpdated,
+
+		Requirements: app.Requirements,
+	}
+}
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ==========================================
+// CONFIGURAÇÃO GLOBAL
+// ==========================================
+
+// Config reúne as opções do gerador que não pertencem a nenhuma fonte específica.
+// Precedência (da menor para a maior): padrões -> arquivo de config -> variáveis
+// de ambiente (UPDATER_*) -> flags de linha de comando.
+type Config struct {
+	Sources         []string           `json:"sources"`          // Arquivos/diretórios de fontes
+	Only            []string           `json:"only"`             // Processa só estes IDs (vazio = todos)
+	Skip            []string           `json:"skip"`             // Ignora estes IDs
+	Tags            []string           `json:"tags"`             // Processa só fontes com alguma destas tags
+	CatalogPath     string             `json:"catalog"`          // catalog.json (leitura e escrita): caminho local, s3://, gs:// ou az://
+	StagingPath     string             `json:"staging"`          // Catálogo de staging: o generate grava aqui e o promote copia para catalog (vazio = desativado)
+	PendingPath     string             `json:"pending"`          // Atualizações aguardando aprovação (vazio = publicadas direto)
+	PlanPath        string             `json:"plan"`             // Plano gravado pelo check e lido pelo apply
+	SigningKey      string             `json:"signing_key"`      // Chave Ed25519 (PEM) que assina o catálogo em catalog.json.sig (vazio = sem assinatura)
+	SigningKeys     []SigningKeyConfig `json:"signing_keys"`     // Chaves com validade, para rotação: catalog.json.sigs e o manifesto catalog.json.keys
+	Profile         string             `json:"profile"`          // Perfil a usar (vazio = todos, no generate/check/apply)
+	HTTPTimeout     Duration           `json:"http_timeout"`     // Timeout das checagens de versão
+	DownloadTimeout Duration           `json:"download_timeout"` // Timeout de cada download (0 = sem limite)
+	Timeout         Duration           `json:"timeout"`          // Prazo da execução inteira (0 = sem limite)
+	StaleAfter      Duration           `json:"stale_after"`      // Sem checagem bem-sucedida há mais que isso, a entrada vira stale (0 = desativado)
+	BreakerAfter    int                `json:"breaker_after"`    // Falhas seguidas que suspendem as checagens do app (0 = desativado)
+	BreakerCooldown Duration           `json:"breaker_cooldown"` // Por quanto tempo as checagens ficam suspensas
+	Concurrency     int                `json:"concurrency"`      // Quantos apps processar em paralelo
+	GithubTokenEnv  string             `json:"github_token_env"` // Variável de ambiente com o token do GitHub
+	GithubAPIURL    string             `json:"github_api_url"`   // Base da API (GitHub Enterprise ou proxy/mirror)
+	GithubGraphQL   bool               `json:"github_graphql"`   // Pré-carrega releases em lote via GraphQL
+	GithubCachePath string             `json:"github_cache"`     // Cache de ETags da API REST (vazio = desativado)
+	MaxFailures     int                `json:"max_failures"`     // Falhas toleradas antes de sair com erro (-1 = ilimitado)
+	ReportPath      string             `json:"report"`           // Relatório JSON da execução (vazio = desativado)
+	SummaryPath     string             `json:"summary"`          // Resumo em Markdown para PRs/commits (vazio = desativado)
+	ChangelogPath   string             `json:"changelog"`        // Histórico acumulado das atualizações em JSON, com o .md ao lado (vazio = desativado)
+	LogLevel        string             `json:"log_level"`        // quiet, info, verbose ou debug
+	StrictChecksums bool               `json:"strict_checksums"` // Download divergente do hash/tamanho publicado reprova o app
+	Prune           bool               `json:"prune"`            // Retira do catálogo os apps sem fonte (em vez de marcá-los como orphaned)
+	RespectRobots   bool               `json:"respect_robots"`   // Estratégias que raspam páginas e feeds respeitam o robots.txt (ver robots.go)
+
+	Notify    NotifyConfig    `json:"notify"`
+	Artifacts ArtifactsConfig `json:"artifacts"`
+	Torrent   TorrentConfig   `json:"torrent"`
+	IPFS      IPFSConfig      `json:"ipfs"`
+	S3        S3Config        `json:"s3"`
+	GCS       GCSConfig       `json:"gcs"`
+	Azure     AzureConfig     `json:"azure"`
+	Rsync     RsyncConfig     `json:"rsync"`
+	Profiles  []Profile       `json:"profiles"`
+	Snapshots SnapshotConfig  `json:"snapshots"`
+	TUF       TUFConfig       `json:"tuf"`
+	Encrypt   EncryptConfig   `json:"encrypt"`
+	Server    ServerConfig    `json:"server"`
+}
+
+// cfg é a configuração efetiva da execução, preenchida por loadConfig.
+var cfg = defaultConfig()
+
+func defaultConfig() Config {
+	return Config{
+		CatalogPath:     "catalog.json",
+		PlanPath:        "plan.json",
+		HTTPTimeout:     Duration(10 * time.Second),
+		DownloadTimeout: 0,
+		Concurrency:     1,
+		GithubTokenEnv:  "GITHUB_TOKEN",
+		GithubAPIURL:    "https://api.github.com",
+		MaxFailures:     -1,
+		BreakerCooldown: Duration(24 * time.Hour),
+		Server:          ServerConfig{Listen: ":8080", ReloadInterval: Duration(time.Minute), ShutdownGrace: Duration(30 * time.Second)},
+	}
+}
+
+// configFiles são os nomes procurados quando -config não é informado.
+var configFiles = []string{"generator.json", "generator.yaml", "generator.yml", "generator.toml"}
+
+// loadConfig monta a configuração efetiva aplicando a ordem de precedência documentada.
+// Devolve também os argumentos posicionais que sobram após as flags (ex: o id do probe).
+// extra, se não for nil, registra flags próprias do subcomando no mesmo FlagSet.
+func loadConfig(args []string, extra func(*flag.FlagSet)) (Config, []string, error) {
+	c := defaultConfig()
+
+	fs := flag.NewFlagSet("generator", flag.ExitOnError)
+	configPath := fs.String("config", "", "Arquivo de configuração (padrão: generator.{json,yaml,yml,toml} se existir)")
+	sources := fs.String("sources", "", "Arquivos ou diretórios de fontes, separados por vírgula (padrão: apps.source.{json,yaml,yml,toml})")
+	only := fs.String("only", "", "Processa só estes IDs, separados por vírgula (ex: firefox,chrome)")
+	skip := fs.String("skip", "", "Ignora estes IDs, separados por vírgula")
+	tags := fs.String("tag", "", "Processa só fontes com alguma destas tags, separadas por vírgula")
+	catalog := fs.String("catalog", "", "Local do catalog.json: caminho, s3://, gs:// ou az://")
+	signingKey := fs.String("signing-key", "", "Chave Ed25519 (PEM PKCS#8) para assinar o catálogo em <catalog>.sig")
+	profile := fs.String("profile", "", "Usa só este perfil (ver profiles na configuração)")
+	planPath := fs.String("plan", "", "Plano gravado pelo check e lido pelo apply (padrão: plan.json)")
+	pendingPath := fs.String("pending", "", "Retém as atualizações detectadas neste arquivo até o approve (ex: pending.json)")
+	staging := fs.String("staging", "", "Catálogo de staging (ex: catalog-staging.json): o generate grava nele e o promote o publica")
+	httpTimeout := fs.Duration("http-timeout", 0, "Timeout das checagens de versão (ex: 10s)")
+	downloadTimeout := fs.Duration("download-timeout", 0, "Timeout de cada download (ex: 10m)")
+	timeout := fs.Duration("timeout", 0, "Prazo da execução inteira; ao expirar, checagens e downloads em andamento são cancelados (ex: 30m)")
+	staleAfter := fs.Duration("stale-after", 0, "Marca como stale a entrada sem checagem bem-sucedida há mais que isso (ex: 168h)")
+	breakerAfter := fs.Int("breaker-after", 0, "Suspende as checagens do app depois de N falhas seguidas (circuit breaker; 0 = desativado)")
+	breakerCooldown := fs.Duration("breaker-cooldown", 0, "Por quanto tempo o circuit breaker suspende as checagens (padrão: 24h)")
+	concurrency := fs.Int("concurrency", 0, "Quantidade de apps processados em paralelo")
+	report := fs.String("report", "", "Grava um relatório JSON da execução neste caminho (ex: report.json)")
+	summary := fs.String("summary", "", "Grava um resumo em Markdown das alterações neste caminho (ex: summary.md)")
+	changelog := fs.String("changelog", "", "Acumula o histórico das atualizações neste JSON e no .md ao lado (ex: CHANGELOG.json)")
+	verbose := fs.Bool("v", false, "Log detalhado (passo a passo de cada app)")
+	debug := fs.Bool("vv", false, "Log de depuração (inclui cada requisição HTTP)")
+	quiet := fs.Bool("quiet", false, "Mostra apenas erros")
+	graphQL := fs.Bool("github-graphql", false, "Pré-carrega as releases do GitHub em lote via GraphQL")
+	githubCache := fs.String("github-cache", "", "Arquivo de cache de ETags da API do GitHub (ex: .cache/github.json)")
+	failOnError := fs.Bool("fail-on-error", false, "Sai com código 2 se qualquer fonte falhar (equivale a -max-failures 0)")
+	maxFailures := fs.Int("max-failures", -1, "Sai com código 2 se mais de N fontes falharem (-1 = ilimitado)")
+	prune := fs.Bool("prune", false, "Retira do catálogo os apps que não estão mais nas fontes (padrão: mantê-los marcados como orphaned)")
+	strictChecksums := fs.Bool("strict-checksums", false, "Reprova o app (e sai com código 4) quando o download diverge do hash ou tamanho publicado pela origem")
+	respectRobots := fs.Bool("respect-robots", false, "Consulta o robots.txt (e respeita o Crawl-delay) nas estratégias que raspam páginas e feeds")
+	listen := fs.String("listen", "", "Endereço do modo servidor (serve), ex: :8080")
+	if extra != nil {
+		extra(fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		return c, nil, err
+	}
+
+	// 1. Arquivo de configuração
+	path := *configPath
+	if path == "" {
+		path = os.Getenv("UPDATER_CONFIG")
+	}
+	if path == "" {
+		for _, candidate := range configFiles {
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+		}
+	}
+	if path != "" {
+		data, err := readAsJSON(path)
+		if err != nil {
+			return c, nil, err
+		}
+		if err := json.Unmarshal(data, &c); err != nil {
+			return c, nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	// 2. Variáveis de ambiente
+	if err := applyConfigEnv(&c); err != nil {
+		return c, nil, err
+	}
+
+	// 3. Flags (apenas as informadas explicitamente)
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "sources":
+			c.Sources = splitList(*sources)
+		case "only":
+			c.Only = splitList(*only)
+		case "skip":
+			c.Skip = splitList(*skip)
+		case "tag":
+			c.Tags = splitList(*tags)
+		case "catalog":
+			c.CatalogPath = *catalog
+		case "signing-key":
+			c.SigningKey = *signingKey
+		case "profile":
+			c.Profile = *profile
+		case "staging":
+			c.StagingPath = *staging
+		case "pending":
+			c.PendingPath = *pendingPath
+		case "plan":
+			c.PlanPath = *planPath
+		case "http-timeout":
+			c.HTTPTimeout = Duration(*httpTimeout)
+		case "download-timeout":
+			c.DownloadTimeout = Duration(*downloadTimeout)
+		case "timeout":
+			c.Timeout = Duration(*timeout)
+		case "stale-after":
+			c.StaleAfter = Duration(*staleAfter)
+		case "breaker-after":
+			c.BreakerAfter = *breakerAfter
+		case "breaker-cooldown":
+			c.BreakerCooldown = Duration(*breakerCooldown)
+		case "concurrency":
+			c.Concurrency = *concurrency
+		case "report":
+			c.ReportPath = *report
+		case "summary":
+			c.SummaryPath = *summary
+		case "changelog":
+			c.ChangelogPath = *changelog
+		case "v":
+			if *verbose {
+				c.LogLevel = "verbose"
+			}
+		case "vv":
+			if *debug {
+				c.LogLevel = "debug"
+			}
+		case "quiet":
+			if *quiet {
+				c.LogLevel = "quiet"
+			}
+		case "github-graphql":
+			c.GithubGraphQL = *graphQL
+		case "github-cache":
+			c.GithubCachePath = *githubCache
+		case "max-failures":
+			c.MaxFailures = *maxFailures
+		case "strict-checksums":
+			c.StrictChecksums = *strictChecksums
+		case "prune":
+			c.Prune = *prune
+		case "respect-robots":
+			c.RespectRobots = *respectRobots
+		case "listen":
+			c.Server.Listen = *listen
+		}
+	})
+
+	// --fail-on-error é um atalho para orçamento zero e vence qualquer valor mais permissivo
+	if *failOnError {
+		c.MaxFailures = 0
+	}
+
+	if len(c.Sources) == 0 {
+		c.Sources = []string{defaultSourcesPath()}
+	}
+	if c.Concurrency < 1 {
+		c.Concurrency = 1
+	}
+	return c, fs.Args(), nil
+}
+
+func applyConfigEnv(c *Config) error {
+	if v := os.Getenv("UPDATER_SOURCES"); v != "" {
+		c.Sources = splitList(v)
+	}
+	if v := os.Getenv("UPDATER_CATALOG"); v != "" {
+		c.CatalogPath = v
+	}
+	if v := os.Getenv("UPDATER_SIGNING_KEY"); v != "" {
+		c.SigningKey = v
+	}
+	if v := os.Getenv("UPDATER_PROFILE"); v != "" {
+		c.Profile = v
+	}
+	if v := os.Getenv("UPDATER_STAGING"); v != "" {
+		c.StagingPath = v
+	}
+	if v := os.Getenv("UPDATER_PENDING"); v != "" {
+		c.PendingPath = v
+	}
+	if v := os.Getenv("UPDATER_PLAN"); v != "" {
+		c.PlanPath = v
+	}
+	if v := os.Getenv("UPDATER_REPORT"); v != "" {
+		c.ReportPath = v
+	}
+	if v := os.Getenv("UPDATER_SUMMARY"); v != "" {
+		c.SummaryPath = v
+	}
+	if v := os.Getenv("UPDATER_CHANGELOG"); v != "" {
+		c.ChangelogPath = v
+	}
+	if v := os.Getenv("UPDATER_LOG_LEVEL"); v != "" {
+		c.LogLevel = v
+	}
+	if v := os.Getenv("UPDATER_HTTP_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("UPDATER_HTTP_TIMEOUT: %w", err)
+		}
+		c.HTTPTimeout = Duration(d)
+	}
+	if v := os.Getenv("UPDATER_DOWNLOAD_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("UPDATER_DOWNLOAD_TIMEOUT: %w", err)
+		}
+		c.DownloadTimeout = Duration(d)
+	}
+	if v := os.Getenv("UPDATER_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("UPDATER_TIMEOUT: %w", err)
+		}
+		c.Timeout = Duration(d)
+	}
+	if v := os.Getenv("UPDATER_STALE_AFTER"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("UPDATER_STALE_AFTER: %w", err)
+		}
+		c.StaleAfter = Duration(d)
+	}
+	if v := os.Getenv("UPDATER_BREAKER_AFTER"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("UPDATER_BREAKER_AFTER: %w", err)
+		}
+		c.BreakerAfter = n
+	}
+	if v := os.Getenv("UPDATER_BREAKER_COOLDOWN"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("UPDATER_BREAKER_COOLDOWN: %w", err)
+		}
+		c.BreakerCooldown = Duration(d)
+	}
+	if v := os.Getenv("UPDATER_CONCURRENCY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("UPDATER_CONCURRENCY: %w", err)
+		}
+		c.Concurrency = n
+	}
+	if v := os.Getenv("UPDATER_MAX_FAILURES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("UPDATER_MAX_FAILURES: %w", err)
+		}
+		c.MaxFailures = n
+	}
+	if v := os.Getenv("UPDATER_SLACK_WEBHOOK"); v != "" {
+		c.Notify.SlackWebhook = v
+	}
+	if v := os.Getenv("UPDATER_DISCORD_WEBHOOK"); v != "" {
+		c.Notify.DiscordWebhook = v
+	}
+	if v := os.Getenv("UPDATER_TELEGRAM_TOKEN"); v != "" {
+		c.Notify.TelegramToken = v
+	}
+	if v := os.Getenv("UPDATER_TELEGRAM_CHAT_ID"); v != "" {
+		c.Notify.TelegramChatID = v
+	}
+	if v := os.Getenv("UPDATER_SMTP_HOST"); v != "" {
+		c.Notify.SMTP.Host = v
+	}
+	if v := os.Getenv("UPDATER_SMTP_PORT"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("UPDATER_SMTP_PORT: %w", err)
+		}
+		c.Notify.SMTP.Port = n
+	}
+	if v := os.Getenv("UPDATER_SMTP_USERNAME"); v != "" {
+		c.Notify.SMTP.Username = v
+	}
+	if v := os.Getenv("UPDATER_SMTP_PASSWORD"); v != "" {
+		c.Notify.SMTP.Password = v
+	}
+	if v := os.Getenv("UPDATER_SMTP_FROM"); v != "" {
+		c.Notify.SMTP.From = v
+	}
+	if v := os.Getenv("UPDATER_SMTP_TO"); v != "" {
+		c.Notify.SMTP.To = splitList(v)
+	}
+	if v := os.Getenv("UPDATER_MQTT_BROKER"); v != "" {
+		c.Notify.MQTT.Broker = v
+	}
+	if v := os.Getenv("UPDATER_MQTT_USERNAME"); v != "" {
+		c.Notify.MQTT.Username = v
+	}
+	if v := os.Getenv("UPDATER_MQTT_PASSWORD"); v != "" {
+		c.Notify.MQTT.Password = v
+	}
+	if v := os.Getenv("UPDATER_GITHUB_TOKEN_ENV"); v != "" {
+		c.GithubTokenEnv = v
+	}
+	if v := os.Getenv("UPDATER_GITHUB_API_URL"); v != "" {
+		c.GithubAPIURL = v
+	}
+	if v := os.Getenv("UPDATER_GITHUB_CACHE"); v != "" {
+		c.GithubCachePath = v
+	}
+	if v := os.Getenv("UPDATER_GITHUB_GRAPHQL"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("UPDATER_GITHUB_GRAPHQL: %w", err)
+		}
+		c.GithubGraphQL = b
+	}
+	if v := os.Getenv("UPDATER_STRICT_CHECKSUMS"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("UPDATER_STRICT_CHECKSUMS: %w", err)
+		}
+		c.StrictChecksums = b
+	}
+	if v := os.Getenv("UPDATER_RESPECT_ROBOTS"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("UPDATER_RESPECT_ROBOTS: %w", err)
+		}
+		c.RespectRobots = b
+	}
+	if v := os.Getenv("UPDATER_PRUNE"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("UPDATER_PRUNE: %w", err)
+		}
+		c.Prune = b
+	}
+	if v := os.Getenv("UPDATER_LISTEN"); v != "" {
+		c.Server.Listen = v
+	}
+	return nil
+}
+
+// splitList separa uma lista "a,b,c" ignorando itens vazios.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// readAsJSON lê um arquivo JSON, YAML ou TOML (detectado pela extensão) e devolve
+// seu conteúdo como JSON, para que todos os formatos compartilhem as mesmas tags.
+func readAsJSON(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case ".toml":
+		var table map[string]interface{}
+		if _, err := toml.Decode(string(data), &table); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		raw = table
+	default:
+		return data, nil
+	}
+
+	out, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
+
+// ConfigMap é a config de uma fonte: texto por chave. Valores nativos de YAML/TOML (ou de um
+// JSON escrito à mão), como include_prereleases: true ou port: 2222, viram o texto
+// correspondente ("true", "2222"), para que o mesmo arquivo valha em qualquer formato.
+type ConfigMap map[string]string
+
+func (c *ConfigMap) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*c = nil
+		return nil
+	}
+	config := make(ConfigMap, len(raw))
+	for key, value := range raw {
+		var scalar interface{}
+		if err := json.Unmarshal(value, &scalar); err != nil {
+			return err
+		}
+		switch v := scalar.(type) {
+		case string:
+			config[key] = v
+		case bool, float64:
+			config[key] = string(bytes.TrimSpace(value)) // O número como escrito (2222, não 2.222e+03)
+		case nil:
+			config[key] = ""
+		default:
+			return fmt.Errorf("config.%s: esperado texto, número ou booleano", key)
+		}
+	}
+	*c = config
+	return nil
+}
+
+// Duration aceita tanto "10s"/"5m" quanto um número de segundos no arquivo de configuração.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = Duration(parsed)
+		return nil
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(b, &seconds); err != nil {
+		return fmt.Errorf("duração inválida: %s", b)
+	}
+	*d = Duration(seconds * float64(time.Second))
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// ==========================================
+// URLS DUPLICADAS
+// ==========================================
+
+// runDownloads memoriza downloads por URL durante uma execução de generate: fontes que
+// resolvem para o mesmo artefato (comum em meta-pacotes) baixam e calculam o hash uma vez só.
+// Fica nil fora do generate (probe, refresh do servidor), onde cada chamada baixa de novo.
+var runDownloads *downloadMemo
+
+type downloadMemo struct {
+	mu      sync.Mutex
+	entries map[string]*memoEntry
+	owners  map[string]string // URL publicada -> primeira fonte que a resolveu
+}
+
+type memoEntry struct {
+	done chan struct{}
+	res  downloadResult
+	err  error
+}
+
+func newDownloadMemo() *downloadMemo {
+	return &downloadMemo{entries: make(map[string]*memoEntry), owners: make(map[string]string)}
+}
+
+// claimURL registra que id publica url e avisa se outra fonte já publica a mesma URL.
+func (m *downloadMemo) claimURL(id, url string) {
+	if m == nil || url == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if owner, ok := m.owners[url]; ok && owner != id {
+		errorf(" [AVISO] %s e %s resolvem para a mesma URL (%s); o catálogo pode ter entradas redundantes.", owner, id, url)
+		return
+	}
+	m.owners[url] = id
+}
+
+// download devolve o resultado memorizado para url ou baixa (uma única vez, mesmo com
+// fontes concorrentes pedindo a mesma URL).
+func (m *downloadMemo) download(ctx context.Context, src SourceApp, url string) (downloadResult, error) {
+	if m == nil {
+		return downloadAndHash(ctx, src, url)
+	}
+
+	m.mu.Lock()
+	entry, found := m.entries[url]
+	if !found {
+		entry = &memoEntry{done: make(chan struct{})}
+		m.entries[url] = entry
+	}
+	m.mu.Unlock()
+
+	if found {
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return downloadResult{}, ctx.Err()
+		}
+		verbosef("     %s: reutilizando o download de %s", src.ID, url)
+		return entry.res, entry.err
+	}
+
+	entry.res, entry.err = downloadAndHash(ctx, src, url)
+	close(entry.done)
+	return entry.res, entry.err
+}
+
+// release avisa que o app terminou de usar o download. Com memo, o arquivo em disco pode
+// servir a outra fonte e só é apagado no cleanup; sem memo, é apagado agora.
+func (m *downloadMemo) release(dl downloadResult) {
+	if m == nil {
+		dl.discard()
+	}
+}
+
+// cleanup apaga os arquivos dos downloads memorizados, ao fim da execução.
+func (m *downloadMemo) cleanup() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, entry := range m.entries {
+		select {
+		case <-entry.done:
+			entry.res.discard()
+		default: // Download ainda em andamento (execução interrompida): o arquivo é dele
+		}
+	}
+}
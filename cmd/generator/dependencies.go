@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ==========================================
+// DEPENDÊNCIAS E CONFLITOS ENTRE APPS
+// ==========================================
+
+// Uma fonte pode declarar, em depends_on, apps do próprio catálogo que precisam estar
+// instalados antes dela (ex: o app hospedeiro de um plugin) e, em conflicts_with, apps que não
+// podem conviver com ela. Os campos são repassados à entrada; installPlan é a implementação de
+// referência da ordem de instalação que os clientes devem seguir. A resposta, InstallPlan, é
+// gerada de openapi.json (api.gen.go).
+
+// installPlan ordena a instalação de id e das suas dependências (busca em profundidade, pós-
+// ordem: cada app depois de tudo de que depende). Os conflitos valem nas duas direções: os que
+// os apps do plano declaram e os apps que declaram conflito com algum deles.
+func installPlan(catalog Catalog, id string) (InstallPlan, error) {
+	root, ok := catalog.lookup(id)
+	if !ok {
+		return InstallPlan{}, fmt.Errorf("app não encontrado: %s", id)
+	}
+	plan := InstallPlan{App: root.ID}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var visit func(app CatalogApp, path []string) error
+	visit = func(app CatalogApp, path []string) error {
+		switch state[app.ID] {
+		case visiting:
+			return fmt.Errorf("ciclo em depends_on: %s", strings.Join(append(path, app.ID), " -> "))
+		case done:
+			return nil
+		}
+		state[app.ID] = visiting
+		for _, dep := range app.DependsOn {
+			next, ok := catalog.lookup(dep)
+			if !ok {
+				if !slices.Contains(plan.Missing, dep) {
+					plan.Missing = append(plan.Missing, dep)
+				}
+				continue
+			}
+			if err := visit(next, append(path, app.ID)); err != nil {
+				return err
+			}
+		}
+		state[app.ID] = done
+		plan.Install = append(plan.Install, app.ID)
+		return nil
+	}
+	if err := visit(root, nil); err != nil {
+		return InstallPlan{}, err
+	}
+
+	conflicts := make(map[string]bool)
+	for _, id := range plan.Install {
+		app := catalog.Apps[id]
+		for _, other := range app.ConflictsWith {
+			if entry, ok := catalog.lookup(other); ok {
+				other = entry.ID
+			}
+			conflicts[other] = true
+		}
+	}
+	for _, app := range catalog.Apps {
+		for _, other := range app.ConflictsWith {
+			if entry, ok := catalog.lookup(other); ok && slices.Contains(plan.Install, entry.ID) {
+				conflicts[app.ID] = true
+			}
+		}
+	}
+	for id := range conflicts {
+		plan.Conflicts = append(plan.Conflicts, id)
+	}
+	slices.Sort(plan.Conflicts)
+	return plan, nil
+}
+
+// handleInstallPlan atende GET /api/v1/apps/{id}/install-plan.
+func handleInstallPlan(cache *catalogCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		catalog, err := cache.get()
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		if _, ok := catalog.lookup(r.PathValue("id")); !ok {
+			writeError(w, http.StatusNotFound, "app não encontrado")
+			return
+		}
+		plan, err := installPlan(catalog, r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, plan)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// ==========================================
+// CATÁLOGO CIFRADO (AGE)
+// ==========================================
+
+// Com encrypt.recipients, o catálogo de produção é gravado cifrado com age para esses
+// destinatários, para registries com ferramentas internas publicados num bucket público. Com
+// private_only, só os apps com "private": true na fonte são cifrados, num catálogo à parte, e o
+// catálogo principal continua público sem eles. O gerador lê o catálogo de volta com a própria
+// identidade (encrypt.identity), que entra automaticamente como destinatário.
+
+type EncryptConfig struct {
+	Recipients     []string `json:"recipients"`      // Chaves públicas age (age1...)
+	Identity       string   `json:"identity"`        // Arquivo de identidade age (AGE-SECRET-KEY-1...) do gerador
+	PrivateOnly    bool     `json:"private_only"`    // Cifra só os apps privados, num catálogo à parte
+	PrivateCatalog string   `json:"private_catalog"` // Onde gravar o catálogo privado (padrão: <catalog>.private.age)
+}
+
+func (e EncryptConfig) enabled() bool {
+	return len(e.Recipients) > 0
+}
+
+func (e EncryptConfig) privatePath() string {
+	if e.PrivateCatalog != "" {
+		return e.PrivateCatalog
+	}
+	return cfg.CatalogPath + ".private.age"
+}
+
+// ageHeader abre todo arquivo age binário; um catálogo sem ele é de antes da criptografia.
+var ageHeader = []byte("age-encryption.org/v1\n")
+
+// loadIdentities lê a identidade do gerador e devolve também os destinatários (os
+// configurados mais o da própria identidade).
+func loadIdentities() ([]age.Identity, []age.Recipient, error) {
+	if cfg.Encrypt.Identity == "" {
+		return nil, nil, fmt.Errorf("encrypt.identity não configurada (o gerador precisa ler o catálogo de volta)")
+	}
+	f, err := os.Open(cfg.Encrypt.Identity)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", cfg.Encrypt.Identity, err)
+	}
+	recipients, err := age.ParseRecipients(strings.NewReader(strings.Join(cfg.Encrypt.Recipients, "\n")))
+	if err != nil {
+		return nil, nil, fmt.Errorf("encrypt.recipients: %w", err)
+	}
+	for _, identity := range identities {
+		switch id := identity.(type) {
+		case *age.X25519Identity:
+			recipients = append(recipients, id.Recipient())
+		case *age.HybridIdentity:
+			recipients = append(recipients, id.Recipient())
+		}
+	}
+	return identities, recipients, nil
+}
+
+func encryptAge(data []byte, recipients []age.Recipient) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decryptAge decifra data; um arquivo em texto puro é devolvido como está.
+func decryptAge(data []byte, identities []age.Identity) ([]byte, error) {
+	if !bytes.HasPrefix(data, ageHeader) {
+		return data, nil
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// encryptCatalog devolve o que vai de fato para o catálogo de produção: o catálogo público
+// (sem apps, quando ele todo é cifrado; usado nos targets TUF), os bytes a gravar e, com
+// private_only, o catálogo privado já cifrado.
+func encryptCatalog(catalog Catalog, data []byte) (Catalog, []byte, []byte, error) {
+	_, recipients, err := loadIdentities()
+	if err != nil {
+		return Catalog{}, nil, nil, err
+	}
+	if !cfg.Encrypt.PrivateOnly {
+		out, err := encryptAge(data, recipients)
+		return Catalog{LastUpdated: catalog.LastUpdated}, out, nil, err
+	}
+
+	public := Catalog{LastUpdated: catalog.LastUpdated, Apps: make(map[string]CatalogApp)}
+	private := Catalog{LastUpdated: catalog.LastUpdated, Apps: make(map[string]CatalogApp)}
+	for id, app := range catalog.Apps {
+		if app.Private {
+			private.Apps[id] = app
+		} else {
+			public.Apps[id] = app
+		}
+	}
+	for alias, id := range catalog.Aliases {
+		target := &public
+		if catalog.Apps[id].Private {
+			target = &private
+		}
+		if target.Aliases == nil {
+			target.Aliases = make(map[string]string)
+		}
+		target.Aliases[alias] = id
+	}
+	public.Stats, private.Stats = computeStats(public.Apps), computeStats(private.Apps)
+
+	out, _ := json.MarshalIndent(public, "", "  ")
+	privateData, _ := json.MarshalIndent(private, "", "  ")
+	if privateData, err = encryptAge(privateData, recipients); err != nil {
+		return Catalog{}, nil, nil, err
+	}
+	return public, out, privateData, nil
+}
+
+// readEncryptedCatalog lê o catálogo de produção cifrado e, com private_only, junta a ele os
+// apps do catálogo privado. Arquivos inexistentes equivalem a catálogos vazios.
+func readEncryptedCatalog(location string) (Catalog, error) {
+	identities, _, err := loadIdentities()
+	if err != nil {
+		return Catalog{}, err
+	}
+	catalog := Catalog{Apps: make(map[string]CatalogApp)}
+	locations := []string{location}
+	if cfg.Encrypt.PrivateOnly {
+		locations = append(locations, cfg.Encrypt.privatePath())
+	}
+	for _, loc := range locations {
+		data, err := readBlob(loc)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Catalog{}, err
+		}
+		if data, err = decryptAge(data, identities); err != nil {
+			return Catalog{}, fmt.Errorf("%s: %w", loc, err)
+		}
+		part := parseCatalog(data)
+		if loc == location {
+			catalog.LastUpdated, catalog.Stats = part.LastUpdated, part.Stats
+		}
+		for id, app := range part.Apps {
+			catalog.Apps[id] = app
+		}
+		for alias, id := range part.Aliases {
+			if catalog.Aliases == nil {
+				catalog.Aliases = make(map[string]string)
+			}
+			catalog.Aliases[alias] = id
+		}
+	}
+	if cfg.Encrypt.PrivateOnly {
+		catalog.Stats = computeStats(catalog.Apps)
+	}
+	return catalog, nil
+}
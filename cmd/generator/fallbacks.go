@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+)
+
+// ==========================================
+// ESTRATÉGIAS DE RESERVA (FALLBACKS)
+// ==========================================
+
+// SourceFallback é uma estratégia de reserva da fonte, tentada (na ordem da lista) quando a
+// checagem com a estratégia principal falha: ex: github_release com direct_url_head na página
+// de downloads como reserva, para que uma API fora do ar ou um repositório migrado não deixem o
+// app parado.
+type SourceFallback struct {
+	Strategy string    `json:"strategy"`
+	Config   ConfigMap `json:"config,omitempty"`
+}
+
+// fallback devolve a fonte que a i-ésima estratégia da cadeia usa (0 = a própria fonte). A
+// config é a do fallback, acrescida das chaves de TLS e de normalização da versão da fonte;
+// credenciais não são herdadas, para não irem a hosts que a fonte não lista.
+func (src SourceApp) fallback(i int) SourceApp {
+	if i <= 0 || i > len(src.Fallbacks) {
+		return src
+	}
+	fb := src.Fallbacks[i-1]
+	derived := src
+	derived.Strategy = fb.Strategy
+	derived.Fallbacks, derived.Channels, derived.Mirrors = nil, nil, nil
+	derived.Config = make(map[string]string, len(fb.Config))
+	for _, list := range [][]string{tlsKeys, versionKeys} {
+		for _, key := range list {
+			if v, ok := src.Config[key]; ok {
+				derived.Config[key] = v
+			}
+		}
+	}
+	for key, v := range fb.Config {
+		derived.Config[key] = v
+	}
+	return derived
+}
+
+// checkWithFallbacks roda a checagem com a estratégia principal (e os mirrors) e, se falhar,
+// com cada fallback. checkResult.Fallback registra qual estratégia atendeu, para que o download
+// (inclusive no apply de um plano) use a config dela; se todas falharem, o erro é o da principal.
+func checkWithFallbacks(ctx context.Context, src SourceApp) (checkResult, string, error) {
+	online, mirror, err := checkWithMirrors(ctx, src)
+	if err == nil || ctx.Err() != nil {
+		return online, mirror, err
+	}
+	for i := range src.Fallbacks {
+		derived := src.fallback(i + 1)
+		errorf(" [AVISO] %s: estratégia %s falhou (%v); tentando o fallback %s", src.ID, src.Strategy, err, derived.Strategy)
+		fbOnline, fbErr := checkStrategy(ctx, derived)
+		if fbErr == nil {
+			fbOnline.Fallback = i + 1
+			return fbOnline, "", nil
+		}
+		if ctx.Err() != nil {
+			return checkResult{}, "", fbErr
+		}
+		verbosef("     %s: fallback %s também falhou: %v", src.ID, derived.Strategy, fbErr)
+	}
+	return online, mirror, err
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// errGithubNotFound indica 404 na API (repositório sem releases, por exemplo).
+var errGithubNotFound = errors.New("github status: 404")
+
+// ==========================================
+// ESTRATÉGIA: GITHUB RELEASES
+// ==========================================
+
+// Estrutura auxiliar para API do GitHub
+type GithubRelease struct {
+	TagName     string        `json:"tag_name"`
+	Draft       bool          `json:"draft"`
+	Prerelease  bool          `json:"prerelease"`
+	PublishedAt time.Time     `json:"published_at"`
+	Body        string        `json:"body"` // Texto da release (alguns projetos colam os SHA256 aqui)
+	Assets      []GithubAsset `json:"assets"`
+}
+
+// GithubAsset é um arquivo anexado a uma release.
+type GithubAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Size               int64  `json:"size"`
+	URL                string `json:"url"` // Endpoint da API (único caminho de download em repos privados)
+}
+
+// checkGithub resolve a release de um repositório. Config aceita:
+//   - repo, asset_filter: obrigatórios
+//   - include_prereleases: "true" lista /releases e escolhe a mais nova pela versão (version_scheme, padrão semver),
+//     para repos que só publicam prereleases (o /releases/latest as ignora)
+//   - include_drafts: "true" considera também rascunhos (exige token com acesso de escrita)
+//   - tag_pattern: regex que a tag deve casar, para repos com várias linhas de produto
+//     ("desktop-v*" vs "cli-v*"); se tiver grupo de captura, o 1º grupo vira a versão
+//   - tag_fallback: "true" recorre às tags (ver checkGithubTag) quando o repo não tem releases
+//   - private: "true" para repositórios privados: o browser_download_url continua publicado
+//     no catálogo, mas o hash é calculado baixando pelo endpoint de assets da API, com o token
+//   - body_checksum_regex: regex que extrai do texto da release o SHA-256 do asset, conferido
+//     contra o download (ver bodyChecksum)
+//
+// Sem body_checksum_regex, um asset irmão "<asset>.sha256" (ou .sha256sum) é usado
+// automaticamente para conferir o download.
+func checkGithub(ctx context.Context, conf map[string]string) (checkResult, error) {
+	repo, assetFilter := conf["repo"], conf["asset_filter"]
+
+	pattern, err := tagPattern(conf)
+	if err != nil {
+		return checkResult{}, err
+	}
+
+	var rel GithubRelease
+	if conf["include_prereleases"] == "true" || pattern != nil {
+		found := false
+		if releases, cached := cachedReleaseList(repo); cached {
+			rel, found = newestRelease(conf, pattern, releases)
+		}
+
+		// A API lista da release mais recente para a mais antiga, 100 por página: segue o
+		// Link rel="next" até a primeira página com uma release elegível
+		next := githubURL(fmt.Sprintf("/repos/%s/releases?per_page=100", repo))
+		for !found && next != "" {
+			var releases []GithubRelease
+			if next, err = githubGetPage(ctx, next, &releases); err != nil {
+				return checkResult{}, err
+			}
+			rel, found = newestRelease(conf, pattern, releases)
+		}
+		if !found {
+			if conf["tag_fallback"] == "true" {
+				verbosef("     %s não tem releases elegíveis, usando tags", repo)
+				return checkGithubTag(ctx, conf)
+			}
+			return checkResult{}, fmt.Errorf("nenhuma release elegível encontrada em %s", repo)
+		}
+	} else if cachedRel, cached := cachedLatestRelease(repo); cached {
+		rel = cachedRel
+	} else if err := githubGet(ctx, fmt.Sprintf("/repos/%s/releases/latest", repo), &rel); err != nil {
+		if errors.Is(err, errGithubNotFound) && conf["tag_fallback"] == "true" {
+			verbosef("     %s não tem releases, usando tags", repo)
+			return checkGithubTag(ctx, conf)
+		}
+		return checkResult{}, err
+	}
+
+	version := tagVersion(pattern, rel.TagName)
+
+	for _, asset := range rel.Assets {
+		if strings.Contains(strings.ToLower(asset.Name), assetFilter) {
+			res := checkResult{
+				Version:    version,
+				URL:        asset.BrowserDownloadURL,
+				Size:       asset.Size,
+				ReleasedAt: rel.PublishedAt,
+				Matches:    tagMatches(pattern, rel.TagName),
+			}
+			if conf["private"] == "true" {
+				if asset.URL == "" {
+					return checkResult{}, fmt.Errorf("asset '%s' sem URL da API", asset.Name)
+				}
+				res.FetchURL = asset.URL
+			}
+			if expr := conf["body_checksum_regex"]; expr != "" {
+				sum, err := bodyChecksum(expr, rel.Body, asset.Name)
+				if err != nil {
+					return checkResult{}, fmt.Errorf("release %s: %w", rel.TagName, err)
+				}
+				res.ExpectedSHA256, res.ExpectedFrom = sum, "texto da release "+rel.TagName
+			} else if sibling := findChecksumSibling(asset.Name, assetNames(rel.Assets)); sibling >= 0 {
+				res.ChecksumURL, res.ExpectedFrom = rel.Assets[sibling].BrowserDownloadURL, rel.Assets[sibling].Name
+				if conf["private"] == "true" {
+					res.ChecksumURL = rel.Assets[sibling].URL
+				}
+			}
+			return res, nil
+		}
+	}
+
+	return checkResult{}, fmt.Errorf("asset '%s' não encontrado na release %s", assetFilter, rel.TagName)
+}
+
+// assetNames lista os nomes dos assets de uma release.
+// newestRelease escolhe, entre as releases elegíveis (rascunhos e prereleases só quando
+// pedidos, tag casando com tag_pattern), a de maior versão.
+func newestRelease(conf map[string]string, pattern *regexp.Regexp, releases []GithubRelease) (GithubRelease, bool) {
+	var rel GithubRelease
+	found := false
+	for _, r := range releases {
+		if r.Draft && conf["include_drafts"] != "true" {
+			continue
+		}
+		if r.Prerelease && conf["include_prereleases"] != "true" {
+			continue
+		}
+		if pattern != nil && !pattern.MatchString(r.TagName) {
+			continue
+		}
+		if !found || compareVersions(versionScheme(conf), tagVersion(pattern, r.TagName), tagVersion(pattern, rel.TagName)) > 0 {
+			rel, found = r, true
+		}
+	}
+	return rel, found
+}
+
+func assetNames(assets []GithubAsset) []string {
+	names := make([]string, len(assets))
+	for i, a := range assets {
+		names[i] = a.Name
+	}
+	return names
+}
+
+// tagPattern compila o tag_pattern da fonte; nil quando não configurado.
+func tagPattern(conf map[string]string) (*regexp.Regexp, error) {
+	if conf["tag_pattern"] == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(conf["tag_pattern"])
+	if err != nil {
+		return nil, fmt.Errorf("tag_pattern inválido: %w", err)
+	}
+	return re, nil
+}
+
+// tagVersion extrai a versão de uma tag: o 1º grupo de captura do tag_pattern, se houver,
+// ou a própria tag sem o prefixo "v".
+func tagVersion(pattern *regexp.Regexp, tag string) string {
+	if pattern != nil {
+		if m := pattern.FindStringSubmatch(tag); len(m) > 1 && m[1] != "" {
+			return m[1]
+		}
+	}
+	return strings.TrimPrefix(tag, "v")
+}
+
+// tagMatches devolve os grupos do tag_pattern na tag (nil sem tag_pattern), para o probe.
+func tagMatches(pattern *regexp.Regexp, tag string) []string {
+	if pattern == nil {
+		return nil
+	}
+	return pattern.FindStringSubmatch(tag)
+}
+
+// GithubTag é um item de /repos/{repo}/tags.
+type GithubTag struct {
+	Name string `json:"name"`
+}
+
+// checkGithubTag resolve a versão pelas tags, para projetos que nunca criam GitHub Releases.
+// Config aceita:
+//   - repo: obrigatório
+//   - tag_pattern: regex que a tag deve casar (padrão: qualquer tag), como em checkGithub
+//   - url_template: URL do download com {repo}, {tag} e {version}
+//     (padrão: tarball do código-fonte da tag)
+func checkGithubTag(ctx context.Context, conf map[string]string) (checkResult, error) {
+	repo := conf["repo"]
+
+	pattern, err := tagPattern(conf)
+	if err != nil {
+		return checkResult{}, err
+	}
+
+	var tags []GithubTag
+	if err := githubGet(ctx, fmt.Sprintf("/repos/%s/tags?per_page=100", repo), &tags); err != nil {
+		return checkResult{}, err
+	}
+
+	newest := ""
+	for _, tag := range tags {
+		if pattern != nil && !pattern.MatchString(tag.Name) {
+			continue
+		}
+		if newest == "" || compareVersions(versionScheme(conf), tagVersion(pattern, tag.Name), tagVersion(pattern, newest)) > 0 {
+			newest = tag.Name
+		}
+	}
+	if newest == "" {
+		return checkResult{}, fmt.Errorf("nenhuma tag elegível encontrada em %s", repo)
+	}
+
+	template := conf["url_template"]
+	if template == "" {
+		template = "https://github.com/{repo}/archive/refs/tags/{tag}.tar.gz"
+	}
+	version := tagVersion(pattern, newest)
+	url := strings.NewReplacer("{repo}", repo, "{tag}", newest, "{version}", version).Replace(template)
+
+	return checkResult{Version: version, URL: url, Matches: tagMatches(pattern, newest)}, nil
+}
+
+// isGithubAssetAPI diz se url é o endpoint de download de um asset na API do GitHub.
+func isGithubAssetAPI(url string) bool {
+	return strings.HasPrefix(url, strings.TrimSuffix(cfg.GithubAPIURL, "/")+"/") && strings.Contains(url, "/releases/assets/")
+}
+
+func githubURL(path string) string {
+	return strings.TrimSuffix(cfg.GithubAPIURL, "/") + path
+}
+
+// githubGet faz um GET autenticado na API do GitHub e decodifica o JSON em out.
+// Com o cache de ETags ativo, respostas 304 reutilizam o corpo guardado.
+func githubGet(ctx context.Context, path string, out interface{}) error {
+	_, err := githubGetPage(ctx, githubURL(path), out)
+	return err
+}
+
+// githubGetPage é githubGet para listagens paginadas: recebe a URL completa e devolve a da
+// página seguinte (Link rel="next"), vazia na última. A próxima página só é seguida se
+// estiver na própria API, para o token não ir para outro host.
+func githubGetPage(ctx context.Context, url string, out interface{}) (string, error) {
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	debugf("     GET %s", url)
+
+	// Token é obrigatório no Actions para não tomar rate limit
+	if token := os.Getenv(cfg.GithubTokenEnv); token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	cached, hasCached := cachedETag(url)
+	if hasCached {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	client := &http.Client{Timeout: time.Duration(cfg.HTTPTimeout)}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && hasCached {
+		debugf("     304 Not Modified (cache)")
+		return cached.Next, json.Unmarshal(cached.Body, out)
+	}
+	if resp.StatusCode == 404 {
+		return "", errGithubNotFound
+	}
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("github status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return "", err
+	}
+	next := nextPageLink(resp.Header.Get("Link"))
+	if next != "" && !strings.HasPrefix(next, githubURL("/")) {
+		return "", fmt.Errorf("github: próxima página fora da API: %s", next)
+	}
+	storeETag(url, resp.Header.Get("ETag"), next, body)
+	return next, nil
+}
+
+// nextPageLink extrai a URL de rel="next" de um cabeçalho Link
+// (`<https://api.github.com/...&page=2>; rel="next", <...>; rel="last"`).
+func nextPageLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, ok := strings.Cut(link, ";")
+		if !ok {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ==========================================
+// GITHUB: CACHE DE REQUISIÇÕES CONDICIONAIS
+// ==========================================
+
+// etagEntry é a última resposta 200 de uma URL da API, com o ETag que a identifica e, nas
+// listagens, o link da página seguinte (o 304 não repete o cabeçalho Link).
+type etagEntry struct {
+	ETag string          `json:"etag"`
+	Next string          `json:"next,omitempty"`
+	Body json.RawMessage `json:"body"`
+}
+
+// githubETags persiste ETags entre execuções: a requisição seguinte envia If-None-Match
+// e, se o repositório não mudou, a API responde 304 sem consumir cota de rate limit.
+var githubETags = struct {
+	sync.Mutex
+	enabled bool
+	entries map[string]etagEntry // URL -> resposta
+}{entries: make(map[string]etagEntry)}
+
+// loadGithubETags carrega o cache do disco; um arquivo ausente ou inválido começa vazio.
+func loadGithubETags(path string) {
+	githubETags.Lock()
+	defer githubETags.Unlock()
+
+	githubETags.enabled = true
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &githubETags.entries); err != nil {
+		errorf(" [AVISO] Cache de ETags inválido em %s, ignorando: %v", path, err)
+		githubETags.entries = make(map[string]etagEntry)
+	}
+}
+
+func saveGithubETags(path string) {
+	githubETags.Lock()
+	defer githubETags.Unlock()
+
+	data, _ := json.Marshal(githubETags.entries)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		errorf(" [ERRO] Falha ao criar diretório do cache de ETags: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		errorf(" [ERRO] Falha ao gravar cache de ETags %s: %v", path, err)
+	}
+}
+
+func cachedETag(url string) (etagEntry, bool) {
+	githubETags.Lock()
+	defer githubETags.Unlock()
+	if !githubETags.enabled {
+		return etagEntry{}, false
+	}
+	entry, ok := githubETags.entries[url]
+	return entry, ok
+}
+
+func storeETag(url, etag, next string, body []byte) {
+	githubETags.Lock()
+	defer githubETags.Unlock()
+	if githubETags.enabled && etag != "" {
+		githubETags.entries[url] = etagEntry{ETag: etag, Next: next, Body: body}
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ==========================================
+// GITHUB: PRÉ-CARGA VIA GRAPHQL
+// ==========================================
+
+// githubGraphQLBatch é quantos repositórios vão em cada consulta GraphQL.
+const githubGraphQLBatch = 25
+
+// githubCache guarda as releases pré-carregadas via GraphQL. checkGithub consulta o cache
+// antes de chamar a API REST; repositórios ausentes seguem pelo caminho REST normal.
+var githubCache = struct {
+	sync.Mutex
+	latest map[string]GithubRelease   // repo -> release mais recente (equivalente a /releases/latest)
+	lists  map[string][]GithubRelease // repo -> releases recentes (equivalente a /releases)
+}{
+	latest: make(map[string]GithubRelease),
+	lists:  make(map[string][]GithubRelease),
+}
+
+func cachedLatestRelease(repo string) (GithubRelease, bool) {
+	githubCache.Lock()
+	defer githubCache.Unlock()
+	rel, ok := githubCache.latest[strings.ToLower(repo)]
+	return rel, ok
+}
+
+func cachedReleaseList(repo string) ([]GithubRelease, bool) {
+	githubCache.Lock()
+	defer githubCache.Unlock()
+	list, ok := githubCache.lists[strings.ToLower(repo)]
+	return list, ok
+}
+
+// prefetchGithub carrega, em lotes de várias fontes por consulta, as releases de todos os
+// repositórios usados por github_release. Com centenas de fontes isso troca centenas de
+// chamadas REST por poucas consultas GraphQL. Falhas são registradas e o REST assume.
+func prefetchGithub(ctx context.Context, sources []SourceApp) {
+	token := os.Getenv(cfg.GithubTokenEnv)
+	if token == "" {
+		errorf(" [AVISO] github_graphql exige token em %s; usando a API REST.", cfg.GithubTokenEnv)
+		return
+	}
+
+	seen := make(map[string]bool)
+	var repos []string
+	for _, src := range sources {
+		repo := strings.ToLower(src.Config["repo"])
+		// Repos privados precisam da URL de API dos assets, que o GraphQL não devolve
+		if src.Strategy != "github_release" || src.Config["private"] == "true" || !strings.Contains(repo, "/") || seen[repo] {
+			continue
+		}
+		seen[repo] = true
+		repos = append(repos, repo)
+	}
+
+	for start := 0; start < len(repos); start += githubGraphQLBatch {
+		end := min(start+githubGraphQLBatch, len(repos))
+		if err := prefetchGithubBatch(ctx, token, repos[start:end]); err != nil {
+			errorf(" [AVISO] Falha na consulta GraphQL (%d repos): %v. Usando a API REST.", end-start, err)
+		}
+	}
+	verbosef("     %d repositórios pré-carregados via GraphQL", len(githubCache.latest))
+}
+
+// graphQLRelease espelha os campos de Release usados pelo gerador.
+type graphQLRelease struct {
+	TagName       string    `json:"tagName"`
+	IsDraft       bool      `json:"isDraft"`
+	IsPrerelease  bool      `json:"isPrerelease"`
+	PublishedAt   time.Time `json:"publishedAt"`
+	Description   string    `json:"description"`
+	ReleaseAssets struct {
+		Nodes []struct {
+			Name        string `json:"name"`
+			DownloadURL string `json:"downloadUrl"`
+			Size        int64  `json:"size"`
+		} `json:"nodes"`
+	} `json:"releaseAssets"`
+}
+
+func (r graphQLRelease) toREST() GithubRelease {
+	rel := GithubRelease{
+		TagName:     r.TagName,
+		Draft:       r.IsDraft,
+		Prerelease:  r.IsPrerelease,
+		PublishedAt: r.PublishedAt,
+		Body:        r.Description,
+	}
+	for _, a := range r.ReleaseAssets.Nodes {
+		rel.Assets = append(rel.Assets, GithubAsset{Name: a.Name, BrowserDownloadURL: a.DownloadURL, Size: a.Size})
+	}
+	return rel
+}
+
+const graphQLReleaseFields = `tagName isDraft isPrerelease publishedAt description releaseAssets(first: 100) { nodes { name downloadUrl size } }`
+
+func prefetchGithubBatch(ctx context.Context, token string, repos []string) error {
+	var q strings.Builder
+	q.WriteString("query {")
+	for i, repo := range repos {
+		owner, name, _ := strings.Cut(repo, "/")
+		fmt.Fprintf(&q, ` r%d: repository(owner: %q, name: %q) { latestRelease { %s } releases(first: 30, orderBy: {field: CREATED_AT, direction: DESC}) { nodes { %s } } }`,
+			i, owner, name, graphQLReleaseFields, graphQLReleaseFields)
+	}
+	q.WriteString(" }")
+
+	body, _ := json.Marshal(map[string]string{"query": q.String()})
+	req, _ := http.NewRequestWithContext(ctx, "POST", githubGraphQLURL(), bytes.NewReader(body))
+	req.Header.Set("Authorization", "bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	debugf("     POST %s (%d repos)", req.URL, len(repos))
+
+	client := &http.Client{Timeout: time.Duration(cfg.HTTPTimeout)}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("github graphql status: %d", resp.StatusCode)
+	}
+
+	// Erros parciais (ex: um repo inexistente) vêm em "errors" junto com os demais dados
+	var out struct {
+		Data map[string]*struct {
+			LatestRelease *graphQLRelease `json:"latestRelease"`
+			Releases      struct {
+				Nodes []graphQLRelease `json:"nodes"`
+			} `json:"releases"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return err
+	}
+	for _, e := range out.Errors {
+		verbosef("     GraphQL: %s", e.Message)
+	}
+
+	githubCache.Lock()
+	defer githubCache.Unlock()
+	for i, repo := range repos {
+		node := out.Data[fmt.Sprintf("r%d", i)]
+		if node == nil {
+			continue
+		}
+		if node.LatestRelease != nil {
+			githubCache.latest[repo] = node.LatestRelease.toREST()
+		}
+		list := make([]GithubRelease, 0, len(node.Releases.Nodes))
+		for _, r := range node.Releases.Nodes {
+			list = append(list, r.toREST())
+		}
+		githubCache.lists[repo] = list
+	}
+	return nil
+}
+
+// githubGraphQLURL deriva o endpoint GraphQL da base REST configurada
+// (api.github.com/graphql, ou /api/graphql no GitHub Enterprise).
+func githubGraphQLURL() string {
+	base := strings.TrimSuffix(cfg.GithubAPIURL, "/")
+	if strings.HasSuffix(base, "/api/v3") {
+		return strings.TrimSuffix(base, "/v3") + "/graphql"
+	}
+	return base + "/graphql"
+}
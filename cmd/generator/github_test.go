@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestNextPageLink(t *testing.T) {
+	tests := []struct {
+		header, want string
+	}{
+		{"", ""},
+		{`<https://api.github.com/repositories/1/releases?per_page=100&page=2>; rel="next", <https://api.github.com/repositories/1/releases?per_page=100&page=5>; rel="last"`,
+			"https://api.github.com/repositories/1/releases?per_page=100&page=2"},
+		{`<https://api.github.com/x?page=1>; rel="prev", <https://api.github.com/x?page=3>; rel="next"`, "https://api.github.com/x?page=3"},
+		{`<https://api.github.com/x?page=1>; rel="first", <https://api.github.com/x?page=4>; rel="last"`, ""},
+	}
+	for _, tt := range tests {
+		if got := nextPageLink(tt.header); got != tt.want {
+			t.Errorf("nextPageLink(%q) = %q, esperado %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+// A primeira página só tem rascunhos e prereleases: a release elegível está na segunda, e a
+// terceira não deve ser pedida.
+func TestCheckGithubFollowsPages(t *testing.T) {
+	pages := map[string]string{
+		"1": `[{"tag_name": "v2.0.0-rc.1", "prerelease": true}, {"tag_name": "v2.0.0", "draft": true}]`,
+		"2": `[{"tag_name": "v1.2.0", "assets": [{"name": "foo_amd64.deb", "browser_download_url": "https://example.org/foo_amd64.deb", "size": 10}]}]`,
+		"3": `[{"tag_name": "v1.1.0", "assets": [{"name": "foo_amd64.deb", "browser_download_url": "https://example.org/old.deb", "size": 9}]}]`,
+	}
+	requests := make(map[string]int)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		requests[page]++
+		etag := `"p` + page + `"`
+		if page != "3" {
+			var n int
+			fmt.Sscan(page, &n)
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/acme/foo/releases?per_page=100&page=%d>; rel="next"`, server.URL, n+1))
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, pages[page])
+	}))
+	defer server.Close()
+
+	saved := cfg
+	t.Cleanup(func() {
+		cfg = saved
+		githubETags.enabled, githubETags.entries = false, make(map[string]etagEntry)
+	})
+	cfg.GithubAPIURL = server.URL
+	t.Setenv(cfg.GithubTokenEnv, "")
+	loadGithubETags(filepath.Join(t.TempDir(), "etags.json"))
+
+	conf := map[string]string{"repo": "acme/foo", "asset_filter": "amd64.deb", "tag_pattern": "^v"}
+	for run := 1; run <= 2; run++ { // A segunda execução recebe 304 e segue o link guardado no cache
+		res, err := checkGithub(context.Background(), conf)
+		if err != nil {
+			t.Fatalf("execução %d: %v", run, err)
+		}
+		if res.Version != "1.2.0" || res.URL != "https://example.org/foo_amd64.deb" {
+			t.Errorf("execução %d: resultado = %+v", run, res)
+		}
+	}
+	if requests["1"] != 2 || requests["2"] != 2 || requests["3"] != 0 {
+		t.Errorf("requisições por página = %v", requests)
+	}
+}
+
+// Um Link apontando para outro host não é seguido (levaria o token junto).
+func TestGithubGetPageRejectsForeignNext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", `<https://evil.example/releases?page=2>; rel="next"`)
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	saved := cfg
+	t.Cleanup(func() { cfg = saved })
+	cfg.GithubAPIURL = server.URL
+
+	var releases []GithubRelease
+	if _, err := githubGetPage(context.Background(), githubURL("/repos/acme/foo/releases"), &releases); err == nil {
+		t.Error("próxima página fora da API deveria ser recusada")
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// CLIENTE HTTP DAS ESTRATÉGIAS
+// ==========================================
+
+// defaultMaxRedirects é o limite quando a fonte não define max_redirects (como no net/http).
+const defaultMaxRedirects = 10
+
+// headClient monta o client dos HEADs de direct_url_head e direct_static: cada salto da
+// cadeia de redirects vai para o log em debug (para entender um "regex falhou na url" sem
+// repetir o curl à mão) e max_redirects na config da fonte limita a cadeia (0 = não seguir).
+func headClient(conf map[string]string) (*http.Client, error) {
+	maxRedirects := defaultMaxRedirects
+	if v := conf["max_redirects"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("max_redirects inválido: %q", v)
+		}
+		maxRedirects = n
+	}
+
+	transport, err := sourceTransport(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout:   time.Duration(cfg.HTTPTimeout),
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if maxRedirects == 0 {
+				return http.ErrUseLastResponse
+			}
+			debugf("     %d -> %s", req.Response.StatusCode, req.URL)
+			if len(via) > maxRedirects {
+				return fmt.Errorf("mais de %d redirects a partir de %s", maxRedirects, via[0].URL)
+			}
+			return nil
+		},
+	}, nil
+}
+
+// sourceMaxBodySize limita as respostas de API e metadados lidas pelas estratégias.
+const sourceMaxBodySize = 32 << 20
+
+// sourceGet faz um GET de API ou metadados com o TLS e as credenciais da fonte e devolve o
+// corpo; status diferente de 200 vira erro.
+func sourceGet(ctx context.Context, conf map[string]string, url, accept string) ([]byte, error) {
+	body, _, err := sourceFetch(ctx, conf, url, accept)
+	return body, err
+}
+
+// sourceFetch é o sourceGet que devolve também a URL final, após redirects (para resolver
+// caminhos relativos do documento baixado).
+func sourceFetch(ctx context.Context, conf map[string]string, url, accept string) ([]byte, *neturl.URL, error) {
+	transport, err := sourceTransport(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Registries como o crates.io recusam requisições sem User-Agent identificável
+	req.Header.Set("User-Agent", "updater-registry (+https://github.com/luizhanauer/updater-registry)")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if err := applySourceAuth(req, conf); err != nil {
+		return nil, nil, err
+	}
+	debugf("     GET %s", url)
+
+	client := &http.Client{Timeout: time.Duration(cfg.HTTPTimeout), Transport: transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, nil, fmt.Errorf("status %d em %s", resp.StatusCode, url)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, sourceMaxBodySize))
+	return body, resp.Request.URL, err
+}
+
+// sourceGetJSON é o sourceGet de APIs JSON.
+func sourceGetJSON(ctx context.Context, conf map[string]string, url string, out any) error {
+	body, err := sourceGet(ctx, conf, url, "application/json")
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("resposta inválida de %s: %w", url, err)
+	}
+	return nil
+}
+
+// tlsVersions mapeia os valores aceitos em tls_min_version.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// sourceTLSConfig monta a configuração TLS de fornecedores com CA privada. Config aceita:
+//   - tls_ca_file: bundle PEM com CAs adicionais (somadas às do sistema)
+//   - tls_min_version: "1.0" a "1.3" (padrão: o do crypto/tls)
+//   - tls_insecure_skip_verify: "true" desliga a verificação do certificado; o lint avisa
+//   - tls_cert_file/tls_key_file ou tls_cert_env/tls_key_env: certificado e chave do cliente
+//     (PEM, em arquivo ou numa variável de ambiente com o conteúdo), para APIs atrás de mTLS
+//
+// Devolve nil quando a fonte não configura nada, para manter o padrão do Go.
+func sourceTLSConfig(conf map[string]string) (*tls.Config, error) {
+	cert, err := clientCertificate(conf)
+	if err != nil {
+		return nil, err
+	}
+	if cert == nil && conf["tls_ca_file"] == "" && conf["tls_min_version"] == "" && conf["tls_insecure_skip_verify"] != "true" {
+		return nil, nil
+	}
+
+	tlsConf := &tls.Config{}
+	if cert != nil {
+		tlsConf.Certificates = []tls.Certificate{*cert}
+	}
+	if path := conf["tls_ca_file"]; path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("tls_ca_file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls_ca_file: nenhum certificado PEM em %s", path)
+		}
+		tlsConf.RootCAs = pool
+	}
+	if v := conf["tls_min_version"]; v != "" {
+		version, ok := tlsVersions[v]
+		if !ok {
+			return nil, fmt.Errorf("tls_min_version inválido: %q (use 1.0, 1.1, 1.2 ou 1.3)", v)
+		}
+		tlsConf.MinVersion = version
+	}
+	if conf["tls_insecure_skip_verify"] == "true" {
+		debugf("     TLS sem verificação de certificado (tls_insecure_skip_verify)")
+		tlsConf.InsecureSkipVerify = true
+	}
+	return tlsConf, nil
+}
+
+// clientCertificate carrega o par certificado/chave do mTLS; nil quando a fonte não usa.
+func clientCertificate(conf map[string]string) (*tls.Certificate, error) {
+	var certPEM, keyPEM []byte
+	var err error
+	switch {
+	case conf["tls_cert_file"] != "" || conf["tls_key_file"] != "":
+		if conf["tls_cert_file"] == "" || conf["tls_key_file"] == "" {
+			return nil, fmt.Errorf("mTLS: 'tls_cert_file' e 'tls_key_file' devem vir juntos")
+		}
+		if certPEM, err = os.ReadFile(conf["tls_cert_file"]); err != nil {
+			return nil, fmt.Errorf("tls_cert_file: %w", err)
+		}
+		if keyPEM, err = os.ReadFile(conf["tls_key_file"]); err != nil {
+			return nil, fmt.Errorf("tls_key_file: %w", err)
+		}
+	case conf["tls_cert_env"] != "" || conf["tls_key_env"] != "":
+		if conf["tls_cert_env"] == "" || conf["tls_key_env"] == "" {
+			return nil, fmt.Errorf("mTLS: 'tls_cert_env' e 'tls_key_env' devem vir juntos")
+		}
+		certPEM, keyPEM = []byte(os.Getenv(conf["tls_cert_env"])), []byte(os.Getenv(conf["tls_key_env"]))
+		if len(certPEM) == 0 || len(keyPEM) == 0 {
+			return nil, fmt.Errorf("mTLS: variáveis %s/%s vazias", conf["tls_cert_env"], conf["tls_key_env"])
+		}
+	default:
+		return nil, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("mTLS: %w", err)
+	}
+	return &cert, nil
+}
+
+// sourceTransport devolve o transport HTTP da fonte; nil (http.DefaultTransport) quando ela
+// não tem configuração TLS própria.
+func sourceTransport(conf map[string]string) (http.RoundTripper, error) {
+	tlsConf, err := sourceTLSConfig(conf)
+	if err != nil || tlsConf == nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConf
+	return transport, nil
+}
+
+// errHTMLResponse marca um download que devolveu uma página HTML (login, limite de requisições,
+// aviso de CDN) no lugar do artefato. Vai para o relatório como error_class "html_response".
+var errHTMLResponse = errors.New("a origem devolveu uma página HTML no lugar do artefato")
+
+// htmlSniffSize é quanto do início do corpo é inspecionado.
+const htmlSniffSize = 512
+
+// guardHTML confere o Content-Type e os primeiros bytes da resposta de um download antes do
+// hash: HTML nunca é o artefato esperado. allow_html: "true" na config da fonte desliga a checagem.
+func guardHTML(resp *http.Response, conf map[string]string) (io.ReadCloser, error) {
+	if conf["allow_html"] == "true" {
+		return resp.Body, nil
+	}
+	if media, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil && (media == "text/html" || media == "application/xhtml+xml") {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w (Content-Type: %s)", errHTMLResponse, media)
+	}
+
+	// Servidores que mandam application/octet-stream (ou nada) para qualquer coisa
+	peeked := bufio.NewReaderSize(resp.Body, htmlSniffSize)
+	head, _ := peeked.Peek(htmlSniffSize)
+	if looksLikeHTML(head) {
+		resp.Body.Close()
+		return nil, errHTMLResponse
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{peeked, resp.Body}, nil
+}
+
+// looksLikeHTML reconhece o início de um documento HTML (após BOM e espaços).
+func looksLikeHTML(head []byte) bool {
+	head = bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")), " \t\r\n")
+	for _, prefix := range []string{"<!doctype html", "<html", "<head", "<body"} {
+		if len(head) >= len(prefix) && strings.EqualFold(string(head[:len(prefix)]), prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"regexp"
+	"strings"
+)
+
+// ==========================================
+// DICAS DE INSTALAÇÃO E REMOÇÃO
+// ==========================================
+
+// InstallHints traz o que um cliente que orquestra instalações precisa além do artefato:
+// scripts a rodar antes e depois, se a instalação exige reiniciar e os argumentos da
+// instalação silenciosa de .exe/.msi. Declarado na fonte; o gerador só repassa.
+type InstallHints struct {
+	PreInstall     *InstallScript `json:"pre_install,omitempty"`     // Roda antes de instalar o artefato
+	PostInstall    *InstallScript `json:"post_install,omitempty"`    // Roda depois de instalar o artefato
+	RebootRequired bool           `json:"reboot_required,omitempty"` // A instalação só vale depois de reiniciar
+	SilentArgs     []string       `json:"silent_args,omitempty"`     // Ex: ["/S"] (NSIS), ["/quiet", "/norestart"] (msiexec)
+}
+
+// InstallScript é um script baixado pelo cliente. O checksum é obrigatório: o cliente só o
+// executa se o SHA-256 do arquivo conferir.
+type InstallScript struct {
+	URL      string `json:"url"`
+	Checksum string `json:"checksum"` // SHA-256 em hexadecimal
+}
+
+// scripts percorre os scripts declarados, com o nome do campo.
+func (h *InstallHints) scripts() iter.Seq2[string, *InstallScript] {
+	return func(yield func(string, *InstallScript) bool) {
+		for _, s := range []struct {
+			name   string
+			script *InstallScript
+		}{{"pre_install", h.PreInstall}, {"post_install", h.PostInstall}} {
+			if s.script != nil && !yield(s.name, s.script) {
+				return
+			}
+		}
+	}
+}
+
+// lintInstallHints confere a forma do bloco install_hints.
+func lintInstallHints(src SourceApp, add func(isError bool, format string, args ...interface{})) {
+	hints := src.InstallHints
+	if hints == nil {
+		return
+	}
+	if hints.PreInstall == nil && hints.PostInstall == nil && !hints.RebootRequired && len(hints.SilentArgs) == 0 {
+		add(false, "bloco 'install_hints' vazio")
+		return
+	}
+	for name, script := range hints.scripts() {
+		switch {
+		case script.URL == "":
+			add(true, "install_hints.%s sem url", name)
+		case !strings.HasPrefix(script.URL, "https://"):
+			add(false, "install_hints.%s.url não é HTTPS: %s", name, script.URL)
+		}
+		if !sha256HexRe.MatchString(script.Checksum) {
+			add(true, "install_hints.%s.checksum deve ser o SHA-256 do script, em hexadecimal", name)
+		}
+	}
+	if len(hints.SilentArgs) > 0 && src.InstallType != "exe" && src.InstallType != "msi" {
+		add(false, "install_hints.silent_args só se aplica a install_type exe ou msi (é %q)", src.InstallType)
+	}
+}
+
+// checkInstallScript baixa o script (com o TLS e as credenciais da fonte) e confere o checksum
+// declarado; um script alterado upstream seria recusado por todos os clientes.
+func checkInstallScript(ctx context.Context, src SourceApp, script *InstallScript) error {
+	body, err := sourceGet(ctx, src.Config, script.URL, "")
+	if err != nil {
+		return err
+	}
+	if got := sha256Hex(body); !strings.EqualFold(got, script.Checksum) {
+		return fmt.Errorf("checksum não confere (o script tem %s)", got)
+	}
+	return nil
+}
+
+// UninstallInfo diz ao cliente como remover o app de forma limpa. Declarado na fonte; o gerador
+// só repassa.
+type UninstallInfo struct {
+	Package     string   `json:"package,omitempty"`      // Nome no apt/dnf (vazio = package_name da entrada)
+	Path        string   `json:"path,omitempty"`         // Desinstalador no Windows, ex: %ProgramFiles%\Foo\uninstall.exe
+	Args        []string `json:"args,omitempty"`         // Argumentos do desinstalador, ex: ["/S"]
+	ProductCode string   `json:"product_code,omitempty"` // Instalações .msi: msiexec /x {código}
+}
+
+var msiProductCode = regexp.MustCompile(`^\{[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}\}$`)
+
+// lintUninstall confere a forma do bloco uninstall.
+func lintUninstall(src SourceApp, add func(isError bool, format string, args ...interface{})) {
+	u := src.Uninstall
+	if u == nil {
+		return
+	}
+	switch {
+	case u.Package == "" && u.Path == "" && u.ProductCode == "" && len(u.Args) == 0:
+		add(false, "bloco 'uninstall' vazio")
+	case len(u.Args) > 0 && u.Path == "":
+		add(true, "uninstall.args sem uninstall.path")
+	case u.ProductCode != "" && !msiProductCode.MatchString(u.ProductCode):
+		add(true, "uninstall.product_code inválido: %q (use o GUID entre chaves, ex: {12345678-1234-1234-1234-123456789012})", u.ProductCode)
+	case u.Package != "" && (src.InstallType == "exe" || src.InstallType == "msi"):
+		add(false, "uninstall.package é para apt/dnf, mas install_type é %q", src.InstallType)
+	case (u.Path != "" || u.ProductCode != "") && (src.InstallType == "deb" || src.InstallType == "rpm"):
+		add(false, "uninstall.path/product_code são para Windows, mas install_type é %q", src.InstallType)
+	}
+}
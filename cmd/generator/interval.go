@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// ==========================================
+// INTERVALO ENTRE CHECAGENS (CHECK_INTERVAL)
+// ==========================================
+
+// Uma fonte pode declarar check_interval (ex: "24h"): verificada com sucesso há menos que
+// isso, ela fica de fora da execução, sem checagem nem download. Assim o gerador roda de hora
+// em hora e os fornecedores lentos só são consultados uma vez por dia.
+
+// forcedCheckKey marca no contexto uma checagem pedida explicitamente (refresh sob demanda).
+type forcedCheckKey struct{}
+
+// withForcedCheck devolve um contexto cujas checagens ignoram check_interval e o circuit breaker.
+func withForcedCheck(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcedCheckKey{}, true)
+}
+
+// forcedCheck diz se a checagem de id foi pedida explicitamente: pelo refresh sob demanda ou
+// selecionando o app com -only.
+func forcedCheck(ctx context.Context, id string) bool {
+	forced, _ := ctx.Value(forcedCheckKey{}).(bool)
+	return forced || slices.Contains(cfg.Only, id)
+}
+
+// checkInterval devolve o check_interval da fonte (0 = checar sempre; o lint acusa valores inválidos).
+func checkInterval(src SourceApp) time.Duration {
+	if src.CheckInterval == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(src.CheckInterval)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// nextCheck devolve quando app volta a ser checado (zero = já, ou nunca verificado).
+func nextCheck(src SourceApp, app CatalogApp) time.Time {
+	interval := checkInterval(src)
+	if interval == 0 || app.LastChecked.IsZero() {
+		return time.Time{}
+	}
+	return app.LastChecked.Add(interval)
+}
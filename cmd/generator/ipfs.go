@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// PUBLICAÇÃO NO IPFS
+// ==========================================
+
+// IPFSConfig publica os artefatos (e o próprio catálogo) num nó IPFS via API HTTP do Kubo,
+// registrando o CID de cada artefato, para distribuição endereçada por conteúdo.
+type IPFSConfig struct {
+	API     string `json:"api"`     // ex: http://127.0.0.1:5001 (vazio = desativado)
+	Pin     bool   `json:"pin"`     // Fixa o conteúdo no nó (recomendado)
+	Catalog bool   `json:"catalog"` // Publica também o catalog.json a cada gravação
+}
+
+func (c IPFSConfig) enabled() bool {
+	return c.API != ""
+}
+
+// ipfsUpload recebe o stream do artefato enquanto ele é baixado e o envia ao nó IPFS.
+// Uma falha no IPFS não pode derrubar o download: a partir do primeiro erro, as escritas
+// são descartadas e o erro aparece apenas em wait().
+type ipfsUpload struct {
+	pw     *io.PipeWriter
+	failed error
+	done   chan ipfsAddResult
+}
+
+type ipfsAddResult struct {
+	cid string
+	err error
+}
+
+func startIPFSUpload(name string) *ipfsUpload {
+	pr, pw := io.Pipe()
+	up := &ipfsUpload{pw: pw, done: make(chan ipfsAddResult, 1)}
+	go func() {
+		cid, err := ipfsAdd(name, pr)
+		pr.CloseWithError(err) // Libera o escritor se o nó parar de ler
+		up.done <- ipfsAddResult{cid, err}
+	}()
+	return up
+}
+
+func (u *ipfsUpload) Write(b []byte) (int, error) {
+	if u.failed == nil {
+		if _, err := u.pw.Write(b); err != nil {
+			u.failed = err
+		}
+	}
+	return len(b), nil
+}
+
+// wait encerra o envio e devolve o CID. Com ok=false (download incompleto), o envio é abortado.
+func (u *ipfsUpload) wait(ok bool) (string, error) {
+	if ok {
+		u.pw.Close()
+	} else {
+		u.pw.CloseWithError(fmt.Errorf("download interrompido"))
+	}
+	res := <-u.done
+	return res.cid, res.err
+}
+
+// ipfsAdd envia o conteúdo para /api/v0/add e devolve o CID (v1).
+func ipfsAdd(name string, r io.Reader) (string, error) {
+	body, mw := io.Pipe()
+	form := multipart.NewWriter(mw)
+	go func() {
+		part, err := form.CreateFormFile("file", name)
+		if err == nil {
+			_, err = io.Copy(part, r)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		mw.CloseWithError(err)
+	}()
+
+	url := fmt.Sprintf("%s/api/v0/add?cid-version=1&pin=%t", strings.TrimSuffix(cfg.IPFS.API, "/"), cfg.IPFS.Pin)
+	debugf("     IPFS add %s", name)
+	client := &http.Client{Timeout: time.Duration(cfg.DownloadTimeout)}
+	resp, err := client.Post(url, form.FormDataContentType(), body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("ipfs status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var out struct {
+		Hash string `json:"Hash"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	return out.Hash, nil
+}
+
+// publishCatalogIPFS adiciona o catalog.json gravado ao IPFS e devolve seu CID.
+func publishCatalogIPFS(location string) (string, error) {
+	data, err := readBlob(location)
+	if err != nil {
+		return "", err
+	}
+	return ipfsAdd(path.Base(location), bytes.NewReader(data))
+}
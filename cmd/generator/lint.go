@@ -0,0 +1,493 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// LINT DAS FONTES
+// ==========================================
+
+// lintIssue é um problema encontrado numa fonte. Erros impedem a fonte de funcionar;
+// avisos apontam configurações suspeitas.
+type lintIssue struct {
+	File    string
+	ID      string
+	Error   bool
+	Message string
+}
+
+func (i lintIssue) String() string {
+	level := "aviso"
+	if i.Error {
+		level = "erro"
+	}
+	return fmt.Sprintf("%s: %s: [%s] %s", i.File, i.ID, level, i.Message)
+}
+
+// strategyKeys lista, por estratégia, as chaves de config obrigatórias e opcionais.
+// exec, script e wasm recebem a config inteira, então aceitam qualquer chave extra.
+var strategyKeys = map[string]struct {
+	required, optional []string
+	openEnded          bool
+}{
+	"github_release":  {required: []string{"repo", "asset_filter"}, optional: []string{"include_prereleases", "include_drafts", "tag_pattern", "tag_fallback", "url_template", "private", "body_checksum_regex"}},
+	"github_tag":      {required: []string{"repo"}, optional: []string{"tag_pattern", "url_template"}},
+	"direct_url_head": {required: []string{"url", "regex"}, optional: []string{"max_redirects"}},
+	"direct_static":   {required: []string{"url"}, optional: []string{"regex", "max_redirects"}},
+	"exec":            {required: []string{"command"}, optional: []string{"args", "timeout"}, openEnded: true},
+	"script":          {openEnded: true},
+	"wasm":            {required: []string{"module"}, optional: []string{"timeout"}, openEnded: true},
+	"browser":         {required: []string{"url", "selector", "regex"}, optional: []string{"attribute", "version_selector", "timeout"}},
+	"ftp":             {required: []string{"url", "pattern"}},
+	"sftp":            {required: []string{"url", "pattern"}, optional: []string{"public_url"}},
+	"nexus":           {required: []string{"url", "repository", "pattern"}, optional: []string{"group", "name"}},
+	"artifactory":     {required: []string{"url", "repository", "pattern"}, optional: []string{"path"}},
+	"maven":           {required: []string{"group_id", "artifact_id"}, optional: []string{"url", "extension", "classifier", "pattern"}},
+	"goproxy":         {required: []string{"module"}, optional: []string{"url", "pattern", "include_prereleases"}},
+	"crates":          {required: []string{"crate"}, optional: []string{"url", "pattern", "include_prereleases"}},
+	"rubygems":        {required: []string{"gem"}, optional: []string{"url", "platform", "pattern", "include_prereleases"}},
+	"vsx":             {required: []string{"extension"}, optional: []string{"registry", "url", "target_platform", "include_prereleases"}},
+	"jetbrains":       {required: []string{"plugin", "build"}, optional: []string{"channel", "url"}},
+	"electron":        {required: []string{"url"}, optional: []string{"file_filter"}},
+	"squirrel":        {required: []string{"url"}, optional: []string{"package", "pattern", "include_prereleases"}},
+	"appinstaller":    {required: []string{"url"}},
+}
+
+// downloadKeys valem para qualquer estratégia: são lidas no download quando a URL resolvida
+// é FTP/SFTP (ver openArtifact); username_env/password_env também autenticam HTTP (ver applySourceAuth)
+// e allow_html aceita um artefato HTML (ver guardHTML).
+var downloadKeys = []string{"username_env", "password_env", "key_file", "key_env", "passphrase_env", "known_hosts", "insecure_ignore_host_key", "allow_html"}
+
+// authKeys valem para qualquer estratégia: credenciais HTTP da fonte (ver applySourceAuth).
+var authKeys = []string{"auth_hosts", "token_env", "oauth_token_url", "oauth_client_id_env", "oauth_client_secret_env", "oauth_scope", "oauth_audience", "oauth_client_auth"}
+
+// tlsKeys valem para qualquer estratégia: TLS da fonte nos HEADs e downloads (ver sourceTLSConfig).
+var tlsKeys = []string{"tls_ca_file", "tls_min_version", "tls_insecure_skip_verify", "tls_cert_file", "tls_key_file", "tls_cert_env", "tls_key_env"}
+
+// versionKeys valem para qualquer estratégia: regras de normalização da versão (ver normalizeVersion).
+var versionKeys = []string{"version_regex", "version_trim_prefix", "version_trim_suffix", "version_scheme", "version_source"}
+
+// regexKeys são compiladas pelas estratégias; um regex inválido só apareceria na execução.
+var regexKeys = []string{"regex", "tag_pattern", "pattern", "version_regex"}
+
+// broadAssetFilters são filtros que costumam casar com vários assets (arquiteturas, .sig, ...).
+var broadAssetFilters = map[string]bool{
+	"deb": true, "rpm": true, "appimage": true, "zip": true, "tar.gz": true, "tgz": true,
+	"exe": true, "msi": true, "dmg": true, "linux": true, "amd64": true, "x86_64": true, "x64": true,
+}
+
+// replacement liga uma fonte (arquivo e id) ao id que ela referencia.
+type replacement struct{ file, id, target string }
+
+// lintSourceFiles analisa os arquivos de fontes sem abortar no primeiro problema.
+// online faz um HEAD em cada icon_url e baixa os scripts de install_hints para conferir o
+// checksum (usa a rede, por isso só no subcomando lint).
+func lintSourceFiles(ctx context.Context, paths []string, online bool) ([]lintIssue, error) {
+	files, err := expandSourcePaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []lintIssue
+	origin := make(map[string]string)
+	// replaced_by só pode ser conferido depois de ler todos os arquivos
+	var replaced []replacement
+	var linted []lintedSource                  // Para depends_on e conflicts_with
+	aliasOwner := make(map[string]replacement) // alias -> fonte que o declara
+	for _, file := range files {
+		sources, err := readSourceFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, src := range sources {
+			if src.ID == "" {
+				issues = append(issues, lintIssue{file, "(sem id)", true, "fonte sem 'id'"})
+				continue
+			}
+			if prev, dup := origin[src.ID]; dup {
+				issues = append(issues, lintIssue{file, src.ID, true, "id duplicado (já declarado em " + prev + ")"})
+			}
+			origin[src.ID] = file
+			linted = append(linted, lintedSource{file, src})
+			if src.ReplacedBy != "" && src.ReplacedBy != src.ID {
+				replaced = append(replaced, replacement{file, src.ID, src.ReplacedBy})
+			}
+			for _, alias := range src.Aliases {
+				switch prev, dup := aliasOwner[alias]; {
+				case alias == src.ID:
+					issues = append(issues, lintIssue{file, src.ID, true, "alias igual ao próprio id: " + alias})
+				case dup:
+					issues = append(issues, lintIssue{file, src.ID, true, "alias " + alias + " já declarado por " + prev.id})
+				default:
+					aliasOwner[alias] = replacement{file, src.ID, alias}
+				}
+			}
+			for _, msg := range lintSource(src) {
+				issues = append(issues, lintIssue{file, src.ID, msg.Error, msg.Message})
+			}
+			if online && src.IconURL != "" {
+				if err := checkIconURL(ctx, src.IconURL); err != nil {
+					issues = append(issues, lintIssue{file, src.ID, false, "icon_url inacessível: " + err.Error()})
+				}
+			}
+			if online && src.InstallHints != nil {
+				for name, script := range src.InstallHints.scripts() {
+					if script.URL == "" || !sha256HexRe.MatchString(script.Checksum) {
+						continue // Já acusado em lintInstallHints
+					}
+					if err := checkInstallScript(ctx, src, script); err != nil {
+						issues = append(issues, lintIssue{file, src.ID, true, fmt.Sprintf("install_hints.%s: %v", name, err)})
+					}
+				}
+			}
+		}
+	}
+	for alias, owner := range aliasOwner {
+		if _, ok := origin[alias]; ok {
+			issues = append(issues, lintIssue{owner.file, owner.id, true, "alias " + alias + " é o id de outra fonte"})
+		}
+	}
+	for _, r := range replaced {
+		if _, ok := origin[r.target]; !ok {
+			issues = append(issues, lintIssue{r.file, r.id, true, "replaced_by aponta para um app inexistente: " + r.target})
+		}
+	}
+	issues = append(issues, lintDependencies(linted, origin, aliasOwner)...)
+	return issues, nil
+}
+
+// lintedSource é uma fonte lida pelo lint, com o arquivo de origem.
+type lintedSource struct {
+	file string
+	src  SourceApp
+}
+
+// lintDependencies confere depends_on e conflicts_with entre todas as fontes: ids que não
+// existem (ou que são aliases) e ciclos de dependência, que impediriam qualquer ordem de
+// instalação.
+func lintDependencies(sources []lintedSource, origin map[string]string, aliasOwner map[string]replacement) []lintIssue {
+	var issues []lintIssue
+	depends := make(map[string][]string)
+	for _, s := range sources {
+		for _, field := range []struct {
+			name string
+			ids  []string
+		}{{"depends_on", s.src.DependsOn}, {"conflicts_with", s.src.ConflictsWith}} {
+			for _, id := range field.ids {
+				if owner, ok := aliasOwner[id]; ok {
+					issues = append(issues, lintIssue{s.file, s.src.ID, false, fmt.Sprintf("%s usa o alias %s; prefira o id atual %s", field.name, id, owner.id)})
+					id = owner.id
+				} else if _, ok := origin[id]; !ok {
+					issues = append(issues, lintIssue{s.file, s.src.ID, true, fmt.Sprintf("%s aponta para um app inexistente: %s", field.name, id)})
+					continue
+				}
+				if field.name == "depends_on" && id != s.src.ID { // O próprio app já é acusado em lintSource
+					depends[s.src.ID] = append(depends[s.src.ID], id)
+				}
+			}
+		}
+	}
+
+	// Busca em profundidade; cada ciclo é acusado uma vez, na fonte em que a busca o encontra
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var visit func(id string, path []string) []string
+	visit = func(id string, path []string) []string {
+		switch state[id] {
+		case visiting:
+			return append(path, id)
+		case done:
+			return nil
+		}
+		state[id] = visiting
+		for _, dep := range depends[id] {
+			if cycle := visit(dep, append(path, id)); cycle != nil {
+				return cycle
+			}
+		}
+		state[id] = done
+		return nil
+	}
+	for _, s := range sources {
+		if cycle := visit(s.src.ID, nil); cycle != nil {
+			issues = append(issues, lintIssue{s.file, s.src.ID, true, "ciclo em depends_on: " + strings.Join(cycle, " -> ")})
+			for id, st := range state {
+				if st == visiting {
+					state[id] = done // A busca foi interrompida no meio; o ciclo já foi acusado
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// requirementVersion é a forma aceita para versões mínimas em requirements: números separados por ponto.
+var requirementVersion = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+// lintSource aplica as regras que dependem só da própria fonte.
+func lintSource(src SourceApp) []lintIssue {
+	var issues []lintIssue
+	add := func(isError bool, format string, args ...interface{}) {
+		issues = append(issues, lintIssue{Error: isError, Message: fmt.Sprintf(format, args...)})
+	}
+
+	keys, known := strategyKeys[src.Strategy]
+	if !known {
+		add(true, "estratégia desconhecida: %q", src.Strategy)
+		return issues
+	}
+
+	for _, key := range keys.required {
+		if src.Config[key] == "" {
+			add(true, "config '%s' é obrigatória para %s", key, src.Strategy)
+		}
+	}
+	if src.Strategy == "script" && src.Config["script"] == "" && src.Config["script_file"] == "" {
+		add(true, "config 'script' ou 'script_file' é obrigatória para script")
+	}
+
+	if !keys.openEnded {
+		allowed := make(map[string]bool)
+		for _, list := range [][]string{keys.required, keys.optional, downloadKeys, authKeys, tlsKeys, versionKeys} {
+			for _, key := range list {
+				allowed[key] = true
+			}
+		}
+		var orphaned []string
+		for key := range src.Config {
+			if !allowed[key] {
+				orphaned = append(orphaned, key)
+			}
+		}
+		sort.Strings(orphaned)
+		for _, key := range orphaned {
+			add(false, "config '%s' é ignorada pela estratégia %s", key, src.Strategy)
+		}
+	}
+
+	if scheme := src.Config["version_scheme"]; scheme != "" && !validVersionScheme(scheme) {
+		add(true, "version_scheme desconhecido: %q (use semver, debian, calver ou string)", scheme)
+	}
+	if source := src.Config["version_source"]; source != "" && source != "package" {
+		add(true, "version_source desconhecido: %q (use package)", source)
+	}
+	if src.Config["oauth_token_url"] != "" && (src.Config["oauth_client_id_env"] == "" || src.Config["oauth_client_secret_env"] == "") {
+		add(true, "oauth_token_url exige 'oauth_client_id_env' e 'oauth_client_secret_env'")
+	}
+	if src.Config["oauth_token_url"] != "" && src.Config["username_env"] != "" && src.Config["url"] != "" && !isFTPURL(src.Config["url"]) {
+		add(false, "oauth_token_url e username_env configurados; o token OAuth tem precedência")
+	}
+	if (src.Config["username_env"] != "" || src.Config["token_env"] != "" || src.Config["oauth_token_url"] != "") && len(authHosts(src.Config)) == 0 {
+		add(false, "credenciais HTTP sem 'url' nem 'auth_hosts' não são enviadas a nenhum host")
+	}
+	// Arquivos e variáveis do TLS podem só existir no CI; aqui vale apenas a forma
+	if v := src.Config["tls_min_version"]; v != "" && tlsVersions[v] == 0 {
+		add(true, "tls_min_version inválido: %q (use 1.0, 1.1, 1.2 ou 1.3)", v)
+	}
+	for _, pair := range [][2]string{{"tls_cert_file", "tls_key_file"}, {"tls_cert_env", "tls_key_env"}} {
+		if (src.Config[pair[0]] == "") != (src.Config[pair[1]] == "") {
+			add(true, "'%s' e '%s' devem vir juntos", pair[0], pair[1])
+		}
+	}
+	if src.Config["tls_insecure_skip_verify"] == "true" {
+		add(false, "tls_insecure_skip_verify desativa a verificação do certificado; prefira tls_ca_file")
+	}
+
+	for _, key := range regexKeys {
+		if v := src.Config[key]; v != "" {
+			if _, err := regexp.Compile(v); err != nil {
+				add(true, "%s inválido: %v", key, err)
+			}
+		}
+	}
+
+	if mac := src.MacOS; mac != nil {
+		switch {
+		case *mac == MacOSInstall{}:
+			add(false, "bloco 'macos' vazio")
+		case strings.Contains(mac.VolumeName, "/"):
+			add(true, "macos.volume_name não pode conter '/': %q", mac.VolumeName)
+		case mac.AppBundle != "" && !strings.HasSuffix(mac.AppBundle, ".app"):
+			add(true, "macos.app_bundle deve terminar em .app: %q", mac.AppBundle)
+		case mac.PKGIdentifier != "" && !strings.Contains(mac.PKGIdentifier, "."):
+			add(false, "macos.pkg_identifier %q não parece um identificador reverse-DNS", mac.PKGIdentifier)
+		}
+	}
+
+	lintInstallHints(src, add)
+	lintUninstall(src, add)
+	lintMirrors(src, add)
+
+	if src.Sunset != "" {
+		if _, err := time.Parse(time.DateOnly, src.Sunset); err != nil {
+			add(true, "sunset inválido: %q (use AAAA-MM-DD)", src.Sunset)
+		}
+	}
+	if src.CheckInterval != "" {
+		if d, err := time.ParseDuration(src.CheckInterval); err != nil || d <= 0 {
+			add(true, "check_interval inválido: %q (use uma duração positiva, ex: 24h ou 30m)", src.CheckInterval)
+		}
+	}
+	if src.ReplacedBy == src.ID && src.ID != "" {
+		add(true, "replaced_by aponta para o próprio app")
+	}
+	for _, id := range src.DependsOn {
+		switch {
+		case id == src.ID:
+			add(true, "depends_on inclui o próprio app")
+		case slices.Contains(src.ConflictsWith, id):
+			add(true, "%s está em depends_on e em conflicts_with", id)
+		}
+	}
+	if slices.Contains(src.ConflictsWith, src.ID) {
+		add(true, "conflicts_with inclui o próprio app")
+	}
+	if (src.Sunset != "" || src.ReplacedBy != "") && !src.Deprecated {
+		add(false, "sunset/replaced_by sem 'deprecated: true'; clientes só os consideram em apps descontinuados")
+	}
+
+	if req := src.Requirements; req != nil {
+		switch {
+		case req.empty():
+			add(false, "bloco 'requirements' vazio")
+		case req.Glibc != "" && !requirementVersion.MatchString(req.Glibc):
+			add(true, "requirements.glibc inválido: %q (use a versão, ex: 2.31)", req.Glibc)
+		case req.MinOSVersion != "" && !requirementVersion.MatchString(req.MinOSVersion):
+			add(true, "requirements.min_os_version inválido: %q (use só a versão, ex: 12.0)", req.MinOSVersion)
+		}
+	}
+
+	switch {
+	case src.RolloutPercent < 0 || src.RolloutPercent > 100:
+		add(true, "rollout_percent deve estar entre 0 e 100: %d", src.RolloutPercent)
+	case src.RolloutPercent == 100:
+		add(false, "rollout_percent 100 equivale a não declarar; remova o campo ao concluir a liberação")
+	}
+
+	if expr := src.Config["body_checksum_regex"]; expr != "" {
+		if re, err := regexp.Compile(strings.ReplaceAll(expr, "{asset}", "asset")); err != nil {
+			add(true, "body_checksum_regex inválido: %v", err)
+		} else if re.NumSubexp() < 1 {
+			add(true, "body_checksum_regex precisa de um grupo de captura com o hash")
+		}
+	}
+
+	if filter, ok := src.Config["asset_filter"]; ok && src.Strategy == "github_release" {
+		switch {
+		case filter != strings.ToLower(filter):
+			// O nome do asset é comparado em minúsculas (ver checkGithub)
+			add(true, "asset_filter %q tem maiúsculas e nunca casará", filter)
+		case len(filter) < 4 || broadAssetFilters[strings.TrimPrefix(filter, ".")]:
+			add(false, "asset_filter %q é amplo demais; o primeiro asset que casar será usado", filter)
+		}
+	}
+
+	// Cada canal passa pelas mesmas regras; só aparece o que a fonte em si não tem
+	seen := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		seen[issue.Message] = true
+	}
+	for _, name := range slices.Sorted(maps.Keys(src.Channels)) {
+		if !validChannelName(name) {
+			add(true, "nome de canal inválido: %q (use minúsculas, dígitos, - e _; %q é a própria entrada)", name, stableChannel)
+			continue
+		}
+		if len(src.Channels[name].Config) == 0 && src.Channels[name].Strategy == "" {
+			add(false, "canal %s sem strategy nem config repete a checagem da própria fonte", name)
+		}
+		for _, issue := range lintSource(src.channel(name)) {
+			if !seen[issue.Message] {
+				add(issue.Error, "canal %s: %s", name, issue.Message)
+			}
+		}
+	}
+
+	// Idem para as estratégias de reserva
+	for i, fb := range src.Fallbacks {
+		if fb.Strategy == "" {
+			add(true, "fallback %d sem strategy", i+1)
+			continue
+		}
+		for _, issue := range lintSource(src.fallback(i + 1)) {
+			if !seen[issue.Message] {
+				add(issue.Error, "fallback %d (%s): %s", i+1, fb.Strategy, issue.Message)
+			}
+		}
+	}
+	return issues
+}
+
+// checkIconURL confere se o ícone responde (HEAD, ou GET quando o servidor não aceita HEAD).
+func checkIconURL(ctx context.Context, url string) error {
+	client := &http.Client{Timeout: time.Duration(cfg.HTTPTimeout)}
+	for _, method := range []string{"HEAD", "GET"} {
+		req, err := http.NewRequestWithContext(ctx, method, url, nil)
+		if err != nil {
+			return err
+		}
+		debugf("     %s %s", method, url)
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusMethodNotAllowed && method == "HEAD" {
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("status %d", resp.StatusCode)
+		}
+		return nil
+	}
+	return nil
+}
+
+// runLint é o subcomando lint: todas as regras, incluindo os ícones. Sai com 1 se houver erros.
+func runLint() {
+	issues, err := lintSourceFiles(context.Background(), cfg.Sources, true)
+	if err != nil {
+		errorf("%v", err)
+		os.Exit(1)
+	}
+
+	errCount := 0
+	for _, issue := range issues {
+		fmt.Println(issue)
+		if issue.Error {
+			errCount++
+		}
+	}
+	fmt.Printf("%d problema(s), %d erro(s)\n", len(issues), errCount)
+	if errCount > 0 {
+		os.Exit(1)
+	}
+}
+
+// lintBeforeGenerate roda as regras locais (sem rede) e só registra os problemas no log:
+// uma fonte quebrada não deve impedir a atualização das demais.
+func lintBeforeGenerate() {
+	issues, err := lintSourceFiles(context.Background(), cfg.Sources, false)
+	if err != nil {
+		return // loadSources reporta o mesmo erro logo em seguida
+	}
+	for _, issue := range issues {
+		if issue.Error {
+			errorf(" [LINT] %s", issue)
+		} else {
+			verbosef(" [LINT] %s", issue)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ==========================================
+// LOG COM NÍVEIS
+// ==========================================
+
+// Níveis de verbosidade: -quiet mostra só erros, o padrão mostra o desfecho de cada app,
+// -v acrescenta o passo a passo e -vv os detalhes de cada requisição HTTP.
+const (
+	levelQuiet = iota
+	levelInfo
+	levelVerbose
+	levelDebug
+)
+
+var logLevel = levelInfo
+
+// parseLogLevel converte o nome do nível usado na configuração/ambiente.
+func parseLogLevel(name string) (int, error) {
+	switch strings.ToLower(name) {
+	case "quiet", "error":
+		return levelQuiet, nil
+	case "", "info", "normal":
+		return levelInfo, nil
+	case "verbose":
+		return levelVerbose, nil
+	case "debug":
+		return levelDebug, nil
+	default:
+		return levelInfo, fmt.Errorf("nível de log desconhecido: %s", name)
+	}
+}
+
+// errorf é sempre exibido, mesmo em modo silencioso.
+func errorf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func infof(format string, args ...interface{}) {
+	if logLevel >= levelInfo {
+		log.Printf(format, args...)
+	}
+}
+
+func verbosef(format string, args ...interface{}) {
+	if logLevel >= levelVerbose {
+		log.Printf(format, args...)
+	}
+}
+
+func debugf(format string, args ...interface{}) {
+	if logLevel >= levelDebug {
+		log.Printf(format, args...)
+	}
+}
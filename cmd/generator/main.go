@@ -1,16 +1,23 @@
 package main
 
 import (
+	"context"
+	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"regexp"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
@@ -19,14 +26,53 @@ import (
 // ==========================================
 
 type SourceApp struct {
-	ID          string            `json:"id"`
-	Name        string            `json:"name"`
-	Description string            `json:"description"`
-	IconURL     string            `json:"icon_url"`
-	PackageName string            `json:"package_name"`
-	InstallType string            `json:"install_type"`
-	Strategy    string            `json:"strategy"` // "github_release", "direct_url_head", "direct_static"
-	Config      map[string]string `json:"config"`
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	IconURL     string        `json:"icon_url"`
+	PackageName string        `json:"package_name"`
+	InstallType string        `json:"install_type"`
+	Strategy    string        `json:"strategy"` // "github_release", "direct_url_head", "direct_static"
+	Config      ConfigMap     `json:"config"`
+	Tags        []string      `json:"tags,omitempty"`        // Agrupamento livre para filtrar execuções (--tag)
+	Deprecated  bool          `json:"deprecated,omitempty"`  // App descontinuado (ver "remove -deprecate")
+	Private     bool          `json:"private,omitempty"`     // Com encrypt.private_only, vai só para o catálogo privado cifrado (ver encrypt.go)
+	Sunset      string        `json:"sunset,omitempty"`      // Fim do suporte (AAAA-MM-DD) de um app descontinuado
+	ReplacedBy  string        `json:"replaced_by,omitempty"` // Id do app sugerido para migração
+	Aliases     []string      `json:"aliases,omitempty"`     // Ids antigos do app, após uma renomeação (ver aliases.go)
+	MacOS       *MacOSInstall `json:"macos,omitempty"`       // Dicas de instalação para clientes macOS
+
+	// Scripts, reboot e modo silencioso para clientes que orquestram a instalação (ver install_hints.go)
+	InstallHints *InstallHints `json:"install_hints,omitempty"`
+
+	// Como remover o app: pacote do apt/dnf ou desinstalador do Windows (ver install_hints.go)
+	Uninstall *UninstallInfo `json:"uninstall,omitempty"`
+
+	// Liberação gradual: de 1 a 99, só essa porcentagem dos clientes recebe a versão nova
+	// (ver rollout.go); 0 ou 100 = todos
+	RolloutPercent int `json:"rollout_percent,omitempty"`
+
+	// Canais além do estável (a própria fonte), ex: "beta" (ver channels.go)
+	Channels map[string]SourceChannel `json:"channels,omitempty"`
+
+	// Requisitos do sistema (ver requirements.go); completam os lidos do pacote
+	Requirements *Requirements `json:"requirements,omitempty"`
+
+	// Ids de apps do catálogo que precisam estar instalados antes deste (ex: o app hospedeiro de
+	// um plugin) e de apps que não podem conviver com ele (ver dependencies.go)
+	DependsOn     []string `json:"depends_on,omitempty"`
+	ConflictsWith []string `json:"conflicts_with,omitempty"`
+
+	// Verificado com sucesso há menos que isso (ex: "24h"), o app fica de fora da execução
+	// (ver interval.go)
+	CheckInterval string `json:"check_interval,omitempty"`
+
+	// Bases de URL alternativas, tentadas em ordem se a checagem ou o download na origem falhar
+	// (ver mirrors.go)
+	Mirrors []string `json:"mirrors,omitempty"`
+
+	// Estratégias de reserva, tentadas em ordem se a checagem com strategy falhar (ver fallbacks.go)
+	Fallbacks []SourceFallback `json:"fallbacks,omitempty"`
 }
 
 type CatalogApp struct {
@@ -43,257 +89,1082 @@ type CatalogApp struct {
 	DownloadURL string `json:"download_url"`
 	Checksum    string `json:"checksum"` // SHA256
 	Size        int64  `json:"size"`     // Tamanho em bytes
+
+	// O mesmo artefato nos mirrors da fonte, na ordem em que os clientes devem tentá-los
+	Mirrors []string `json:"mirrors,omitempty"`
+
+	// SHA-512 publicado pela origem (ex: latest.yml do electron-builder), em hexadecimal. Quando
+	// a origem só informa SHA-512, o download é dispensado e checksum fica vazio.
+	SHA512 string `json:"sha512,omitempty"`
+
+	// Data de publicação upstream (published_at da release, Last-Modified para URLs diretas)
+	ReleasedAt time.Time `json:"released_at,omitzero"`
+
+	// Distribuição P2P opcional (ver torrent.go); verificada pelo mesmo checksum
+	Magnet     string `json:"magnet,omitempty"`
+	TorrentURL string `json:"torrent_url,omitempty"`
+	IPFSCID    string `json:"ipfs_cid,omitempty"`
+
+	// Clientes devem avisar o usuário e não oferecer o app em instalações novas. Sunset é a
+	// data (AAAA-MM-DD) do fim do suporte e ReplacedBy, o id do app sugerido para migração.
+	Deprecated bool   `json:"deprecated,omitempty"`
+	Sunset     string `json:"sunset,omitempty"`
+	ReplacedBy string `json:"replaced_by,omitempty"`
+
+	// Entrada do catálogo privado cifrado (encrypt.private_only); nunca vai para o público
+	Private bool `json:"private,omitempty"`
+
+	// Esquema de versão declarado pela fonte (semver, debian, calver, string), para que
+	// clientes comparem e exibam a versão corretamente; ausente = semver
+	VersionScheme string `json:"version_scheme,omitempty"`
+
+	// Dicas de instalação para clientes macOS, copiadas da fonte
+	MacOS *MacOSInstall `json:"macos,omitempty"`
+
+	// Scripts antes/depois da instalação, reboot e argumentos silenciosos, copiados da fonte
+	InstallHints *InstallHints `json:"install_hints,omitempty"`
+
+	// Como remover o app (pacote do apt/dnf, desinstalador do Windows), copiado da fonte
+	Uninstall *UninstallInfo `json:"uninstall,omitempty"`
+
+	// O que a máquina precisa ter para instalar esta versão, declarado na fonte ou lido do pacote
+	Requirements *Requirements `json:"requirements,omitempty"`
+
+	// Apps do catálogo a instalar antes deste e apps incompatíveis com ele, copiados da fonte
+	DependsOn     []string `json:"depends_on,omitempty"`
+	ConflictsWith []string `json:"conflicts_with,omitempty"`
+
+	// Porcentagem dos clientes que deve receber latest_version (liberação gradual, ver
+	// rollout.go); ausente = todos
+	RolloutPercent int `json:"rollout_percent,omitempty"`
+
+	// Versões publicadas nos canais extras da fonte (ex: "beta"); a entrada em si é o canal estável
+	Channels map[string]ChannelRelease `json:"channels,omitempty"`
+
+	// A fonte do app foi removida; a entrada é mantida, sem checagens, até um generate -prune
+	Orphaned bool `json:"orphaned,omitempty"`
+
+	// Checagens que falharam em sequência e, com stale_after, a marca de entrada sem
+	// verificação bem-sucedida há tempo demais (ver stale.go); ambas zeram na próxima checagem
+	ConsecutiveFailures int  `json:"consecutive_failures,omitempty"`
+	Stale               bool `json:"stale,omitempty"`
+
+	// Com breaker_after, até quando as checagens do app ficam suspensas depois de falhas
+	// seguidas demais (ver breaker.go); some na próxima checagem bem-sucedida
+	BreakerUntil time.Time `json:"breaker_until,omitzero"`
+
+	// Controle: última checagem bem-sucedida vs. última mudança de conteúdo da entrada.
+	// Uma entrada com last_checked antigo indica que as checagens estão falhando.
+	LastChecked time.Time `json:"last_checked,omitzero"`
+	LastUpdated time.Time `json:"last_updated,omitzero"`
+}
+
+// MacOSInstall traz o que um cliente macOS precisa além da URL: onde o .dmg monta, qual
+// receipt o .pkg deixa (para saber se já está instalado) e se o artefato é notarizado.
+// Declarado na fonte; o gerador só repassa.
+type MacOSInstall struct {
+	VolumeName    string `json:"volume_name,omitempty"`    // Nome do volume montado pelo .dmg (/Volumes/<nome>)
+	AppBundle     string `json:"app_bundle,omitempty"`     // .app dentro do .dmg a copiar para /Applications
+	PKGIdentifier string `json:"pkg_identifier,omitempty"` // Identificador do receipt do .pkg (pkgutil --pkgs)
+	Notarized     *bool  `json:"notarized,omitempty"`      // Notarizado pela Apple; ausente = desconhecido
 }
 
 type Catalog struct {
 	LastUpdated time.Time             `json:"last_updated"`
+	Stats       CatalogStats          `json:"stats"`
 	Apps        map[string]CatalogApp `json:"apps"`
-}
-
-// Estrutura auxiliar para API do GitHub
-type GithubRelease struct {
-	TagName string `json:"tag_name"`
-	Assets  []struct {
-		Name               string `json:"name"`
-		BrowserDownloadURL string `json:"browser_download_url"`
-		Size               int64  `json:"size"`
-	} `json:"assets"`
+	Aliases     map[string]string     `json:"aliases,omitempty"` // Id antigo -> id atual
 }
 
 // ==========================================
 // MAIN
 // ==========================================
 
+// main despacha o subcomando: "generate" (padrão, quando só há flags) gera o catálogo;
+// "serve" sobe o modo servidor sobre o catálogo gerado (ver server.go); "probe" testa
+// uma fonte isolada (ver probe.go); "remove" retira um app das fontes e do catálogo (ver remove.go);
+// "lint" valida as fontes (ver lint.go); "promote" publica o catálogo de staging (ver promote.go);
+// "approve" aplica atualizações retidas para revisão (ver approve.go); "check" e "apply" dividem
+// a geração em plano e execução (ver plan.go); "rollback" restaura um snapshot (ver snapshot.go).
 func main() {
-	log.Println(">>> Iniciando Gerador de Catálogo...")
+	command, args := "generate", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
 
-	// 1. Carregar Configuração e Catálogo Antigo
-	sources := loadSources("apps.source.json")
-	oldCatalog := loadCatalog("catalog.json") // Se não existir, retorna vazio
+	// Flags exclusivas de cada subcomando
+	extraFlags := map[string]func(*flag.FlagSet){
+		"remove":  removeFlags,
+		"promote": promoteFlags,
+		"approve": approveFlags,
+	}
+
+	var err error
+	if cfg, args, err = loadConfig(args, extraFlags[command]); err != nil {
+		log.Fatal(err)
+	}
+	if logLevel, err = parseLogLevel(cfg.LogLevel); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(args) > 0 && command != "probe" && command != "remove" && command != "approve" && command != "rollback" {
+		log.Fatalf("Argumento inesperado: %s", args[0])
+	}
+
+	if cfg.Profile != "" {
+		selectProfile(cfg.Profile)
+	}
+
+	switch command {
+	case "generate":
+		code := forEachProfile(runGenerate)
+		runDownloads.cleanup() // Mantido entre os perfis (ver runGenerate)
+		os.Exit(code)
+	case "serve":
+		runServe()
+	case "probe":
+		runProbe(args)
+	case "remove":
+		runRemove(args)
+	case "lint":
+		runLint()
+	case "promote":
+		runPromote()
+	case "approve":
+		runApprove(args)
+	case "check":
+		os.Exit(forEachProfile(runCheck))
+	case "apply":
+		os.Exit(forEachProfile(runApply))
+	case "rollback":
+		runRollback(args)
+	default:
+		log.Fatalf("Comando desconhecido: %s (use generate, check, apply, serve, probe, remove, lint, promote, approve ou rollback)", command)
+	}
+}
+
+// runGenerate verifica todas as fontes e grava o novo catálogo; devolve o código de saída.
+func runGenerate() int {
+	// 1. Carregar Catálogo Antigo
+	infof(">>> Iniciando Gerador de Catálogo...")
+	ctx, stop := runContext()
+	defer stop()
+
+	lintBeforeGenerate()
+	sources, filtered := selectSources(profileSources(loadSources(cfg.Sources...)))
+	target, oldCatalog := workingCatalog() // Se não existir, retorna vazio
+	if len(filtered) > 0 {
+		infof(">>> Filtro ativo: %d de %d fontes selecionadas.", len(sources), len(sources)+len(filtered))
+	}
+
+	if cfg.GithubCachePath != "" {
+		loadGithubETags(cfg.GithubCachePath)
+	}
+	if cfg.GithubGraphQL {
+		prefetchGithub(ctx, sources)
+	}
+
+	// Com perfis, o memo é mantido entre eles: um app em dois catálogos é baixado uma vez
+	if runDownloads == nil {
+		runDownloads = newDownloadMemo()
+	}
+
+	// Apps renomeados: a entrada sob o id antigo passa para o novo antes da comparação
+	aliases := aliasMap(sources, filtered)
+	migrateAliases(&oldCatalog, aliases)
+
+	pending, compareCatalog := pendingOverlay(oldCatalog)
 
 	newCatalog := Catalog{
 		LastUpdated: time.Now(),
 		Apps:        make(map[string]CatalogApp),
+		Aliases:     aliases,
+	}
+
+	// Fontes fora do filtro mantêm a entrada anterior intacta
+	for _, src := range filtered {
+		if oldApp, ok := oldCatalog.Apps[src.ID]; ok {
+			newCatalog.Apps[src.ID] = oldApp
+		}
 	}
 
-	changesCount := 0
+	// 2. Processar cada App (até cfg.Concurrency em paralelo)
+	results := make([]appResult, len(sources))
+	runConcurrently(len(sources), func(i int) {
+		start := time.Now()
+		results[i] = processApp(ctx, sources[i], compareCatalog)
+		results[i].Duration = time.Since(start)
+	})
 
-	// 2. Processar cada App
-	for _, src := range sources {
-		log.Printf("------------------------------------------------")
-		log.Printf("Processando: %s (%s)", src.Name, src.Strategy)
+	if err := ctx.Err(); err != nil {
+		errorf(">>> Execução interrompida (%v); apps não concluídos mantêm a versão anterior.", context.Cause(ctx))
+	}
 
-		// Passo A: Identificar versão online e URL (sem baixar se possível)
-		onlineVer, onlineURL, onlineSize, err := checkStrategy(src)
-		if err != nil {
-			log.Printf(" [ERRO] Falha ao checar %s: %v. Mantendo versão antiga.", src.ID, err)
-			if old, ok := oldCatalog.Apps[src.ID]; ok {
-				newCatalog.Apps[src.ID] = old
-			}
-			continue
-		}
+	if cfg.GithubCachePath != "" {
+		saveGithubETags(cfg.GithubCachePath)
+	}
 
-		// Passo B: Verificar se precisa atualizar
-		// Se for "direct_static", a versão é sempre "latest" ou data, então forçamos a checagem de hash depois
-		forceCheck := src.Strategy == "direct_static"
-		
-		oldApp, exists := oldCatalog.Apps[src.ID]
-		
-		if exists && !forceCheck && oldApp.Version == onlineVer {
-			log.Printf(" [SKIP] Versão inalterada (%s). Mantendo cache.", onlineVer)
-			newCatalog.Apps[src.ID] = oldApp
-			continue
+	results = append(results, orphanResults(oldCatalog, sources, filtered)...)
+	return finishRun(target, oldCatalog, newCatalog, results, pending)
+}
+
+// runContext devolve o contexto de uma execução: o prazo global (--timeout) e Ctrl+C/SIGTERM
+// cancelam checagens e downloads em andamento, e o catálogo ainda é salvo com o que terminou a tempo.
+func runContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	if cfg.Timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.Timeout))
+	return ctx, func() { cancel(); stop() }
+}
+
+// runConcurrently chama fn(0..count-1) com até cfg.Concurrency chamadas em paralelo.
+func runConcurrently(count int, fn func(i int)) {
+	sem := make(chan struct{}, cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// finishRun consolida os resultados em newCatalog (que já traz as entradas fora da execução),
+// grava o catálogo em target e cuida do que vem depois: pendências, relatórios, publicação,
+// notificações; devolve o código de saída.
+func finishRun(target string, oldCatalog, newCatalog Catalog, results []appResult, pending *PendingChanges) int {
+	changesCount, checkedCount, mismatches := 0, 0, 0
+	var failed []string
+	for i := range results {
+		switch {
+		case pending == nil:
+		case results[i].Orphaned || results[i].Removed:
+			delete(pending.Changes, results[i].ID) // Sem fonte, não há o que aprovar
+		default:
+			results[i] = pending.hold(results[i], oldCatalog)
+		}
+		trackFailures(&results[i])
+		res := results[i]
+		if res.HasApp {
+			newCatalog.Apps[res.App.ID] = res.App
+		}
+		if res.Changed || res.ChannelChanged {
+			changesCount++
 		}
+		if res.Checked {
+			checkedCount++
+		}
+		if res.Err != nil {
+			failed = append(failed, res.ID)
+		}
+		if res.ChecksumMismatch {
+			mismatches++
+		}
+	}
 
-		// Passo C: Baixar e Calcular Hash
-		log.Printf(" [UPDATE] Nova versão detectada ou check forçado (%s -> %s). Baixando...", oldApp.Version, onlineVer)
+	// 3. Salvar (checagens bem-sucedidas e falhas também contam: last_checked e o contador de
+	// falhas precisam ser persistidos)
+	if changesCount > 0 || checkedCount > 0 || len(failed) > 0 || len(oldCatalog.Apps) == 0 {
+		newCatalog.Stats = computeStats(newCatalog.Apps)
+		saveCatalog(target, newCatalog)
+		infof(">>> Catálogo salvo com %d alterações (%d apps verificados).", changesCount, checkedCount)
+		if cfg.StagingPath != "" {
+			infof(">>> Gravado no staging %s; use 'promote' para publicar.", target)
+		}
 
-		checksum, downloadedSize, err := downloadAndHash(onlineURL)
-		if err != nil {
-			log.Printf(" [ERRO] Falha no download de %s: %v", src.ID, err)
-			// Mantém o antigo em caso de falha no download
-			if exists { newCatalog.Apps[src.ID] = oldApp }
-			continue
+		if cfg.IPFS.enabled() && cfg.IPFS.Catalog && cfg.StagingPath == "" {
+			if cid, err := publishCatalogIPFS(cfg.CatalogPath); err != nil {
+				errorf(" [ERRO] Falha ao publicar o catálogo no IPFS: %v", err)
+			} else {
+				infof(">>> Catálogo publicado no IPFS: %s", cid)
+			}
 		}
+	} else {
+		infof(">>> Nenhuma alteração necessária.")
+		if cfg.TUF.enabled() && target == cfg.CatalogPath {
+			if err := refreshTUF(); err != nil {
+				errorf(" [ERRO] Falha ao renovar os metadados TUF em %s: %v", cfg.TUF.Dir, err)
+			}
+		}
+	}
+	if pending != nil {
+		if err := savePending(cfg.PendingPath, *pending); err != nil {
+			errorf(" [ERRO] Falha ao gravar as pendências %s: %v", cfg.PendingPath, err)
+		} else if n := len(pending.Changes); n > 0 {
+			infof(">>> %d atualização(ões) aguardando aprovação em %s.", n, cfg.PendingPath)
+		}
+	}
 
-		// Para estratégia estática (Chrome), se o hash for igual, não atualizamos a data
-		if forceCheck && exists && oldApp.Checksum == checksum {
-			log.Printf(" [SKIP] Hash do arquivo estático não mudou. Mantendo.",)
-			newCatalog.Apps[src.ID] = oldApp
-			continue
+	if cfg.ReportPath != "" {
+		if err := writeReport(cfg.ReportPath, buildReport(newCatalog.LastUpdated, results)); err != nil {
+			errorf(" [ERRO] Falha ao gravar relatório %s: %v", cfg.ReportPath, err)
 		}
+	}
 
-		// Se o tamanho veio zerado da estratégia (ex: alguns servers não mandam Content-Length no HEAD),
-		// usamos o tamanho real do arquivo baixado.
-		finalSize := onlineSize
-		if finalSize == 0 {
-			finalSize = downloadedSize
+	if cfg.SummaryPath != "" {
+		if err := os.WriteFile(cfg.SummaryPath, []byte(markdownSummary(results)), 0644); err != nil {
+			errorf(" [ERRO] Falha ao gravar resumo %s: %v", cfg.SummaryPath, err)
 		}
+	}
 
-		// Monta o novo objeto
-		newApp := CatalogApp{
-			ID:          src.ID,
-			Name:        src.Name,
-			Description: src.Description,
-			IconURL:     src.IconURL,
-			PackageName: src.PackageName,
-			InstallType: src.InstallType,
-			Version:     onlineVer,
-			DownloadURL: onlineURL,
-			Checksum:    checksum,
-			Size:        finalSize,
+	if cfg.ChangelogPath != "" {
+		if err := recordChangelog(cfg.ChangelogPath, newCatalog.LastUpdated, changelogEntries(results)); err != nil {
+			errorf(" [ERRO] Falha ao atualizar o changelog %s: %v", cfg.ChangelogPath, err)
 		}
+	}
 
-		newCatalog.Apps[src.ID] = newApp
-		changesCount++
-		log.Printf(" [SUCESSO] Atualizado para versão %s (Size: %d bytes)", onlineVer, finalSize)
+	// Publicação no servidor (mesmo sem alterações: o destino pode estar defasado). Com
+	// staging, quem publica é o promote.
+	publishFailed := false
+	if cfg.Rsync.enabled() && cfg.StagingPath == "" {
+		if err := publishRsync(); err != nil {
+			errorf(" [ERRO] Falha ao publicar via rsync em %s: %v", cfg.Rsync.Target, err)
+			publishFailed = true
+		} else {
+			infof(">>> Publicado via rsync em %s", cfg.Rsync.Target)
+		}
 	}
 
-	// 3. Salvar
-	if changesCount > 0 || len(oldCatalog.Apps) == 0 {
-		saveCatalog("catalog.json", newCatalog)
-		log.Printf(">>> Catálogo salvo com %d alterações.", changesCount)
-	} else {
-		log.Println(">>> Nenhuma alteração necessária.")
+	sendNotifications(results)
+	publishMQTT(results)
+
+	// 4. Política de saída: o catálogo já foi salvo, mas a CI precisa enxergar a degradação
+	if len(failed) > 0 {
+		errorf(">>> %d fonte(s) falharam: %s", len(failed), strings.Join(failed, ", "))
+	}
+	if exceedsFailureBudget(len(failed)) {
+		errorf(">>> Orçamento de falhas excedido (%d falhas, máximo %d).", len(failed), cfg.MaxFailures)
+		return exitFailureBudget
+	}
+	if publishFailed {
+		return exitPublishFailed
+	}
+	if cfg.StrictChecksums && mismatches > 0 {
+		errorf(">>> %d download(s) divergiram do hash publicado pela origem (strict_checksums).", mismatches)
+		return exitChecksumMismatch
 	}
+	return 0
 }
 
-// ==========================================
-// ESTRATÉGIAS
-// ==========================================
+// Códigos de saída do gerador. Erros fatais (configuração, fontes inválidas) saem com 1 via log.Fatal.
+const (
+	exitFailureBudget    = 2 // Mais fontes falharam do que o permitido por --max-failures/--fail-on-error
+	exitPublishFailed    = 3 // O catálogo foi salvo, mas a publicação (rsync) falhou
+	exitChecksumMismatch = 4 // Com strict_checksums, algum download divergiu do hash publicado (o catálogo é salvo)
+)
 
-func checkStrategy(src SourceApp) (version string, url string, size int64, err error) {
-	switch src.Strategy {
-	case "github_release":
-		return checkGithub(src.Config["repo"], src.Config["asset_filter"])
-	case "direct_url_head":
-		return checkDirectHead(src.Config["url"], src.Config["regex"])
-	case "direct_static":
-		// Para links estáticos (ex: Chrome), a versão é a data de hoje
-		// O download real vai confirmar se o hash mudou
-		return time.Now().Format("2006.01.02"), src.Config["url"], 0, nil
+// exceedsFailureBudget indica se a quantidade de falhas viola a política configurada.
+// MaxFailures negativo desativa a verificação (comportamento padrão).
+func exceedsFailureBudget(failures int) bool {
+	return cfg.MaxFailures >= 0 && failures > cfg.MaxFailures
+}
+
+// appResult é o desfecho do processamento de um app.
+type appResult struct {
+	ID      string
+	App     CatalogApp // Entrada a publicar (nova ou mantida)
+	HasApp  bool       // false quando não há nada a publicar (ex: falha sem versão antiga)
+	Changed bool
+	Checked bool  // A versão online foi verificada (last_checked avançou)
+	Err     error // Falha na checagem ou no download (a versão antiga é mantida)
+	Pending bool  // Atualização retida aguardando aprovação (ver approve.go)
+
+	ChannelChanged bool // Um canal extra ganhou versão nova pelo apply (ver mergeChannelResults)
+
+	// O app não tem mais fonte (ver orphanResults): mantido como orphaned ou, com -prune, removido
+	Orphaned bool
+	Removed  bool
+
+	Stale bool // A entrada passou a ser stale nesta execução (ver trackFailures)
+
+	BreakerOpen bool // Checagem não feita: o circuit breaker do app está aberto (ver breaker.go)
+	NotDue      bool // Checagem não feita: verificado há menos de check_interval (ver interval.go)
+
+	// O download divergiu do hash publicado pela origem (ver verifyDownload)
+	ChecksumMismatch bool
+
+	// Mirror que atendeu a checagem ou o download depois de a origem falhar (ver mirrors.go)
+	Mirror string
+
+	// Estratégia de reserva que atendeu a checagem depois de a principal falhar (ver fallbacks.go)
+	Fallback string
+
+	// Detalhes para o relatório da execução
+	OldVersion      string
+	OldSize         int64
+	NewVersion      string // Versão encontrada online (vazia se a checagem falhou)
+	Downgrade       bool   // NewVersion é menor que OldVersion segundo o version_scheme
+	BytesDownloaded int64
+	Duration        time.Duration
+}
+
+// Status resume o resultado em updated/pending/skipped/failed/orphaned/removed.
+func (r appResult) Status() string {
+	switch {
+	case r.Err != nil:
+		return "failed"
+	case r.Removed:
+		return "removed"
+	case r.Orphaned:
+		return "orphaned"
+	case r.Changed:
+		return "updated"
+	case r.Pending:
+		return "pending"
 	default:
-		return "", "", 0, fmt.Errorf("estratégia desconhecida: %s", src.Strategy)
+		return "skipped"
 	}
 }
 
-// Estratégia 1: GitHub API
-func checkGithub(repo, assetFilter string) (string, string, int64, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repo)
-	req, _ := http.NewRequest("GET", url, nil)
-	
-	// Token é obrigatório no Actions para não tomar rate limit
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		req.Header.Set("Authorization", "token "+token)
+// processApp checa a fonte, baixa o artefato se necessário e devolve a entrada resultante.
+// Em caso de falha, a versão antiga (se houver) é mantida.
+func processApp(ctx context.Context, src SourceApp, oldCatalog Catalog) appResult {
+	res, online, ok := resolveApp(ctx, src, oldCatalog)
+	if ok {
+		res = applyApp(ctx, src, oldCatalog, online, res)
+	}
+	if !res.HasApp || res.BreakerOpen || res.NotDue {
+		return res
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil { return "", "", 0, err }
-	defer resp.Body.Close()
+	// Canais extras (ver channels.go): uma falha num canal conta como falha do app
+	channels, err := refreshChannels(ctx, src, res.App)
+	res.App.Channels = channels
+	if err != nil && res.Err == nil {
+		res.Err = err
+	}
+	return res
+}
 
-	if resp.StatusCode != 200 {
-		return "", "", 0, fmt.Errorf("github status: %d", resp.StatusCode)
+// resolveApp executa os passos A e B: consulta a versão online e decide se há o que aplicar.
+// ok é false quando a checagem falhou ou a versão não mudou (res já é o resultado final).
+func resolveApp(ctx context.Context, src SourceApp, oldCatalog Catalog) (res appResult, online checkResult, ok bool) {
+	verbosef("------------------------------------------------")
+	verbosef("Processando: %s (%s)", src.Name, src.Strategy)
+
+	oldApp, exists := oldCatalog.Apps[src.ID]
+	res = appResult{ID: src.ID, App: oldApp, HasApp: exists, OldVersion: oldApp.Version, OldSize: oldApp.Size}
+	res.App.Orphaned = false // A fonte pode ter voltado depois de um app ficar órfão
+
+	// Prazo da execução já esgotado: nem começa, para não gerar um erro por app
+	if err := ctx.Err(); err != nil {
+		verbosef(" [SKIP] %s: execução interrompida antes da checagem.", src.ID)
+		res.Err = err
+		return res, online, false
 	}
 
-	var rel GithubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil { return "", "", 0, err }
+	// Falhas seguidas demais ou verificação recente: a checagem espera (ver breaker.go e
+	// interval.go), a não ser que tenha sido pedida explicitamente
+	if exists && !forcedCheck(ctx, src.ID) {
+		if breakerOpen(oldApp) {
+			errorf(" [AVISO] %s: circuit breaker aberto (%d falhas seguidas); checagem suspensa até %s.",
+				src.ID, oldApp.ConsecutiveFailures, oldApp.BreakerUntil.Format(time.RFC3339))
+			res.BreakerOpen = true
+			return res, online, false
+		}
+		if next := nextCheck(src, oldApp); time.Now().Before(next) {
+			infof(" [SKIP] %s: verificado em %s; próxima checagem a partir de %s (check_interval).",
+				src.ID, oldApp.LastChecked.Format(time.RFC3339), next.Format(time.RFC3339))
+			res.NotDue = true
+			return res, online, false
+		}
+	}
 
-	version := strings.TrimPrefix(rel.TagName, "v")
-	
-	for _, asset := range rel.Assets {
-		if strings.Contains(strings.ToLower(asset.Name), assetFilter) {
-			return version, asset.BrowserDownloadURL, asset.Size, nil
+	// Passo A: Identificar versão online e URL (sem baixar se possível)
+	online, mirror, err := checkWithFallbacks(ctx, src)
+	res.Mirror = mirror
+	if err != nil {
+		errorf(" [ERRO] Falha ao checar %s: %v. Mantendo versão antiga.", src.ID, err)
+		res.Err = err
+		return res, online, false
+	}
+	if online.Fallback > 0 {
+		src = src.fallback(online.Fallback)
+		res.Fallback = src.Strategy
+	}
+	if online.Version, err = normalizeVersion(src.Config, online.Version); err != nil {
+		errorf(" [ERRO] Falha ao normalizar a versão de %s: %v. Mantendo versão antiga.", src.ID, err)
+		res.Err = err
+		return res, online, false
+	}
+	onlineVer := online.Version
+	res.NewVersion = onlineVer
+
+	// Upstream voltou atrás (release retirada, tag errada): publica, mas deixa registrado
+	scheme := versionScheme(src.Config)
+	if exists && oldApp.Version != "" && !online.Provisional && schemeOrdered(scheme) && compareVersions(scheme, onlineVer, oldApp.Version) < 0 {
+		errorf(" [AVISO] %s: versão online (%s) é menor que a publicada (%s); possível downgrade upstream.", src.ID, onlineVer, oldApp.Version)
+		res.Downgrade = true
+	}
+	runDownloads.claimURL(src.ID, online.URL)
+
+	// Passo B: Verificar se precisa atualizar
+	// Com versão provisória (data de hoje), forçamos a checagem de hash depois.
+	// Com version_source "package", a versão da estratégia também é só provisória.
+	forceCheck := online.Provisional || src.Config["version_source"] == "package"
+
+	if exists && !forceCheck && oldApp.Version == onlineVer {
+		infof(" [SKIP] %s: versão inalterada (%s). Mantendo cache.", src.ID, onlineVer)
+		res.App.LastChecked, res.Checked = time.Now().UTC(), true
+		copySourceMetadata(&res.App, src)
+		return res, online, false
+	}
+	return res, online, true
+}
+
+// copySourceMetadata copia para a entrada os campos que a fonte declara e o gerador só repassa.
+// Eles são copiados a cada checagem, mesmo sem versão nova, para que mudanças na fonte (ex:
+// aumentar rollout_percent) cheguem ao catálogo sem esperar o próximo lançamento.
+func copySourceMetadata(app *CatalogApp, src SourceApp) {
+	app.Deprecated, app.Sunset, app.ReplacedBy = src.Deprecated, src.Sunset, src.ReplacedBy
+	app.VersionScheme = src.Config["version_scheme"]
+	app.MacOS, app.InstallHints, app.Uninstall = src.MacOS, src.InstallHints, src.Uninstall
+	app.RolloutPercent = src.RolloutPercent
+	app.Requirements = src.Requirements.over(app.Requirements)
+	app.DependsOn, app.ConflictsWith = src.DependsOn, src.ConflictsWith
+	app.Mirrors = mirrorURLs(src.Mirrors, app.DownloadURL)
+	app.Private = src.Private
+}
+
+// applyApp executa os passos C e D a partir da versão resolvida: baixa e confere o artefato
+// (quando a origem não informa o hash) e monta a nova entrada.
+func applyApp(ctx context.Context, src SourceApp, oldCatalog Catalog, online checkResult, res appResult) appResult {
+	if online.Fallback > 0 {
+		src = src.fallback(online.Fallback) // Baixa com a config da estratégia que atendeu a checagem
+		res.Fallback = src.Strategy
+	}
+	oldApp, exists := oldCatalog.Apps[src.ID]
+	onlineVer := online.Version
+	scheme := versionScheme(src.Config)
+	checkedAt := time.Now().UTC()
+	fromPackage := src.Config["version_source"] == "package"
+	forceCheck := online.Provisional || fromPackage
+	var err error
+
+	// Passo C: Baixar e Calcular Hash
+	// Se a origem já publica SHA256 e tamanho (ex: plugins exec), o download é dispensado.
+	var dl downloadResult
+	if (online.Checksum != "" || online.SHA512 != "") && online.Size > 0 && !fromPackage {
+		infof(" [UPDATE] %s: nova versão detectada (%s -> %s). Usando hash informado pela origem.", src.ID, oldApp.Version, onlineVer)
+		dl = downloadResult{Checksum: strings.ToLower(online.Checksum), Size: online.Size}
+	} else {
+		infof(" [UPDATE] %s: nova versão detectada ou check forçado (%s -> %s). Baixando...", src.ID, oldApp.Version, onlineVer)
+
+		fetchURL := online.URL
+		if online.FetchURL != "" {
+			fetchURL = online.FetchURL
 		}
+		var mirror string
+		dl, mirror, err = downloadWithMirrors(ctx, src, fetchURL)
+		if mirror != "" {
+			res.Mirror = mirror
+		}
+		if err != nil {
+			errorf(" [ERRO] Falha no download de %s: %v", src.ID, err)
+			// Mantém o antigo em caso de falha no download
+			res.Err = err
+			return res
+		}
+		defer runDownloads.release(dl)
+		res.BytesDownloaded = dl.Size
+
+		// Hashes publicados à parte não vão para o catálogo, mas um download divergente não é publicado
+		if err = verifyDownload(ctx, src, &online, dl); err != nil {
+			errorf(" [ERRO] %s: %v", src.ID, err)
+			res.Err, res.ChecksumMismatch = err, true
+			return res
+		}
+	}
+
+	// Com versão provisória (ex: link estático sem metadados), se o hash for igual, não atualizamos a data
+	if forceCheck && exists && oldApp.Checksum == dl.Checksum {
+		infof(" [SKIP] %s: hash do arquivo estático não mudou. Mantendo.", src.ID)
+		res.App.LastChecked, res.Checked = checkedAt, true
+		copySourceMetadata(&res.App, src)
+		return res
+	}
+
+	// Passo D: com versão provisória, a versão gravada no próprio pacote é mais confiável
+	if forceCheck {
+		switch {
+		case dl.PackageVersion != "":
+			pkgVer, err := normalizeVersion(src.Config, dl.PackageVersion)
+			if err != nil {
+				errorf(" [ERRO] Falha ao normalizar a versão do pacote de %s: %v. Mantendo versão antiga.", src.ID, err)
+				res.Err = err
+				return res
+			}
+			verbosef("     %s: versão lida do pacote: %s (estratégia: %s)", src.ID, pkgVer, onlineVer)
+			onlineVer = pkgVer
+			res.NewVersion = onlineVer
+			if exists && oldApp.Version != "" && schemeOrdered(scheme) && compareVersions(scheme, onlineVer, oldApp.Version) < 0 {
+				errorf(" [AVISO] %s: versão do pacote (%s) é menor que a publicada (%s); possível downgrade upstream.", src.ID, onlineVer, oldApp.Version)
+				res.Downgrade = true
+			}
+		case fromPackage:
+			err := dl.PackageVersionErr
+			if err == nil {
+				err = fmt.Errorf("formato do artefato não reconhecido")
+			}
+			errorf(" [ERRO] Falha ao ler a versão do pacote de %s: %v. Mantendo versão antiga.", src.ID, err)
+			res.Err = err
+			return res
+		case dl.PackageVersionErr != nil:
+			debugf("     %s: versão do pacote indisponível: %v", src.ID, dl.PackageVersionErr)
+		}
+	}
+
+	// Se o tamanho veio zerado da estratégia (ex: alguns servers não mandam Content-Length no HEAD),
+	// usamos o tamanho real do arquivo baixado. Quando houve download, ele prevalece sobre o
+	// informado (uma divergência já foi tratada em verifyDownload).
+	finalSize := online.Size
+	if finalSize == 0 || res.BytesDownloaded > 0 {
+		finalSize = dl.Size
 	}
 
-	return "", "", 0, fmt.Errorf("asset '%s' não encontrado na release", assetFilter)
+	// Sem data informada pela estratégia, o Last-Modified do download é a melhor aproximação
+	releasedAt := online.ReleasedAt
+	if releasedAt.IsZero() {
+		releasedAt = dl.LastModified
+	}
+
+	// Monta o novo objeto
+	newApp := CatalogApp{
+		ID:          src.ID,
+		Name:        src.Name,
+		Description: src.Description,
+		IconURL:     src.IconURL,
+		PackageName: src.PackageName,
+		InstallType: src.InstallType,
+		Version:     onlineVer,
+		DownloadURL: online.URL,
+		Checksum:    dl.Checksum,
+		SHA512:      online.SHA512,
+		Size:        finalSize,
+		ReleasedAt:  releasedAt,
+		LastChecked: checkedAt,
+		LastUpdated: checkedAt,
+		IPFSCID:     dl.IPFSCID,
+		Channels:    oldApp.Channels, // Atualizados à parte (ver processApp)
+
+		Requirements: dl.Requirements, // Os declarados na fonte entram por cima (copySourceMetadata)
+	}
+	copySourceMetadata(&newApp, src)
+
+	// Torrent só faz sentido com o arquivo inteiro passando pelo stream (tamanho conferido)
+	if dl.Pieces != nil && dl.Size == finalSize && finalSize >= cfg.Torrent.MinSize {
+		magnet, torrentURL, err := writeTorrent(newApp, dl.Pieces)
+		if err != nil {
+			errorf(" [ERRO] Falha ao gerar torrent de %s: %v", src.ID, err)
+		} else {
+			newApp.Magnet, newApp.TorrentURL = magnet, torrentURL
+		}
+	}
+
+	if err := retainArtifact(newApp, dl); err != nil {
+		errorf(" [ERRO] Falha ao guardar o artefato de %s: %v", src.ID, err)
+	}
+
+	infof(" [SUCESSO] %s: atualizado para versão %s (Size: %d bytes)", src.ID, onlineVer, finalSize)
+	res.App, res.HasApp, res.Changed, res.Checked = newApp, true, true, true
+	return res
+}
+
+// ==========================================
+// ESTRATÉGIAS
+// ==========================================
+
+// checkResult é o que uma estratégia descobre sobre a versão publicada, sem baixar o artefato.
+type checkResult struct {
+	Version    string    `json:"version"`
+	URL        string    `json:"url"`
+	Size       int64     `json:"size,omitempty"`       // 0 quando a origem não informa
+	Checksum   string    `json:"checksum,omitempty"`   // SHA256 publicado pela origem (vazio = calcular baixando)
+	SHA512     string    `json:"sha512,omitempty"`     // SHA-512 publicado pela origem, em hexadecimal; com Size, também dispensa o download
+	SHA1       string    `json:"sha1,omitempty"`       // SHA-1 publicado pela origem (ex: Squirrel); não dispensa o download, só é conferido
+	FetchURL   string    `json:"fetch_url,omitempty"`  // De onde baixar para o hash, quando difere da URL publicada
+	ReleasedAt time.Time `json:"released_at,omitzero"` // Data de publicação upstream (zero quando desconhecida)
+	Matches    []string  `json:"matches,omitempty"`    // Grupos do regex que extraiu a versão (diagnóstico para o probe)
+
+	// SHA-256 publicado à parte do asset (ex: texto da release); como o SHA1, só é conferido
+	ExpectedSHA256 string `json:"expected_sha256,omitempty"`
+	ExpectedFrom   string `json:"expected_from,omitempty"` // De onde veio ExpectedSHA256, para as mensagens
+	ChecksumURL    string `json:"checksum_url,omitempty"`  // Arquivo .sha256 irmão do asset, lido só quando há download (ver loadSiblingChecksum)
+
+	// Posição em fallbacks da estratégia que atendeu a checagem (0 = a própria fonte)
+	Fallback int `json:"fallback,omitempty"`
+
+	// Versão provisória (data de hoje): a origem não informa versão, então só o hash do
+	// download decide se houve mudança (ver checkDirectStatic)
+	Provisional bool `json:"provisional,omitempty"`
+}
+
+func checkStrategy(ctx context.Context, src SourceApp) (checkResult, error) {
+	if cfg.RespectRobots && robotsStrategies[src.Strategy] {
+		if err := robotsAllow(ctx, src.Config, src.Config["url"]); err != nil {
+			return checkResult{}, err
+		}
+	}
+	switch src.Strategy {
+	case "github_release":
+		return checkGithub(ctx, src.Config)
+	case "github_tag":
+		return checkGithubTag(ctx, src.Config)
+	case "exec":
+		return checkExec(ctx, src)
+	case "script":
+		return checkScript(ctx, src)
+	case "wasm":
+		return checkWasm(ctx, src)
+	case "browser":
+		return checkBrowser(ctx, src)
+	case "ftp":
+		return checkFTP(ctx, src)
+	case "sftp":
+		return checkSFTP(ctx, src)
+	case "nexus":
+		return checkNexus(ctx, src)
+	case "artifactory":
+		return checkArtifactory(ctx, src)
+	case "maven":
+		return checkMaven(ctx, src)
+	case "goproxy":
+		return checkGoProxy(ctx, src)
+	case "crates":
+		return checkCrates(ctx, src)
+	case "rubygems":
+		return checkRubyGems(ctx, src)
+	case "vsx":
+		return checkVSX(ctx, src)
+	case "jetbrains":
+		return checkJetBrains(ctx, src)
+	case "electron":
+		return checkElectron(ctx, src)
+	case "squirrel":
+		return checkSquirrel(ctx, src)
+	case "appinstaller":
+		return checkAppInstaller(ctx, src)
+	case "direct_url_head":
+		return checkDirectHead(ctx, src.Config)
+	case "direct_static":
+		return checkDirectStatic(ctx, src)
+	default:
+		return checkResult{}, fmt.Errorf("estratégia desconhecida: %s", src.Strategy)
+	}
 }
 
 // Estratégia 2: HEAD Request com Redirect + Regex
-func checkDirectHead(startURL, versionRegex string) (string, string, int64, error) {
-	client := &http.Client{Timeout: 10 * time.Second}
+func checkDirectHead(ctx context.Context, conf map[string]string) (checkResult, error) {
+	startURL, versionRegex := conf["url"], conf["regex"]
+	client, err := headClient(conf)
+	if err != nil { return checkResult{}, err }
 
 	// HEAD segue redirects por padrão no Go
-	req, _ := http.NewRequest("HEAD", startURL, nil)
+	req, _ := http.NewRequestWithContext(ctx, "HEAD", startURL, nil)
+	debugf("     HEAD %s", startURL)
 	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36")
+	if err := applySourceAuth(req, conf); err != nil { return checkResult{}, err }
 
 	resp, err := client.Do(req)
-	if err != nil { return "", "", 0, err }
+	if err != nil { return checkResult{}, err }
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return "", "", 0, fmt.Errorf("status invalido: %d", resp.StatusCode)
+		return checkResult{}, fmt.Errorf("status invalido: %d", resp.StatusCode)
 	}
 
 	finalURL := resp.Request.URL.String()
+	verbosef("     URL final: %s", finalURL)
 	size := resp.ContentLength // Tenta pegar o tamanho do header
 
-	// Extrai versão da URL final
+	// Extrai versão da URL final; CDNs que servem tudo por um endpoint genérico costumam
+	// trazer a versão só no nome do anexo (Content-Disposition)
 	re := regexp.MustCompile(versionRegex)
 	matches := re.FindStringSubmatch(finalURL)
 
 	if len(matches) < 2 {
-		return "", "", 0, fmt.Errorf("regex falhou na url: %s", finalURL)
+		filename := attachmentFilename(resp.Header)
+		if filename == "" {
+			return checkResult{}, fmt.Errorf("regex falhou na url: %s", finalURL)
+		}
+		debugf("     Content-Disposition: %s", filename)
+		if matches = re.FindStringSubmatch(filename); len(matches) < 2 {
+			return checkResult{}, fmt.Errorf("regex falhou na url (%s) e no Content-Disposition (%s)", finalURL, filename)
+		}
 	}
 
-	return matches[1], finalURL, size, nil
+	return checkResult{
+		Version:    matches[1],
+		URL:        finalURL,
+		Matches:    matches,
+		Size:       size,
+		ReleasedAt: lastModified(resp.Header),
+	}, nil
 }
 
 // ==========================================
 // UTILITÁRIOS (IO/HASH)
 // ==========================================
 
-// downloadAndHash baixa o arquivo para calcular SHA256 e tamanho real
-func downloadAndHash(url string) (string, int64, error) {
-	resp, err := http.Get(url)
-	if err != nil { return "", 0, err }
-	defer resp.Body.Close()
+// downloadResult descreve o artefato efetivamente baixado.
+type downloadResult struct {
+	Checksum     string // SHA256
+	SHA1         string // Conferido contra o SHA-1 publicado por origens como o Squirrel
+	Size         int64
+	LastModified time.Time // Header Last-Modified (zero se ausente)
+	Pieces       *torrentPieces // Pedaços do torrent, quando a geração de torrents está ativa
+	IPFSCID      string         // CID do artefato publicado no IPFS, quando ativo
 
-	if resp.StatusCode != 200 {
-		return "", 0, fmt.Errorf("http status %d", resp.StatusCode)
-	}
+	// Versão embutida no próprio artefato (deb, rpm, PE, Info.plist); vazia se não reconhecida
+	PackageVersion    string
+	PackageVersionErr error
+
+	// Requisitos lidos do pacote (ver requirements.go); nil se o formato não os traz
+	Requirements *Requirements
+
+	// Cópia do artefato em disco (ver artifacts.go); quem a apaga é o downloadMemo ou, sem
+	// memo, quem baixou (ver downloadMemo.release)
+	Path string
+}
+
+// downloadAndHash baixa o arquivo para calcular SHA256 e tamanho real; o stream é gravado
+// ao mesmo tempo num arquivo temporário, para não baixar de novo o que precisar dos bytes
+func downloadAndHash(ctx context.Context, src SourceApp, url string) (downloadResult, error) {
+	body, modified, err := openArtifact(ctx, src, url)
+	if err != nil { return downloadResult{}, err }
+	defer body.Close()
+
+	file, err := spoolFile()
+	if err != nil { return downloadResult{}, fmt.Errorf("falha ao criar o arquivo temporário: %w", err) }
+	keep := false
+	defer func() {
+		if !keep {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
 
 	// Criamos um hasher
 	hasher := sha256.New()
+	sha1Hasher := sha1.New()
+	meta := newPkgMetaSniffer()
+	var sink io.Writer = io.MultiWriter(file, hasher, sha1Hasher, meta)
+
+	// Com torrents ativos, os pedaços são calculados no mesmo stream
+	var pieces *torrentPieces
+	if cfg.Torrent.enabled() {
+		pieces = newTorrentPieces(cfg.Torrent.pieceLength())
+		sink = io.MultiWriter(sink, pieces)
+	}
+
+	// Com IPFS ativo, o mesmo stream é enviado ao nó
+	var upload *ipfsUpload
+	if cfg.IPFS.enabled() {
+		upload = startIPFSUpload(src.ID)
+		sink = io.MultiWriter(sink, upload)
+	}
 	
 	// Copiamos o stream do download para o hasher
 	// O io.Copy retorna o número de bytes copiados (tamanho do arquivo)
-	size, err := io.Copy(hasher, resp.Body)
-	if err != nil { return "", 0, err }
+	size, err := io.Copy(sink, body)
+
+	var cid string
+	if upload != nil {
+		var ipfsErr error
+		if cid, ipfsErr = upload.wait(err == nil); ipfsErr != nil && err == nil {
+			errorf(" [ERRO] Falha ao publicar %s no IPFS: %v", src.ID, ipfsErr)
+		}
+	}
+	if err != nil { return downloadResult{}, err }
+	if err := file.Close(); err != nil { return downloadResult{}, err }
+	keep = true
+
+	if pieces != nil {
+		pieces.finish()
+	}
+
+	pkgVersion, pkgErr := meta.version()
+	return downloadResult{
+		Checksum:     hex.EncodeToString(hasher.Sum(nil)),
+		SHA1:         hex.EncodeToString(sha1Hasher.Sum(nil)),
+		Size:         size,
+		LastModified: modified,
+		Pieces:       pieces,
+		IPFSCID:      cid,
+
+		PackageVersion:    pkgVersion,
+		PackageVersionErr: pkgErr,
+		Requirements:      meta.requirements(),
+
+		Path: file.Name(),
+	}, nil
+}
+
+// openArtifact abre o stream do artefato conforme o esquema da URL (HTTP(S), FTP/FTPS ou SFTP),
+// devolvendo também a data de modificação informada pelo servidor (zero se ausente).
+func openArtifact(ctx context.Context, src SourceApp, url string) (io.ReadCloser, time.Time, error) {
+	if isFTPURL(url) {
+		return openFTP(ctx, src, url)
+	}
+	if strings.HasPrefix(url, "sftp://") {
+		return openSFTP(ctx, src, url)
+	}
+
+	transport, err := sourceTransport(src.Config)
+	if err != nil { return nil, time.Time{}, err }
+	client := &http.Client{Timeout: time.Duration(cfg.DownloadTimeout), Transport: transport}
+	debugf("     GET %s", url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil { return nil, time.Time{}, err }
+	if err := applySourceAuth(req, src.Config); err != nil { return nil, time.Time{}, err }
+	// Assets de repos privados: o GitHub redireciona para uma URL assinada (o token não a acompanha)
+	if isGithubAssetAPI(url) {
+		req.Header.Set("Accept", "application/octet-stream")
+		if token := os.Getenv(cfg.GithubTokenEnv); token != "" {
+			req.Header.Set("Authorization", "token "+token)
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil { return nil, time.Time{}, err }
+
+	if resp.StatusCode != 200 {
+		resp.Body.Close()
+		return nil, time.Time{}, fmt.Errorf("http status %d", resp.StatusCode)
+	}
+	body, err := guardHTML(resp, src.Config)
+	if err != nil { return nil, time.Time{}, err }
+	return body, lastModified(resp.Header), nil
+}
+
+// lastModified interpreta o header Last-Modified; devolve zero se ausente ou inválido.
+func lastModified(h http.Header) time.Time {
+	t, err := http.ParseTime(h.Get("Last-Modified"))
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
 
-	checksum := hex.EncodeToString(hasher.Sum(nil))
-	return checksum, size, nil
+// loadCatalog lê o catálogo anterior de um arquivo local ou backend remoto (ver openStore).
+// Se ele não existir, devolve um catálogo vazio; qualquer outro erro aborta, para que uma
+// falha de leitura não seja confundida com um catálogo novo.
+func loadCatalog(location string) Catalog {
+	catalog, err := readCatalog(location)
+	if err != nil { log.Fatalf("Falha ao ler o catálogo %s: %v", location, err) }
+	return catalog
 }
 
-func loadSources(path string) []SourceApp {
-	file, err := os.ReadFile(path)
-	if err != nil { log.Fatal(err) }
-	var sources []SourceApp
-	json.Unmarshal(file, &sources)
-	return sources
+// readCatalog é a versão de loadCatalog que devolve o erro (usada pelo modo servidor).
+func readCatalog(location string) (Catalog, error) {
+	if location == cfg.CatalogPath && cfg.Encrypt.enabled() {
+		return readEncryptedCatalog(location)
+	}
+	file, err := readBlob(location)
+	if errors.Is(err, os.ErrNotExist) { return Catalog{Apps: make(map[string]CatalogApp)}, nil }
+	if err != nil { return Catalog{}, err }
+	return parseCatalog(file), nil
 }
 
-func loadCatalog(path string) Catalog {
-	file, err := os.ReadFile(path)
-	if err != nil { return Catalog{Apps: make(map[string]CatalogApp)} }
+// parseCatalog interpreta o JSON do catálogo (também o decifrado por readEncryptedCatalog).
+func parseCatalog(file []byte) Catalog {
 	var catalog Catalog
 	json.Unmarshal(file, &catalog.Apps) // Note: ajustado para struct simplificada ou map direto
 	// Se o JSON salvar direto o map "apps", ajuste aqui. 
 	// Para compatibilidade com o formato proposto anteriormente:
 	var temp struct {
-		Apps map[string]CatalogApp `json:"apps"`
+		LastUpdated time.Time             `json:"last_updated"`
+		Stats       CatalogStats          `json:"stats"`
+		Apps        map[string]CatalogApp `json:"apps"`
+		Aliases     map[string]string     `json:"aliases"`
 	}
 	if json.Unmarshal(file, &temp) == nil && temp.Apps != nil {
-		return Catalog{Apps: temp.Apps}
+		return Catalog{LastUpdated: temp.LastUpdated, Stats: temp.Stats, Apps: temp.Apps, Aliases: temp.Aliases}
 	}
 	// Fallback se o arquivo for apenas o map direto
 	json.Unmarshal(file, &catalog.Apps)
+	if catalog.Apps == nil { catalog.Apps = make(map[string]CatalogApp) }
 	return catalog
 }
 
-func saveCatalog(path string, catalog Catalog) {
+func saveCatalog(location string, catalog Catalog) {
+	if err := writeCatalog(location, catalog); err != nil {
+		log.Fatalf("Falha ao gravar o catálogo %s: %v", location, err)
+	}
+}
+
+// writeCatalog é a versão de saveCatalog que devolve o erro (usada pelo modo servidor).
+func writeCatalog(location string, catalog Catalog) error {
 	// Salvamos o objeto completo com timestamp
 	data, _ := json.MarshalIndent(catalog, "", "  ")
-	os.WriteFile(path, data, 0644)
-}
\ No newline at end of file
+
+	// Com encrypt, o que é publicado (assinado e listado no TUF) é o catálogo cifrado ou o
+	// público sem os apps privados; os snapshots, locais, guardam o catálogo completo
+	published, out, contentType := catalog, data, "application/json"
+	var private []byte
+	if location == cfg.CatalogPath && cfg.Encrypt.enabled() {
+		var err error
+		if published, out, private, err = encryptCatalog(catalog, data); err != nil {
+			return fmt.Errorf("criptografia: %w", err)
+		}
+		if !cfg.Encrypt.PrivateOnly {
+			contentType = "application/octet-stream"
+		}
+	}
+
+	// A assinatura é calculada antes, para que uma chave inválida não deixe um catálogo novo
+	// ao lado da assinatura antiga
+	var sigs, privateSigs []signedFile
+	if location == cfg.CatalogPath {
+		var err error
+		if sigs, err = catalogSignatureFiles(out); err != nil {
+			return fmt.Errorf("assinatura: %w", err)
+		}
+		if private != nil {
+			if privateSigs, err = catalogSignatureFiles(private); err != nil {
+				return fmt.Errorf("assinatura: %w", err)
+			}
+		}
+	}
+	var tuf []tufFile
+	if location == cfg.CatalogPath && cfg.TUF.enabled() {
+		var err error
+		if tuf, err = tufMetadata(out, published); err != nil {
+			return fmt.Errorf("TUF: %w", err)
+		}
+	}
+	if err := writeBlob(location, out, contentType); err != nil {
+		return err
+	}
+	for _, sig := range sigs {
+		if err := writeBlob(location+sig.suffix, sig.data, sig.contentType); err != nil {
+			return err
+		}
+	}
+	if private != nil {
+		if err := writeBlob(cfg.Encrypt.privatePath(), private, "application/octet-stream"); err != nil {
+			return err
+		}
+		for _, sig := range privateSigs {
+			if err := writeBlob(cfg.Encrypt.privatePath()+sig.suffix, sig.data, sig.contentType); err != nil {
+				return err
+			}
+		}
+	}
+	if err := writeTUF(tuf); err != nil {
+		return err
+	}
+
+	// Só a produção é arquivada; falhar aqui não desfaz a gravação
+	if location == cfg.CatalogPath && cfg.Snapshots.enabled() {
+		if err := snapshotCatalog(data); err != nil {
+			errorf(" [ERRO] Falha ao gravar o snapshot do catálogo em %s: %v", cfg.Snapshots.Dir, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ==========================================
+// MIRRORS (FAILOVER DE CHECAGEM E DOWNLOAD)
+// ==========================================
+
+// Uma fonte pode listar mirrors: bases de URL alternativas, tentadas em ordem quando a origem
+// principal falha. O mirror troca o esquema e o host da URL (e prefixa o caminho, se tiver um):
+// com o mirror "https://mirror.example.org/pub", https://dl.example.com/foo/1.2/foo.deb vira
+// https://mirror.example.org/pub/foo/1.2/foo.deb. Na checagem, o mirror vale para a config
+// url; no download, para a URL do artefato.
+
+// mirrorURL aplica mirror a original (ok é false se alguma das duas não é uma URL absoluta).
+func mirrorURL(mirror, original string) (string, bool) {
+	m, err := url.Parse(mirror)
+	if err != nil || m.Scheme == "" || m.Host == "" {
+		return "", false
+	}
+	u, err := url.Parse(original)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", false
+	}
+	m.Path = strings.TrimSuffix(m.Path, "/") + u.Path
+	m.RawPath = ""
+	m.RawQuery = u.RawQuery
+	return m.String(), true
+}
+
+// mirrorURLs devolve, na ordem dos mirrors, as URLs alternativas de downloadURL publicadas na
+// entrada, para que os clientes também possam recorrer a elas.
+func mirrorURLs(mirrors []string, downloadURL string) []string {
+	var urls []string
+	for _, mirror := range mirrors {
+		if alt, ok := mirrorURL(mirror, downloadURL); ok && alt != downloadURL && !slices.Contains(urls, alt) {
+			urls = append(urls, alt)
+		}
+	}
+	return urls
+}
+
+// checkWithMirrors roda a checagem na origem e, se falhar, com a config url de cada mirror.
+// Devolve o mirror usado (vazio = origem) e, se todos falharem, o erro da origem.
+func checkWithMirrors(ctx context.Context, src SourceApp) (checkResult, string, error) {
+	online, err := checkStrategy(ctx, src)
+	if err == nil || ctx.Err() != nil || src.Config["url"] == "" {
+		return online, "", err
+	}
+	for _, mirror := range src.Mirrors {
+		alt, ok := mirrorURL(mirror, src.Config["url"])
+		if !ok {
+			continue
+		}
+		errorf(" [AVISO] %s: checagem na origem falhou (%v); tentando o mirror %s", src.ID, err, mirror)
+		derived := src
+		derived.Config = maps.Clone(src.Config)
+		derived.Config["url"] = alt
+		// As credenciais continuam restritas aos hosts da origem (ver authHosts)
+		if derived.Config["auth_hosts"] == "" {
+			derived.Config["auth_hosts"] = strings.Join(authHosts(src.Config), ",")
+		}
+		mirrored, mErr := checkStrategy(ctx, derived)
+		if mErr == nil {
+			return mirrored, mirror, nil
+		}
+		if ctx.Err() != nil {
+			return checkResult{}, "", mErr
+		}
+		verbosef("     %s: mirror %s também falhou: %v", src.ID, mirror, mErr)
+	}
+	return online, "", err
+}
+
+// downloadWithMirrors baixa fetchURL e, se falhar, a mesma URL em cada mirror. Devolve o
+// mirror usado (vazio = origem) e, se todos falharem, o erro da origem.
+func downloadWithMirrors(ctx context.Context, src SourceApp, fetchURL string) (downloadResult, string, error) {
+	dl, err := runDownloads.download(ctx, src, fetchURL)
+	if err == nil || ctx.Err() != nil {
+		return dl, "", err
+	}
+	for _, mirror := range src.Mirrors {
+		alt, ok := mirrorURL(mirror, fetchURL)
+		if !ok {
+			continue
+		}
+		errorf(" [AVISO] %s: download na origem falhou (%v); tentando o mirror %s", src.ID, err, mirror)
+		mirrored, mErr := runDownloads.download(ctx, src, alt)
+		if mErr == nil {
+			return mirrored, mirror, nil
+		}
+		if ctx.Err() != nil {
+			return downloadResult{}, "", mErr
+		}
+		verbosef("     %s: mirror %s também falhou: %v", src.ID, mirror, mErr)
+	}
+	return dl, "", err
+}
+
+// lintMirrors confere a lista de mirrors da fonte.
+func lintMirrors(src SourceApp, add func(isError bool, format string, args ...interface{})) {
+	seen := make(map[string]bool)
+	for _, mirror := range src.Mirrors {
+		u, err := url.Parse(mirror)
+		switch {
+		case err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && !isFTPURL(mirror)):
+			add(true, "mirror inválido: %q (use a base da URL, ex: https://mirror.example.org/pub)", mirror)
+		case u.RawQuery != "" || u.Fragment != "":
+			add(true, "mirror %s não pode ter query nem fragmento", mirror)
+		case seen[mirror]:
+			add(false, "mirror repetido: %s", mirror)
+		case u.Scheme == "http":
+			add(false, "mirror não é HTTPS: %s", mirror)
+		}
+		seen[mirror] = true
+	}
+}
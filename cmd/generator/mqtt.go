@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// ==========================================
+// EVENTOS MQTT
+// ==========================================
+
+// MQTTConfig configura a publicação de um evento por app atualizado, para automações
+// de home-lab (Home Assistant, Node-RED). Cada evento vai para "<topic>/<id do app>".
+type MQTTConfig struct {
+	Broker   string `json:"broker"` // ex: tcp://mqtt.local:1883, ssl://...:8883
+	Topic    string `json:"topic"`  // Prefixo dos tópicos (padrão: updater-registry/apps)
+	QoS      byte   `json:"qos"`
+	Retain   bool   `json:"retain"`
+	ClientID string `json:"client_id"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// UpdateEvent é o payload publicado para cada app atualizado.
+type UpdateEvent struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	OldVersion  string    `json:"old_version,omitempty"`
+	NewVersion  string    `json:"new_version"`
+	DownloadURL string    `json:"download_url"`
+	Checksum    string    `json:"checksum"`
+	Size        int64     `json:"size"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// publishMQTT publica os eventos de atualização. Assim como as demais notificações,
+// falhas são apenas registradas no log.
+func publishMQTT(results []appResult) {
+	c := cfg.Notify.MQTT
+	if c.Broker == "" {
+		return
+	}
+
+	var events []UpdateEvent
+	for _, res := range results {
+		if res.Status() != "updated" {
+			continue
+		}
+		events = append(events, UpdateEvent{
+			ID:          res.App.ID,
+			Name:        res.App.Name,
+			OldVersion:  res.OldVersion,
+			NewVersion:  res.App.Version,
+			DownloadURL: res.App.DownloadURL,
+			Checksum:    res.App.Checksum,
+			Size:        res.App.Size,
+			UpdatedAt:   time.Now().UTC(),
+		})
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	if err := c.publish(events); err != nil {
+		errorf(" [ERRO] Falha ao publicar eventos MQTT: %v", err)
+	}
+}
+
+func (c MQTTConfig) publish(events []UpdateEvent) error {
+	if c.QoS > 2 {
+		return fmt.Errorf("qos inválido: %d", c.QoS)
+	}
+	topic := c.Topic
+	if topic == "" {
+		topic = "updater-registry/apps"
+	}
+	clientID := c.ClientID
+	if clientID == "" {
+		clientID = fmt.Sprintf("updater-registry-%d", time.Now().UnixNano())
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(c.Broker).
+		SetClientID(clientID).
+		SetUsername(c.Username).
+		SetPassword(c.Password).
+		SetConnectTimeout(10 * time.Second)
+
+	client := mqtt.NewClient(opts)
+	token := client.Connect()
+	if !token.WaitTimeout(10 * time.Second) {
+		return fmt.Errorf("timeout conectando a %s", c.Broker)
+	}
+	if err := token.Error(); err != nil {
+		return fmt.Errorf("conexão com %s: %w", c.Broker, err)
+	}
+	defer client.Disconnect(250)
+
+	for _, ev := range events {
+		payload, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		token := client.Publish(topic+"/"+ev.ID, c.QoS, c.Retain, payload)
+		if !token.WaitTimeout(10 * time.Second) {
+			return fmt.Errorf("timeout publicando %s", ev.ID)
+		}
+		if err := token.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// NOTIFICAÇÕES
+// ==========================================
+
+// NotifyConfig define os destinos das notificações. Webhooks e tokens são segredos:
+// prefira informá-los pelas variáveis de ambiente UPDATER_* em vez do arquivo de configuração.
+type NotifyConfig struct {
+	SlackWebhook   string `json:"slack_webhook"`
+	DiscordWebhook string `json:"discord_webhook"`
+	TelegramToken  string `json:"telegram_token"`
+	TelegramChatID string `json:"telegram_chat_id"`
+	OnFailure      bool   `json:"on_failure"` // Notifica também quando há fontes com falha
+
+	// Com N > 0, uma falha só é notificada (em todos os destinos) na execução em que a fonte
+	// chega a N falhas seguidas, e não a cada soluço pontual do upstream
+	FailureThreshold int `json:"failure_threshold"`
+
+	SMTP SMTPConfig `json:"smtp"`
+	MQTT MQTTConfig `json:"mqtt"`
+}
+
+// SMTPConfig configura o envio do resumo por e-mail, para instalações sem chat.
+type SMTPConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"` // 587 (STARTTLS) por padrão; 465 usa TLS implícito
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// notifier é um destino de notificação que recebe o texto já formatado.
+// failures indica se o destino quer receber as falhas mesmo sem notify.on_failure.
+type notifier struct {
+	name     string
+	failures bool
+	send     func(text string) error
+}
+
+// notifiers devolve os destinos configurados.
+func (n NotifyConfig) notifiers() []notifier {
+	var out []notifier
+	if n.SlackWebhook != "" {
+		out = append(out, notifier{"slack", n.OnFailure, func(text string) error {
+			return postJSON(n.SlackWebhook, map[string]string{"text": text})
+		}})
+	}
+	if n.DiscordWebhook != "" {
+		out = append(out, notifier{"discord", n.OnFailure, func(text string) error {
+			return postJSON(n.DiscordWebhook, map[string]string{"content": truncate(text, 2000)})
+		}})
+	}
+	if n.TelegramToken != "" && n.TelegramChatID != "" {
+		out = append(out, notifier{"telegram", n.OnFailure, func(text string) error {
+			endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", n.TelegramToken)
+			return postJSON(endpoint, map[string]string{"chat_id": n.TelegramChatID, "text": truncate(text, 4096)})
+		}})
+	}
+	if n.SMTP.Host != "" && len(n.SMTP.To) > 0 {
+		// O e-mail é o resumo da execução: sempre inclui as falhas
+		out = append(out, notifier{"smtp", true, n.SMTP.send})
+	}
+	return out
+}
+
+// sendNotifications envia o resumo da execução para todos os destinos configurados.
+// Falhas no envio são apenas registradas: não devem derrubar a geração do catálogo.
+func sendNotifications(results []appResult) {
+	targets := cfg.Notify.notifiers()
+	if len(targets) == 0 {
+		return
+	}
+
+	for _, target := range targets {
+		text := notificationText(results, target.failures || cfg.Notify.FailureThreshold > 0)
+		if text == "" {
+			continue
+		}
+		if err := target.send(text); err != nil {
+			errorf(" [ERRO] Falha ao notificar via %s: %v", target.name, err)
+		}
+	}
+}
+
+// notificationText monta a mensagem em texto simples; vazia quando não há nada a avisar.
+func notificationText(results []appResult, includeFailures bool) string {
+	var updates, pending, removals, stale, failures []string
+	for _, res := range results {
+		if res.Stale {
+			stale = append(stale, fmt.Sprintf("• %s %s (%d falhas seguidas)", res.ID, res.App.Version, res.App.ConsecutiveFailures))
+		}
+		switch res.Status() {
+		case "updated":
+			from := res.OldVersion
+			if from == "" {
+				from = "novo"
+			}
+			updates = append(updates, fmt.Sprintf("• %s %s → %s (%s)", res.ID, from, res.App.Version, formatSizeDelta(res.App.Size-res.OldSize)))
+		case "pending":
+			from := res.OldVersion
+			if from == "" {
+				from = "novo"
+			}
+			pending = append(pending, fmt.Sprintf("• %s %s → %s", res.ID, from, res.NewVersion))
+		case "removed":
+			removals = append(removals, fmt.Sprintf("• %s %s (removido)", res.ID, res.OldVersion))
+		case "orphaned":
+			// Só na execução em que a fonte sumiu, para não repetir o aviso
+			if res.Changed {
+				removals = append(removals, fmt.Sprintf("• %s %s (mantido como orphaned)", res.ID, res.OldVersion))
+			}
+		case "failed":
+			if includeFailures && crossedFailureThreshold(res) {
+				failures = append(failures, fmt.Sprintf("• %s: %v", res.ID, res.Err))
+			}
+		}
+	}
+
+	var b strings.Builder
+	if len(updates) > 0 {
+		fmt.Fprintf(&b, "Catálogo atualizado (%d apps):\n%s\n", len(updates), strings.Join(updates, "\n"))
+	}
+	if len(pending) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "Aguardando aprovação (%d apps):\n%s\n", len(pending), strings.Join(pending, "\n"))
+	}
+	if len(removals) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "Apps sem fonte (%d):\n%s\n", len(removals), strings.Join(removals, "\n"))
+	}
+	if len(stale) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "Sem verificação há mais de %s (%d):\n%s\n", time.Duration(cfg.StaleAfter), len(stale), strings.Join(stale, "\n"))
+	}
+	if len(failures) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		if threshold := cfg.Notify.FailureThreshold; threshold > 0 {
+			fmt.Fprintf(&b, "Fontes com %d falhas seguidas (%d):\n%s\n", threshold, len(failures), strings.Join(failures, "\n"))
+		} else {
+			fmt.Fprintf(&b, "Fontes com falha (%d):\n%s\n", len(failures), strings.Join(failures, "\n"))
+		}
+	}
+	return b.String()
+}
+
+// crossedFailureThreshold diz se a falha de res deve ser notificada: sem failure_threshold,
+// sempre; com ele, só na execução em que as falhas seguidas chegam ao limite (ver
+// trackFailures). Apps ainda sem entrada no catálogo não têm contagem e são sempre avisados.
+func crossedFailureThreshold(res appResult) bool {
+	threshold := cfg.Notify.FailureThreshold
+	return threshold <= 0 || !res.HasApp || res.App.ConsecutiveFailures == threshold
+}
+
+// postJSON envia payload para o webhook. A URL carrega o segredo (o token do Telegram, o
+// caminho do webhook do Slack/Discord), então os erros de rede saem sem ela.
+func postJSON(endpoint string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// truncate corta o texto no limite de caracteres do destino.
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// send envia o texto por e-mail. Na porta 465 a conexão já abre em TLS;
+// nas demais, smtp.SendMail negocia STARTTLS quando o servidor oferece.
+func (c SMTPConfig) send(text string) error {
+	port := c.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(port))
+
+	from := c.From
+	if from == "" {
+		from = c.Username
+	}
+
+	subject := "[updater-registry] " + strings.SplitN(text, "\n", 2)[0]
+	msg := "From: " + from + "\r\n" +
+		"To: " + strings.Join(c.To, ", ") + "\r\n" +
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + strings.ReplaceAll(text, "\n", "\r\n")
+
+	var auth smtp.Auth
+	if c.Username != "" {
+		auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+
+	if port != 465 {
+		return smtp.SendMail(addr, auth, from, c.To, []byte(msg))
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: c.Host})
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range c.To {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Erros de rede não podem carregar a URL do webhook, que contém o segredo.
+func TestPostJSONHidesEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	endpoint := server.URL + "/botSEGREDO123/sendMessage"
+	server.Close() // Conexão recusada
+
+	err := postJSON(endpoint, map[string]string{"text": "oi"})
+	if err == nil {
+		t.Fatal("esperado erro de conexão")
+	}
+	if strings.Contains(err.Error(), "SEGREDO123") {
+		t.Errorf("erro expõe o token: %v", err)
+	}
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// ==========================================
+// VERSÃO A PARTIR DO PRÓPRIO PACOTE
+// ==========================================
+
+// pkgMetaHeadSize é quanto do início do artefato fica em memória: basta para o control.tar
+// de um .deb e para os headers de um .rpm.
+const pkgMetaHeadSize = 2 << 20
+
+// pkgMetaSniffer acompanha o stream do download (como o hasher) e guarda só o necessário para
+// ler a versão embutida no artefato: o início do arquivo (deb, rpm), a VS_FIXEDFILEINFO de um
+// executável PE e o Info.plist de um .app zipado.
+type pkgMetaSniffer struct {
+	head []byte
+
+	pe    patternCapture
+	plist patternCapture
+}
+
+func newPkgMetaSniffer() *pkgMetaSniffer {
+	return &pkgMetaSniffer{
+		// Assinatura 0xFEEF04BD (little-endian) seguida do restante da VS_FIXEDFILEINFO
+		pe: patternCapture{pattern: []byte{0xbd, 0x04, 0xef, 0xfe}, after: 48, maxHits: 4},
+		// Nome do arquivo no local header do zip; o header (30 bytes) e o nome vêm antes
+		plist: patternCapture{pattern: []byte(".app/Contents/Info.plist"), before: 30 + 256, after: 256 << 10, maxHits: 8},
+	}
+}
+
+func (s *pkgMetaSniffer) Write(p []byte) (int, error) {
+	limit := pkgMetaHeadSize
+	if len(s.head) >= 4 && !s.isDeb() && !s.isRPM() {
+		limit = len(s.head) // Só deb e rpm precisam do início inteiro
+	}
+	if room := limit - len(s.head); room > 0 {
+		s.head = append(s.head, p[:min(room, len(p))]...)
+	}
+	s.pe.Write(p)
+	s.plist.Write(p)
+	return len(p), nil
+}
+
+// version devolve a versão embutida no artefato ("" se o formato não for reconhecido).
+func (s *pkgMetaSniffer) version() (string, error) {
+	switch {
+	case s.isDeb():
+		return debVersion(s.head)
+	case s.isRPM():
+		return rpmVersion(s.head)
+	case bytes.HasPrefix(s.head, []byte("MZ")):
+		return peVersion(s.pe.hits)
+	case bytes.HasPrefix(s.head, []byte("PK\x03\x04")):
+		return zipPlistVersion(s.plist.hits)
+	}
+	return "", nil
+}
+
+// isDeb aceita um início parcial da assinatura, para decidir cedo se vale guardar o início.
+func (s *pkgMetaSniffer) isDeb() bool {
+	if len(s.head) < 4 {
+		return false
+	}
+	n := min(len(s.head), 21)
+	return bytes.HasPrefix([]byte("!<arch>\ndebian-binary"), s.head[:n])
+}
+
+func (s *pkgMetaSniffer) isRPM() bool {
+	return bytes.HasPrefix(s.head, []byte{0xed, 0xab, 0xee, 0xdb})
+}
+
+// patternCapture procura pattern no stream e guarda, a cada ocorrência, 'before' bytes antes
+// dela e 'after' bytes depois, sem precisar do arquivo inteiro.
+type patternCapture struct {
+	pattern       []byte
+	before, after int
+	maxHits       int
+
+	tail    []byte   // Últimos bytes vistos, para casar através de chamadas a Write
+	pos     int64    // Bytes vistos até agora
+	next    int64    // Posição a partir da qual uma nova ocorrência é aceita
+	hits    [][]byte // Capturas completas ou em andamento
+	pending int      // Bytes que ainda faltam na última captura
+}
+
+func (c *patternCapture) Write(p []byte) {
+	if c.pending > 0 {
+		n := min(c.pending, len(p))
+		last := len(c.hits) - 1
+		c.hits[last] = append(c.hits[last], p[:n]...)
+		c.pending -= n
+	}
+
+	buf := append(c.tail, p...)
+	bufStart := c.pos - int64(len(c.tail))
+	c.pos += int64(len(p))
+
+	start := int(max(0, c.next-bufStart))
+	for len(c.hits) < c.maxHits && c.pending == 0 && start < len(buf) {
+		i := bytes.Index(buf[start:], c.pattern)
+		if i < 0 {
+			break
+		}
+		i += start
+		from := max(0, i-c.before)
+		to := min(len(buf), i+len(c.pattern)+c.after)
+		c.hits = append(c.hits, append([]byte(nil), buf[from:to]...))
+		c.pending = i + len(c.pattern) + c.after - to
+		start = i + len(c.pattern)
+		c.next = bufStart + int64(start)
+	}
+
+	keep := c.before + len(c.pattern)
+	if len(buf) > keep {
+		buf = buf[len(buf)-keep:]
+	}
+	c.tail = append(c.tail[:0], buf...)
+}
+
+// ------------------------------------------
+// Formatos
+// ------------------------------------------
+
+// debVersion lê o campo Version do control do .deb.
+func debVersion(head []byte) (string, error) {
+	control, err := debControl(head)
+	if err != nil {
+		return "", err
+	}
+	if v := control["Version"]; v != "" {
+		return v, nil
+	}
+	return "", fmt.Errorf("deb: control sem Version")
+}
+
+// debControl lê os campos do control dentro do control.tar.{gz,xz,zst,} do .deb.
+func debControl(head []byte) (map[string]string, error) {
+	if len(head) < 8 {
+		return nil, fmt.Errorf("deb: arquivo curto demais")
+	}
+	data := head[8:] // "!<arch>\n"
+	for len(data) >= 60 {
+		name := strings.TrimRight(strings.TrimSpace(string(data[0:16])), "/")
+		size, err := strconv.Atoi(strings.TrimSpace(string(data[48:58])))
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("deb: tamanho inválido do membro %s: %q", name, data[48:58])
+		}
+		data = data[60:]
+		if size+size%2 > len(data) { // Membros de tamanho ímpar levam um byte de alinhamento
+			return nil, fmt.Errorf("deb: %s incompleto nos primeiros %d bytes", name, pkgMetaHeadSize)
+		}
+		member := data[:size]
+		data = data[size+size%2:]
+
+		if !strings.HasPrefix(name, "control.tar") {
+			continue
+		}
+		var r io.Reader = bytes.NewReader(member)
+		switch {
+		case strings.HasSuffix(name, ".gz"):
+			r, err = gzip.NewReader(r)
+		case strings.HasSuffix(name, ".xz"):
+			r, err = xz.NewReader(r)
+		case strings.HasSuffix(name, ".zst"):
+			var d *zstd.Decoder
+			d, err = zstd.NewReader(r)
+			if err == nil {
+				defer d.Close()
+				r = d
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("deb: %w", err)
+		}
+
+		tr := tar.NewReader(r)
+		for {
+			h, err := tr.Next()
+			if err != nil {
+				return nil, fmt.Errorf("deb: control não encontrado: %w", err)
+			}
+			if strings.TrimPrefix(h.Name, "./") != "control" {
+				continue
+			}
+			control, err := io.ReadAll(io.LimitReader(tr, 1<<20))
+			if err != nil {
+				return nil, fmt.Errorf("deb: %w", err)
+			}
+			return parseControl(string(control)), nil
+		}
+	}
+	return nil, fmt.Errorf("deb: control.tar não encontrado")
+}
+
+// parseControl lê os campos "Nome: valor" de um control; linhas de continuação (iniciadas por
+// espaço) são juntadas ao campo anterior.
+func parseControl(control string) map[string]string {
+	fields := make(map[string]string)
+	var last string
+	for _, line := range strings.Split(control, "\n") {
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			if last != "" {
+				fields[last] += " " + strings.TrimSpace(line)
+			}
+			continue
+		}
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			last = name
+			fields[name] = strings.TrimSpace(value)
+		}
+	}
+	return fields
+}
+
+// Tags do header principal do rpm usadas aqui.
+const (
+	rpmTagVersion        = 1001
+	rpmTagRelease        = 1002
+	rpmTagEpoch          = 1003
+	rpmTagRequireFlags   = 1048
+	rpmTagRequireName    = 1049
+	rpmTagRequireVersion = 1050
+)
+
+// rpmVersion lê as tags VERSION, RELEASE e EPOCH do header principal, devolvendo
+// "[epoch:]version-release".
+func rpmVersion(head []byte) (string, error) {
+	tags, err := rpmHeader(head)
+	if err != nil {
+		return "", err
+	}
+	if len(tags[rpmTagVersion]) == 0 {
+		return "", fmt.Errorf("rpm: tag VERSION ausente")
+	}
+	version := tags[rpmTagVersion][0]
+	if release := tags[rpmTagRelease]; len(release) > 0 {
+		version += "-" + release[0]
+	}
+	if epoch := tags[rpmTagEpoch]; len(epoch) > 0 {
+		version = epoch[0] + ":" + version
+	}
+	return version, nil
+}
+
+// rpmHeader lê do header principal as tags acima; inteiros vêm em decimal e cada tag traz
+// todos os valores (as de REQUIRE* são listas paralelas).
+func rpmHeader(head []byte) (map[uint32][]string, error) {
+	const leadSize = 96
+	if len(head) < leadSize+16 {
+		return nil, fmt.Errorf("rpm: arquivo curto demais")
+	}
+
+	// Header de assinatura (alinhado em 8 bytes) e, em seguida, o header principal
+	sigCount, sigSize, err := rpmHeaderSize(head[leadSize:])
+	if err != nil {
+		return nil, err
+	}
+	mainStart := leadSize + 16 + sigCount*16 + sigSize
+	mainStart += (8 - mainStart%8) % 8
+	if mainStart+16 > len(head) {
+		return nil, fmt.Errorf("rpm: header principal fora dos primeiros %d bytes", pkgMetaHeadSize)
+	}
+	count, size, err := rpmHeaderSize(head[mainStart:])
+	if err != nil {
+		return nil, err
+	}
+	index := head[mainStart+16:]
+	store := mainStart + 16 + count*16
+	if store+size > len(head) {
+		return nil, fmt.Errorf("rpm: header principal incompleto")
+	}
+
+	tags := make(map[uint32][]string)
+	for i := 0; i < count; i++ {
+		entry := index[i*16:]
+		tag := binary.BigEndian.Uint32(entry[0:])
+		typ := binary.BigEndian.Uint32(entry[4:])
+		off := int(binary.BigEndian.Uint32(entry[8:]))
+		n := int(binary.BigEndian.Uint32(entry[12:]))
+		switch tag {
+		case rpmTagVersion, rpmTagRelease, rpmTagEpoch, rpmTagRequireName, rpmTagRequireFlags, rpmTagRequireVersion:
+		default:
+			continue
+		}
+		if off >= size {
+			continue
+		}
+		data := head[store+off : store+size]
+		switch typ {
+		case 6, 8: // STRING, STRING_ARRAY
+			for j := 0; j < n; j++ {
+				end := bytes.IndexByte(data, 0)
+				if end < 0 {
+					break
+				}
+				tags[tag] = append(tags[tag], string(data[:end]))
+				data = data[end+1:]
+			}
+		case 4: // INT32
+			for j := 0; j < n && len(data) >= 4; j++ {
+				tags[tag] = append(tags[tag], fmt.Sprint(binary.BigEndian.Uint32(data)))
+				data = data[4:]
+			}
+		}
+	}
+	return tags, nil
+}
+
+func rpmHeaderSize(h []byte) (count, size int, err error) {
+	if len(h) < 16 || !bytes.HasPrefix(h, []byte{0x8e, 0xad, 0xe8, 0x01}) {
+		return 0, 0, fmt.Errorf("rpm: header inválido")
+	}
+	return int(binary.BigEndian.Uint32(h[8:])), int(binary.BigEndian.Uint32(h[12:])), nil
+}
+
+// peVersion formata a ProductVersion da VS_FIXEDFILEINFO como "a.b.c.d". A assinatura pode
+// aparecer por acaso no código; só vale a ocorrência seguida de dwStrucVersion 1.0.
+func peVersion(hits [][]byte) (string, error) {
+	for _, info := range hits {
+		if len(info) < 24 || binary.LittleEndian.Uint32(info[4:]) != 0x00010000 {
+			continue
+		}
+		ms := binary.LittleEndian.Uint32(info[16:])
+		ls := binary.LittleEndian.Uint32(info[20:])
+		return fmt.Sprintf("%d.%d.%d.%d", ms>>16, ms&0xffff, ls>>16, ls&0xffff), nil
+	}
+	return "", fmt.Errorf("pe: recurso de versão não encontrado")
+}
+
+// topLevelPlist casa só o Info.plist do .app principal (não o de apps auxiliares aninhados).
+var topLevelPlist = regexp.MustCompile(`^(?:[^/]+/)?[^/]+\.app/Contents/Info\.plist$`)
+
+// zipPlistVersion lê CFBundleShortVersionString (ou CFBundleVersion) do Info.plist capturado.
+func zipPlistVersion(hits [][]byte) (string, error) {
+	plist, err := zipInfoPlist(hits)
+	if err != nil {
+		return "", err
+	}
+	return plistVersion(plist)
+}
+
+// zipInfoPlist descompacta o Info.plist do .app principal a partir das capturas.
+func zipInfoPlist(hits [][]byte) ([]byte, error) {
+	for _, hit := range hits {
+		start := bytes.LastIndex(hit[:min(len(hit), 30+256)], []byte("PK\x03\x04"))
+		if start < 0 || len(hit)-start < 30 {
+			continue
+		}
+		h := hit[start:]
+		method := binary.LittleEndian.Uint16(h[8:])
+		nameLen := int(binary.LittleEndian.Uint16(h[26:]))
+		extraLen := int(binary.LittleEndian.Uint16(h[28:]))
+		if 30+nameLen+extraLen > len(h) || !topLevelPlist.MatchString(string(h[30:30+nameLen])) {
+			continue
+		}
+		data := h[30+nameLen+extraLen:]
+
+		var plist []byte
+		switch method {
+		case 0:
+			plist = data
+		case 8:
+			plist, _ = io.ReadAll(flate.NewReader(bytes.NewReader(data))) // Lê até onde a captura permitir
+		default:
+			return nil, fmt.Errorf("zip: compressão %d não suportada no Info.plist", method)
+		}
+		return plist, nil
+	}
+	return nil, fmt.Errorf("zip: Info.plist do .app não encontrado")
+}
+
+// plistVersion extrai a versão de um Info.plist.
+func plistVersion(data []byte) (string, error) {
+	values, err := plistValues(data)
+	if err != nil {
+		return "", err
+	}
+	for _, k := range []string{"CFBundleShortVersionString", "CFBundleVersion"} {
+		if v := values[k]; v != "" {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("plist sem CFBundleShortVersionString/CFBundleVersion")
+}
+
+// plistValues lê as chaves de valor string de um Info.plist. Só plists em XML são suportados.
+func plistValues(data []byte) (map[string]string, error) {
+	if bytes.HasPrefix(data, []byte("bplist")) {
+		return nil, fmt.Errorf("plist binário não suportado")
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	values := make(map[string]string)
+	var key, last string
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			last = t.Name.Local
+		case xml.CharData:
+			switch last {
+			case "key":
+				key = string(t)
+			case "string":
+				if key != "" {
+					values[key] = strings.TrimSpace(string(t))
+					key = ""
+				}
+			}
+		case xml.EndElement:
+			last = ""
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// arMember é um membro de um arquivo ar; size, quando não vazio, substitui o tamanho real no
+// cabeçalho (para simular cabeçalhos corrompidos).
+type arMember struct {
+	name string
+	data []byte
+	size string
+}
+
+// buildAr monta um arquivo ar ("!<arch>\n"), com o byte de alinhamento após membros ímpares.
+func buildAr(members ...arMember) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("!<arch>\n")
+	for _, m := range members {
+		size := m.size
+		if size == "" {
+			size = fmt.Sprint(len(m.data))
+		}
+		fmt.Fprintf(&buf, "%-16s%-12s%-6s%-6s%-8s%-10s`\n", m.name, "0", "0", "0", "100644", size)
+		buf.Write(m.data)
+		if len(m.data)%2 == 1 {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// controlTarGz monta um control.tar.gz com ./control.
+func controlTarGz(t *testing.T, control string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "./control", Mode: 0644, Size: int64(len(control))}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write([]byte(control))
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestDebControl(t *testing.T) {
+	control := controlTarGz(t, "Package: foo\nVersion: 1.2.3\nDescription: Foo\n algo mais\n")
+	valid := buildAr(arMember{name: "debian-binary", data: []byte("2.0\n")}, arMember{name: "control.tar.gz", data: control})
+
+	tests := []struct {
+		name    string
+		head    []byte
+		wantErr string // "" = sucesso
+	}{
+		{"valido", valid, ""},
+		{"membro impar antes do control", buildAr(arMember{name: "debian-binary", data: []byte("2.0")}, arMember{name: "control.tar.gz", data: control}), ""},
+		{"curto demais", []byte("!<arch"), "curto demais"},
+		{"control truncado", valid[:len(valid)-10], "incompleto"},
+		{"tamanho negativo", buildAr(arMember{name: "debian-binary", data: []byte("2.0\n"), size: "-4"}), "tamanho inválido"},
+		{"tamanho não numérico", buildAr(arMember{name: "debian-binary", data: []byte("2.0\n"), size: "abc"}), "tamanho inválido"},
+		{"membro impar no fim do buffer", buildAr(arMember{name: "debian-binary", data: []byte("2.0")})[:8+60+3], "incompleto"},
+		{"sem control", buildAr(arMember{name: "debian-binary", data: []byte("2.0\n")}), "não encontrado"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := debControl(tt.head)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("erro = %v, esperado contendo %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fields["Package"] != "foo" || fields["Version"] != "1.2.3" {
+				t.Errorf("campos = %v", fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// ==========================================
+// EXECUÇÃO EM DUAS FASES: CHECK / APPLY
+// ==========================================
+
+// Plan é o resultado do "check": as atualizações a aplicar, com tudo o que a estratégia
+// descobriu, para que o "apply" baixe e grave sem consultar as origens de novo — em outra
+// máquina, ou só depois de o plano ser revisado.
+type Plan struct {
+	CreatedAt time.Time    `json:"created_at"`
+	Catalog   string       `json:"catalog"` // Catálogo contra o qual o plano foi calculado
+	Actions   []PlanAction `json:"actions"`
+}
+
+// PlanAction é uma atualização planejada. Com Channel, a ação é de um canal extra do app (ver
+// channels.go), e OldVersion é a versão do canal.
+type PlanAction struct {
+	ID         string      `json:"id"`
+	Channel    string      `json:"channel,omitempty"`
+	OldVersion string      `json:"old_version,omitempty"` // Versão no catálogo durante o check (vazia = app novo)
+	Downgrade  bool        `json:"downgrade,omitempty"`
+	Online     checkResult `json:"online"`
+}
+
+// runCheck executa só os passos A e B de cada fonte e grava o plano em cfg.PlanPath. Nada é
+// baixado e o catálogo não é alterado (nem last_checked). Devolve o código de saída.
+func runCheck() int {
+	infof(">>> Calculando plano...")
+	ctx, stop := runContext()
+	defer stop()
+
+	lintBeforeGenerate()
+	sources, _ := selectSources(profileSources(loadSources(cfg.Sources...)))
+	_, oldCatalog := workingCatalog()
+	_, compareCatalog := pendingOverlay(oldCatalog)
+
+	if cfg.GithubCachePath != "" {
+		loadGithubETags(cfg.GithubCachePath)
+	}
+	if cfg.GithubGraphQL {
+		prefetchGithub(ctx, sources)
+	}
+	runDownloads = newDownloadMemo() // Só para avisar de URLs repetidas
+
+	type resolved struct {
+		res        appResult
+		online     checkResult
+		ok         bool
+		channels   []PlanAction
+		channelErr error
+	}
+	all := make([]resolved, len(sources))
+	runConcurrently(len(sources), func(i int) {
+		res, online, ok := resolveApp(ctx, sources[i], compareCatalog)
+		all[i] = resolved{res: res, online: online, ok: ok}
+		// Canais extras, nas mesmas condições do processApp
+		if (res.HasApp || ok) && !res.BreakerOpen && !res.NotDue && len(sources[i].Channels) > 0 {
+			all[i].channels, all[i].channelErr = planChannels(ctx, sources[i], compareCatalog.Apps[sources[i].ID])
+		}
+	})
+
+	if cfg.GithubCachePath != "" {
+		saveGithubETags(cfg.GithubCachePath)
+	}
+
+	plan := Plan{CreatedAt: time.Now().UTC(), Catalog: cfg.CatalogPath, Actions: []PlanAction{}}
+	failures := 0
+	for _, r := range all {
+		switch {
+		case r.res.Err != nil:
+			failures++
+		case r.ok:
+			plan.Actions = append(plan.Actions, PlanAction{ID: r.res.ID, OldVersion: r.res.OldVersion, Downgrade: r.res.Downgrade, Online: r.online})
+			from := r.res.OldVersion
+			if from == "" {
+				from = "novo"
+			}
+			fmt.Printf("~ %s %s → %s\n", r.res.ID, from, r.online.Version)
+		}
+		if r.channelErr != nil && r.res.Err == nil {
+			failures++
+		}
+		for _, action := range r.channels {
+			plan.Actions = append(plan.Actions, action)
+			from := action.OldVersion
+			if from == "" {
+				from = "novo"
+			}
+			fmt.Printf("~ %s [%s] %s → %s\n", action.ID, action.Channel, from, action.Online.Version)
+		}
+	}
+
+	data, _ := json.MarshalIndent(plan, "", "  ")
+	if err := os.WriteFile(cfg.PlanPath, data, 0644); err != nil {
+		log.Fatalf("Falha ao gravar o plano %s: %v", cfg.PlanPath, err)
+	}
+	infof(">>> Plano gravado em %s: %d atualização(ões), %d falha(s).", cfg.PlanPath, len(plan.Actions), failures)
+
+	if exceedsFailureBudget(failures) {
+		errorf(">>> Orçamento de falhas excedido (%d falhas, máximo %d).", failures, cfg.MaxFailures)
+		return exitFailureBudget
+	}
+	return 0
+}
+
+// runApply executa os passos C e D do plano em cfg.PlanPath e grava o catálogo como o generate.
+// Uma ação cuja versão de partida não é mais a do catálogo (outra execução já o alterou) é
+// ignorada: o plano está desatualizado para aquele app. Devolve o código de saída.
+func runApply() int {
+	data, err := os.ReadFile(cfg.PlanPath)
+	if err != nil {
+		log.Fatalf("Falha ao ler o plano: %v", err)
+	}
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		log.Fatalf("Plano inválido %s: %v", cfg.PlanPath, err)
+	}
+	infof(">>> Aplicando plano de %s (%d atualizações)...", plan.CreatedAt.Format(time.RFC3339), len(plan.Actions))
+	if plan.Catalog != cfg.CatalogPath {
+		errorf(" [AVISO] O plano foi calculado contra %s, mas o catálogo configurado é %s.", plan.Catalog, cfg.CatalogPath)
+	}
+
+	ctx, stop := runContext()
+	defer stop()
+
+	sources := make(map[string]SourceApp)
+	for _, src := range loadSources(cfg.Sources...) {
+		sources[src.ID] = src
+	}
+	target, oldCatalog := workingCatalog()
+	pending, compareCatalog := pendingOverlay(oldCatalog)
+	runDownloads = newDownloadMemo()
+	defer runDownloads.cleanup()
+
+	// Apps fora do plano seguem como estão
+	newCatalog := Catalog{LastUpdated: time.Now(), Apps: make(map[string]CatalogApp, len(oldCatalog.Apps)), Aliases: oldCatalog.Aliases}
+	for id, app := range oldCatalog.Apps {
+		newCatalog.Apps[id] = app
+	}
+
+	results := make([]appResult, len(plan.Actions))
+	runConcurrently(len(plan.Actions), func(i int) {
+		start := time.Now()
+		results[i] = applyAction(ctx, sources, compareCatalog, plan.Actions[i])
+		results[i].Duration = time.Since(start)
+	})
+	results = mergeChannelResults(plan.Actions, results, compareCatalog)
+
+	if err := ctx.Err(); err != nil {
+		errorf(">>> Execução interrompida (%v); apps não concluídos mantêm a versão anterior.", context.Cause(ctx))
+	}
+	return finishRun(target, oldCatalog, newCatalog, results, pending)
+}
+
+// applyAction aplica uma ação do plano, conferindo antes se ela ainda vale.
+func applyAction(ctx context.Context, sources map[string]SourceApp, catalog Catalog, action PlanAction) appResult {
+	oldApp, exists := catalog.Apps[action.ID]
+	res := appResult{ID: action.ID, App: oldApp, HasApp: exists, OldVersion: oldApp.Version, OldSize: oldApp.Size,
+		NewVersion: action.Online.Version, Downgrade: action.Downgrade}
+
+	src, ok := sources[action.ID]
+	if !ok {
+		res.Err = fmt.Errorf("fonte não encontrada (removida depois do check?)")
+		errorf(" [ERRO] %s: %v", action.ID, res.Err)
+		return res
+	}
+	if action.Channel == "" && oldApp.Version != action.OldVersion {
+		infof(" [SKIP] %s: plano desatualizado (catálogo em %s, plano partiu de %s).", action.ID, oldApp.Version, action.OldVersion)
+		return res
+	}
+	if action.Channel != "" {
+		return applyChannelAction(ctx, src, catalog, action)
+	}
+	runDownloads.claimURL(src.ID, action.Online.URL)
+	return applyApp(ctx, src, catalog, action.Online, res)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// check planeja o canal como ação própria e apply a grava no canal da entrada, sem mexer na
+// entrada principal.
+func TestCheckApplyChannels(t *testing.T) {
+	dir := t.TempDir()
+	checksum := strings.Repeat("ab", 32) // Com hash e tamanho da origem, o apply não baixa nada
+	source := []map[string]any{{
+		"id": "foo", "name": "Foo", "install_type": "deb", "strategy": "script",
+		"config": map[string]string{"version": "1.0.0", "script": `
+def check(config):
+    return {"version": config["version"], "url": "https://example.org/foo_" + config["version"] + ".deb",
+            "checksum": "` + checksum + `", "size": 10}
+`},
+		"channels": map[string]any{"beta": map[string]any{"config": map[string]string{"version": "2.0.0-beta.1"}}},
+	}}
+	lastChecked := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	catalog := Catalog{Apps: map[string]CatalogApp{"foo": {
+		ID: "foo", Name: "Foo", Version: "1.0.0", DownloadURL: "https://example.org/foo_1.0.0.deb", Checksum: checksum, Size: 10,
+		LastChecked: lastChecked,
+		Channels:    map[string]ChannelRelease{"beta": {Version: "1.9.0", DownloadURL: "https://example.org/foo_1.9.0.deb", Checksum: checksum, Size: 10}},
+	}}}
+
+	writeJSON := func(name string, v any) string {
+		path := filepath.Join(dir, name)
+		data, _ := json.Marshal(v)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	saved := cfg
+	t.Cleanup(func() { cfg = saved })
+	cfg.Sources = []string{writeJSON("apps.source.json", source)}
+	cfg.CatalogPath = writeJSON("catalog.json", catalog)
+	cfg.PlanPath = filepath.Join(dir, "plan.json")
+
+	if code := runCheck(); code != 0 {
+		t.Fatalf("check saiu com %d", code)
+	}
+	var plan Plan
+	data, _ := os.ReadFile(cfg.PlanPath)
+	if err := json.Unmarshal(data, &plan); err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) != 1 {
+		t.Fatalf("plano com %d ações, esperada só a do canal: %+v", len(plan.Actions), plan.Actions)
+	}
+	if action := plan.Actions[0]; action.ID != "foo" || action.Channel != "beta" || action.OldVersion != "1.9.0" || action.Online.Version != "2.0.0-beta.1" {
+		t.Errorf("ação = %+v", action)
+	}
+
+	if code := runApply(); code != 0 {
+		t.Fatalf("apply saiu com %d", code)
+	}
+	app := loadCatalog(cfg.CatalogPath).Apps["foo"]
+	if beta := app.Channels["beta"]; beta.Version != "2.0.0-beta.1" || beta.DownloadURL != "https://example.org/foo_2.0.0-beta.1.deb" {
+		t.Errorf("canal beta = %+v", beta)
+	}
+	if app.Version != "1.0.0" || !app.LastChecked.Equal(lastChecked) {
+		t.Errorf("entrada principal alterada: versão %s, last_checked %s", app.Version, app.LastChecked)
+	}
+
+	// O plano já aplicado fica desatualizado para o canal
+	if code := runApply(); code != 0 {
+		t.Fatalf("segundo apply saiu com %d", code)
+	}
+	if beta := loadCatalog(cfg.CatalogPath).Apps["foo"].Channels["beta"]; beta.Version != "2.0.0-beta.1" {
+		t.Errorf("canal beta após reaplicar = %+v", beta)
+	}
+}
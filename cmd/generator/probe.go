@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ==========================================
+// SUBCOMANDO: PROBE
+// ==========================================
+
+// runProbe executa checagem + download de uma única fonte e imprime o que foi resolvido,
+// sem ler nem gravar o catálogo. O alvo é um id das fontes configuradas ou um arquivo de
+// fontes (lista ou um único objeto), para testar uma fonte nova antes de registrá-la.
+func runProbe(args []string) {
+	if len(args) != 1 {
+		log.Fatal("Uso: generator probe [flags] <id | arquivo de fonte>")
+	}
+	sources, err := probeTargets(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Sem efeitos colaterais: nada de torrents nem publicação no IPFS
+	cfg.Torrent, cfg.IPFS = TorrentConfig{}, IPFSConfig{}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Timeout))
+		defer cancel()
+	}
+
+	failed := false
+	for _, src := range sources {
+		if !probeSource(ctx, src) {
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// probeTargets resolve o argumento do probe: arquivo existente ou id das fontes configuradas.
+func probeTargets(target string) ([]SourceApp, error) {
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		if sources, err := readSourceFile(target); err == nil {
+			return sources, nil
+		}
+		// Um único objeto, como costuma ser escrito ao criar uma fonte
+		data, err := readAsJSON(target)
+		if err != nil {
+			return nil, err
+		}
+		var src SourceApp
+		if err := json.Unmarshal(data, &src); err != nil {
+			return nil, fmt.Errorf("%s: %w", target, err)
+		}
+		return []SourceApp{src}, nil
+	}
+
+	sources, err := readSources(cfg.Sources...)
+	if err != nil {
+		return nil, err
+	}
+	for _, src := range sources {
+		if src.ID == target {
+			return []SourceApp{src}, nil
+		}
+	}
+	return nil, fmt.Errorf("fonte '%s' não encontrada em %s", target, strings.Join(cfg.Sources, ", "))
+}
+
+// probeSource imprime o resultado de uma fonte; devolve false se algo falhou.
+func probeSource(ctx context.Context, src SourceApp) bool {
+	fmt.Printf("%s (%s)\n", src.ID, src.Strategy)
+
+	start := time.Now()
+	online, err := checkStrategy(ctx, src)
+	if err != nil {
+		fmt.Printf("  checagem:  FALHOU: %v\n", err)
+		return false
+	}
+	raw := online.Version
+	if online.Version, err = normalizeVersion(src.Config, raw); err != nil {
+		fmt.Printf("  versão:    %q: FALHOU: %v\n", raw, err)
+		return false
+	}
+	if raw != online.Version {
+		fmt.Printf("  versão:    %s (resolvida: %q)\n", online.Version, raw)
+	} else {
+		fmt.Printf("  versão:    %s\n", online.Version)
+	}
+	fmt.Printf("  url:       %s\n", online.URL)
+	if online.FetchURL != "" {
+		fmt.Printf("  origem:    %s\n", online.FetchURL)
+	}
+	for i, group := range online.Matches {
+		fmt.Printf("  grupo %d:   %q\n", i, group)
+	}
+	if !online.ReleasedAt.IsZero() {
+		fmt.Printf("  publicado: %s\n", online.ReleasedAt.Format(time.RFC3339))
+	}
+	fmt.Printf("  checagem:  %s\n", time.Since(start).Round(time.Millisecond))
+
+	if (online.Checksum != "" || online.SHA512 != "") && online.Size > 0 {
+		fmt.Printf("  tamanho:   %d bytes (informado pela origem)\n", online.Size)
+		if online.Checksum != "" {
+			fmt.Printf("  sha256:    %s (informado pela origem)\n", strings.ToLower(online.Checksum))
+		}
+		if online.SHA512 != "" {
+			fmt.Printf("  sha512:    %s (informado pela origem)\n", online.SHA512)
+		}
+		return true
+	}
+
+	fetchURL := online.URL
+	if online.FetchURL != "" {
+		fetchURL = online.FetchURL
+	}
+	start = time.Now()
+	dl, err := downloadAndHash(ctx, src, fetchURL)
+	if err != nil {
+		fmt.Printf("  download:  FALHOU: %v\n", err)
+		return false
+	}
+	defer dl.discard()
+	fmt.Printf("  tamanho:   %d bytes\n", dl.Size)
+	if online.Size > 0 && online.Size != dl.Size {
+		fmt.Printf("  aviso:     a origem informou %d bytes\n", online.Size)
+	}
+	fmt.Printf("  sha256:    %s\n", dl.Checksum)
+	if err := verifyDownload(ctx, src, &online, dl); err != nil {
+		fmt.Printf("  conferido: FALHOU: %v\n", err)
+		return false
+	}
+	if online.ExpectedSHA256 != "" {
+		fmt.Printf("  conferido: sha256 publicado em %s\n", online.ExpectedFrom)
+	}
+	switch {
+	case dl.PackageVersion != "":
+		fmt.Printf("  pacote:    %s (versão embutida no artefato)\n", dl.PackageVersion)
+	case dl.PackageVersionErr != nil:
+		fmt.Printf("  pacote:    %v\n", dl.PackageVersionErr)
+	}
+	if req := dl.Requirements; req != nil {
+		if req.MinOSVersion != "" {
+			fmt.Printf("  sistema:   >= %s\n", req.MinOSVersion)
+		}
+		if req.Glibc != "" {
+			fmt.Printf("  glibc:     >= %s\n", req.Glibc)
+		}
+		if len(req.Depends) > 0 {
+			fmt.Printf("  depende:   %s\n", strings.Join(req.Depends, ", "))
+		}
+	}
+	fmt.Printf("  download:  %s\n", time.Since(start).Round(time.Millisecond))
+	return true
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"log"
+	"path"
+	"strings"
+)
+
+// ==========================================
+// PERFIS (VÁRIOS CATÁLOGOS A PARTIR DAS MESMAS FONTES)
+// ==========================================
+
+// Profile é um catálogo nomeado gerado a partir das fontes com alguma das tags do perfil
+// (ex: "desktop", "server", "internal"), com caminho e chave de assinatura próprios.
+type Profile struct {
+	Name        string             `json:"name"`
+	Catalog     string             `json:"catalog"`      // Onde gravar o catálogo do perfil (local, s3://, gs:// ou az://)
+	Tags        []string           `json:"tags"`         // Fontes com alguma destas tags entram no perfil (vazio = todas)
+	SigningKey  string             `json:"signing_key"`  // Chave Ed25519 do perfil (vazio = a signing_key global)
+	SigningKeys []SigningKeyConfig `json:"signing_keys"` // Chaves com validade do perfil (vazio = as signing_keys globais)
+}
+
+// activeProfile é o perfil em uso (nil sem perfis), aplicado por useProfile.
+var activeProfile *Profile
+
+// forEachProfile executa run uma vez por perfil configurado, na ordem declarada, ou uma vez
+// só quando não há perfis ou -profile escolheu um. Devolve o primeiro código de saída
+// diferente de zero, depois de passar por todos os perfis.
+func forEachProfile(run func() int) int {
+	if len(cfg.Profiles) == 0 || activeProfile != nil {
+		return run()
+	}
+
+	seen := make(map[string]bool)
+	for _, profile := range cfg.Profiles {
+		if seen[profile.Name] {
+			log.Fatalf("Perfil duplicado: %s", profile.Name)
+		}
+		seen[profile.Name] = true
+	}
+
+	base, code := cfg, 0
+	for _, profile := range base.Profiles {
+		useProfile(profile)
+		infof(">>> Perfil %s (%s)", profile.Name, cfg.CatalogPath)
+		if c := run(); c != 0 && code == 0 {
+			code = c
+		}
+		cfg, activeProfile = base, nil
+	}
+	return code
+}
+
+// selectProfile aplica o perfil escolhido por -profile (ou profile/UPDATER_PROFILE), para que
+// qualquer subcomando (promote, approve, serve, ...) opere sobre o catálogo daquele perfil.
+func selectProfile(name string) {
+	for _, profile := range cfg.Profiles {
+		if profile.Name == name {
+			useProfile(profile)
+			return
+		}
+	}
+	log.Fatalf("Perfil desconhecido: %s", name)
+}
+
+// useProfile troca na configuração o que é próprio de cada catálogo: o caminho, a chave de
+// assinatura e os arquivos derivados do catálogo, que ganham o nome do perfil como sufixo
+// (report.json -> report-desktop.json) para que os perfis não se sobrescrevam.
+func useProfile(profile Profile) {
+	if profile.Name == "" || profile.Catalog == "" {
+		log.Fatal("Todo perfil precisa de 'name' e 'catalog'")
+	}
+	cfg.CatalogPath = profile.Catalog
+	if profile.SigningKey != "" {
+		cfg.SigningKey = profile.SigningKey
+	}
+	if len(profile.SigningKeys) > 0 {
+		cfg.SigningKeys = profile.SigningKeys
+	}
+	for _, p := range []*string{&cfg.StagingPath, &cfg.PendingPath, &cfg.PlanPath, &cfg.ReportPath,
+		&cfg.SummaryPath, &cfg.ChangelogPath, &cfg.Snapshots.Dir, &cfg.TUF.Dir, &cfg.Encrypt.PrivateCatalog} {
+		if *p != "" {
+			*p = profilePath(*p, profile.Name)
+		}
+	}
+	activeProfile = &profile
+}
+
+// profilePath acrescenta o nome do perfil antes da extensão: plan.json -> plan-desktop.json.
+func profilePath(p, name string) string {
+	ext := path.Ext(p)
+	return strings.TrimSuffix(p, ext) + "-" + name + ext
+}
+
+// profileSources restringe as fontes às do perfil ativo. Fontes fora do perfil não entram
+// no catálogo dele (e uma entrada que já estava lá fica órfã, ver orphanResults).
+func profileSources(sources []SourceApp) []SourceApp {
+	if activeProfile == nil || len(activeProfile.Tags) == 0 {
+		return sources
+	}
+	tags := toSet(activeProfile.Tags)
+	var selected []SourceApp
+	for _, src := range sources {
+		for _, tag := range src.Tags {
+			if tags[tag] {
+				selected = append(selected, src)
+				break
+			}
+		}
+	}
+	return selected
+}
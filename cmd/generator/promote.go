@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+)
+
+// ==========================================
+// SUBCOMANDO: PROMOTE (STAGING -> PRODUÇÃO)
+// ==========================================
+
+// Flags do promote, registradas por promoteFlags.
+var promoteForce *bool
+
+func promoteFlags(fs *flag.FlagSet) {
+	promoteForce = fs.Bool("force", false, "promote: promove mesmo com erros na validação")
+}
+
+// workingCatalog devolve onde o generate grava e o catálogo de partida. Com staging
+// configurado, as atualizações vão para o staging, que parte do próprio staging (ainda não
+// promovido) ou, se ele estiver vazio, da produção.
+func workingCatalog() (string, Catalog) {
+	if cfg.StagingPath == "" {
+		return cfg.CatalogPath, loadCatalog(cfg.CatalogPath)
+	}
+	if staging := loadCatalog(cfg.StagingPath); len(staging.Apps) > 0 {
+		return cfg.StagingPath, staging
+	}
+	return cfg.StagingPath, loadCatalog(cfg.CatalogPath)
+}
+
+// runPromote valida o catálogo de staging e o copia para a produção, publicando-o como o
+// generate faria sem staging (IPFS, rsync). Assim uma release ruim detectada pelo generate
+// só chega aos clientes depois de passar por aqui.
+func runPromote() {
+	if cfg.StagingPath == "" {
+		log.Fatal("promote: nenhum catálogo de staging configurado (staging, UPDATER_STAGING ou -staging)")
+	}
+	staging, issues, err := promoteCatalog(cfg.StagingPath, cfg.CatalogPath, *promoteForce)
+	for _, issue := range issues {
+		if issue.Error {
+			errorf(" %s", issue)
+		} else {
+			infof(" %s", issue)
+		}
+	}
+	if errors.Is(err, errPromotionBlocked) {
+		errorf(">>> %v; nada foi promovido (use -force para promover assim mesmo).", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	infof(">>> Staging %s promovido para %s (%d apps).", cfg.StagingPath, cfg.CatalogPath, len(staging.Apps))
+	if !publishProduction() {
+		os.Exit(exitPublishFailed)
+	}
+}
+
+// errPromotionBlocked indica que a validação do staging encontrou erros.
+var errPromotionBlocked = errors.New("erros na validação do staging")
+
+// promoteCatalog valida o staging e o grava em catalogPath, devolvendo o catálogo promovido
+// e o que a validação encontrou. Com erros e sem force, nada é gravado (errPromotionBlocked).
+// Usado pelo subcomando e pela rota de promoção do modo servidor.
+func promoteCatalog(stagingPath, catalogPath string, force bool) (Catalog, []lintIssue, error) {
+	staging, err := readCatalog(stagingPath)
+	if err != nil {
+		return Catalog{}, nil, fmt.Errorf("falha ao ler o staging %s: %w", stagingPath, err)
+	}
+	if len(staging.Apps) == 0 {
+		return Catalog{}, nil, fmt.Errorf("o staging %s está vazio", stagingPath)
+	}
+	production, err := readCatalog(catalogPath)
+	if err != nil {
+		return Catalog{}, nil, fmt.Errorf("falha ao ler o catálogo %s: %w", catalogPath, err)
+	}
+
+	issues := validatePromotion(stagingPath, staging, production)
+	failures := 0
+	for _, issue := range issues {
+		if issue.Error {
+			failures++
+		}
+	}
+	if failures > 0 && !force {
+		return Catalog{}, issues, fmt.Errorf("%w (%d)", errPromotionBlocked, failures)
+	}
+
+	staging.Stats = computeStats(staging.Apps)
+	if err := writeCatalog(catalogPath, staging); err != nil {
+		return Catalog{}, issues, fmt.Errorf("falha ao gravar o catálogo %s: %w", catalogPath, err)
+	}
+	return staging, issues, nil
+}
+
+// publishProduction publica o catálogo de produção já gravado (IPFS, rsync), como o
+// generate faz sem staging; devolve false se o rsync falhou.
+func publishProduction() bool {
+	if cfg.IPFS.enabled() && cfg.IPFS.Catalog {
+		if cid, err := publishCatalogIPFS(cfg.CatalogPath); err != nil {
+			errorf(" [ERRO] Falha ao publicar o catálogo no IPFS: %v", err)
+		} else {
+			infof(">>> Catálogo publicado no IPFS: %s", cid)
+		}
+	}
+	if cfg.Rsync.enabled() {
+		if err := publishRsync(); err != nil {
+			errorf(" [ERRO] Falha ao publicar via rsync em %s: %v", cfg.Rsync.Target, err)
+			return false
+		}
+		infof(">>> Publicado via rsync em %s", cfg.Rsync.Target)
+	}
+	return true
+}
+
+// validatePromotion confere o staging antes de ele substituir a produção: entradas
+// incompletas e versões que regridem são erros; apps que somem da produção, avisos.
+func validatePromotion(stagingPath string, staging, production Catalog) []lintIssue {
+	var issues []lintIssue
+	add := func(id string, isError bool, format string, args ...interface{}) {
+		issues = append(issues, lintIssue{stagingPath, id, isError, fmt.Sprintf(format, args...)})
+	}
+
+	ids := make([]string, 0, len(staging.Apps))
+	for id := range staging.Apps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		app := staging.Apps[id]
+		switch {
+		case app.Version == "" || app.DownloadURL == "":
+			add(id, true, "entrada sem versão ou download_url")
+		case app.Size <= 0:
+			add(id, true, "entrada sem tamanho")
+		case app.Checksum == "" && app.SHA512 == "":
+			add(id, true, "entrada sem hash (checksum ou sha512)")
+		}
+		if old, ok := production.Apps[id]; ok && schemeOrdered(app.VersionScheme) && compareVersions(app.VersionScheme, app.Version, old.Version) < 0 {
+			add(id, true, "versão regride de %s para %s", old.Version, app.Version)
+		}
+	}
+
+	var removed []string
+	for id := range production.Apps {
+		if _, ok := staging.Apps[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	for _, id := range removed {
+		add(id, false, "app sai da produção (ausente no staging)")
+	}
+	return issues
+}
@@ -0,0 +1,47 @@
+package main
+
+import "sort"
+
+// ==========================================
+// APPS SEM FONTE (ÓRFÃOS)
+// ==========================================
+
+// orphanResults trata as entradas do catálogo cujo app não está mais em nenhuma fonte
+// (nem nas filtradas desta execução). Sem cfg.Prune elas são mantidas, marcadas como
+// orphaned; com cfg.Prune saem do catálogo. Em ambos os casos viram resultados da
+// execução, para aparecer no relatório, no resumo e nas notificações.
+func orphanResults(oldCatalog Catalog, sourceLists ...[]SourceApp) []appResult {
+	known := make(map[string]bool)
+	for _, list := range sourceLists {
+		for _, src := range list {
+			known[src.ID] = true
+		}
+	}
+
+	var ids []string
+	for id := range oldCatalog.Apps {
+		if !known[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	results := make([]appResult, 0, len(ids))
+	for _, id := range ids {
+		old := oldCatalog.Apps[id]
+		res := appResult{ID: id, OldVersion: old.Version, OldSize: old.Size}
+		if cfg.Prune {
+			res.Removed, res.Changed = true, true
+			infof(" [REMOVIDO] %s: sem fonte, retirado do catálogo (-prune).", id)
+		} else {
+			res.Orphaned, res.HasApp = true, true
+			res.App = old
+			if !old.Orphaned {
+				res.App.Orphaned, res.Changed = true, true
+				infof(" [ÓRFÃO] %s: sem fonte; mantido como orphaned (use -prune para retirar).", id)
+			}
+		}
+		results = append(results, res)
+	}
+	return results
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ==========================================
+// PUBLICAÇÃO: RSYNC VIA SSH
+// ==========================================
+
+// RsyncConfig espelha os arquivos gerados (catalog.json, .torrent, ...) para a raiz web de
+// um servidor via rsync sobre SSH, para hospedagens clássicas em VPS.
+type RsyncConfig struct {
+	Target  string   `json:"target"`   // Destino do rsync, ex: deploy@vps.example.com:/var/www/registry/ (vazio = desativado)
+	Paths   []string `json:"paths"`    // Arquivos/diretórios locais a enviar (padrão: catálogo + torrent.dir)
+	SSHKey  string   `json:"ssh_key"`  // Chave privada (opcional; padrão do ssh)
+	SSHPort int      `json:"ssh_port"` // Porta SSH (padrão: 22)
+	Delete  bool     `json:"delete"`   // Remove no destino o que não existe mais localmente
+	Args    []string `json:"args"`     // Argumentos extras do rsync (ex: ["--chmod=F644"])
+}
+
+func (r RsyncConfig) enabled() bool {
+	return r.Target != ""
+}
+
+// paths devolve o que será enviado. Catálogos remotos (s3://, gs://, ...) não entram no
+// padrão, pois não há arquivo local para espelhar.
+func (r RsyncConfig) paths() []string {
+	if len(r.Paths) > 0 {
+		return r.Paths
+	}
+	var paths []string
+	if !strings.Contains(cfg.CatalogPath, "://") {
+		paths = append(paths, cfg.CatalogPath)
+		if cfg.SigningKey != "" {
+			paths = append(paths, cfg.CatalogPath+".sig")
+		}
+		if len(cfg.SigningKeys) > 0 {
+			paths = append(paths, cfg.CatalogPath+".sigs", cfg.CatalogPath+".keys", cfg.CatalogPath+".keys.sigs")
+		}
+	}
+	if private := cfg.Encrypt.privatePath(); cfg.Encrypt.enabled() && cfg.Encrypt.PrivateOnly && !strings.Contains(private, "://") {
+		paths = append(paths, private)
+		if cfg.SigningKey != "" {
+			paths = append(paths, private+".sig")
+		}
+		if len(cfg.SigningKeys) > 0 {
+			paths = append(paths, private+".sigs", private+".keys", private+".keys.sigs")
+		}
+	}
+	if cfg.Torrent.enabled() {
+		paths = append(paths, strings.TrimSuffix(cfg.Torrent.Dir, "/"))
+	}
+	if cfg.TUF.enabled() && !strings.Contains(cfg.TUF.Dir, "://") {
+		paths = append(paths, strings.TrimSuffix(cfg.TUF.Dir, "/"))
+	}
+	return paths
+}
+
+// publishRsync executa o rsync configurado. A saída do rsync vai direto para o log.
+func publishRsync() error {
+	paths := cfg.Rsync.paths()
+	if len(paths) == 0 {
+		return fmt.Errorf("nada a publicar (defina rsync.paths)")
+	}
+
+	ssh := []string{"ssh", "-o", "BatchMode=yes"}
+	if cfg.Rsync.SSHPort != 0 {
+		ssh = append(ssh, "-p", strconv.Itoa(cfg.Rsync.SSHPort))
+	}
+	if cfg.Rsync.SSHKey != "" {
+		ssh = append(ssh, "-i", cfg.Rsync.SSHKey)
+	}
+
+	args := []string{"-az", "-e", strings.Join(ssh, " ")}
+	if cfg.Rsync.Delete {
+		args = append(args, "--delete")
+	}
+	if logLevel >= levelVerbose {
+		args = append(args, "-v")
+	}
+	args = append(args, cfg.Rsync.Args...)
+	args = append(args, paths...)
+	args = append(args, cfg.Rsync.Target)
+
+	debugf("     rsync %s", strings.Join(args, " "))
+	cmd := exec.Command("rsync", args...)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ==========================================
+// SUBCOMANDO: REMOVE
+// ==========================================
+
+// Flags do remove, registradas por removeFlags.
+var (
+	removeKeepCatalog *bool
+	removeDeprecate   *bool
+	removeSunset      *string
+	removeReplacedBy  *string
+)
+
+func removeFlags(fs *flag.FlagSet) {
+	removeKeepCatalog = fs.Bool("keep-catalog", false, "remove: não altera o catálogo (a entrada some na próxima geração)")
+	removeDeprecate = fs.Bool("deprecate", false, "remove: em vez de apagar, marca o app como descontinuado nas fontes e no catálogo")
+	removeSunset = fs.String("sunset", "", "remove: data do fim do suporte (AAAA-MM-DD); implica -deprecate")
+	removeReplacedBy = fs.String("replaced-by", "", "remove: id do app sugerido para migração; implica -deprecate")
+}
+
+// sourceField é uma chave a gravar na fonte editada pelo remove.
+type sourceField struct {
+	Key   string
+	Value any
+}
+
+// runRemove retira um app do arquivo de fontes que o declara e do catálogo, mantendo os
+// dois consistentes. Com -deprecate, o app continua publicado, marcado como descontinuado.
+func runRemove(args []string) {
+	if len(args) != 1 {
+		log.Fatal("Uso: generator remove [-deprecate [-sunset AAAA-MM-DD] [-replaced-by id]] [-keep-catalog] <id>")
+	}
+	id := args[0]
+
+	// Campos gravados na fonte e no catálogo quando o app é descontinuado
+	var marks []sourceField
+	if *removeDeprecate || *removeSunset != "" || *removeReplacedBy != "" {
+		*removeDeprecate = true
+		marks = append(marks, sourceField{"deprecated", true})
+	}
+	if *removeSunset != "" {
+		if _, err := time.Parse(time.DateOnly, *removeSunset); err != nil {
+			log.Fatalf("remove: -sunset inválido %q (use AAAA-MM-DD)", *removeSunset)
+		}
+		marks = append(marks, sourceField{"sunset", *removeSunset})
+	}
+	if *removeReplacedBy != "" {
+		if *removeReplacedBy == id {
+			log.Fatal("remove: -replaced-by não pode apontar para o próprio app")
+		}
+		if _, err := findSourceFile(*removeReplacedBy); err != nil {
+			log.Fatalf("remove: -replaced-by: %v", err)
+		}
+		marks = append(marks, sourceField{"replaced_by", *removeReplacedBy})
+	}
+
+	file, err := findSourceFile(id)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := editSourceFile(file, id, marks); err != nil {
+		log.Fatalf("%s: %v", file, err)
+	}
+	if *removeDeprecate {
+		infof(">>> %s marcado como descontinuado em %s", id, file)
+	} else {
+		infof(">>> %s removido de %s", id, file)
+	}
+
+	if *removeKeepCatalog {
+		return
+	}
+	catalog, err := readCatalog(cfg.CatalogPath)
+	if err != nil {
+		log.Fatalf("Falha ao ler o catálogo %s: %v", cfg.CatalogPath, err)
+	}
+	app, ok := catalog.Apps[id]
+	if !ok {
+		infof(">>> %s não estava no catálogo %s", id, cfg.CatalogPath)
+		return
+	}
+	if *removeDeprecate {
+		app.Deprecated = true
+		if *removeSunset != "" {
+			app.Sunset = *removeSunset
+		}
+		if *removeReplacedBy != "" {
+			app.ReplacedBy = *removeReplacedBy
+		}
+		catalog.Apps[id] = app
+	} else {
+		delete(catalog.Apps, id)
+	}
+	catalog.LastUpdated = time.Now()
+	catalog.Stats = computeStats(catalog.Apps)
+	saveCatalog(cfg.CatalogPath, catalog)
+	infof(">>> Catálogo %s atualizado", cfg.CatalogPath)
+}
+
+// findSourceFile devolve o arquivo de fontes que declara o id.
+func findSourceFile(id string) (string, error) {
+	files, err := expandSourcePaths(cfg.Sources)
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		sources, err := readSourceFile(file)
+		if err != nil {
+			return "", err
+		}
+		for _, src := range sources {
+			if src.ID == id {
+				return file, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("fonte '%s' não encontrada em %s", id, strings.Join(cfg.Sources, ", "))
+}
+
+// editSourceFile apaga a fonte no próprio arquivo ou, com marks, grava essas chaves nela
+// (descontinuação), preservando a ordem das chaves e das demais fontes. TOML não é reescrito,
+// pois o encoder descartaria comentários e a formatação.
+func editSourceFile(file, id string, marks []sourceField) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		out, err = editSourceJSON(data, id, marks)
+	case ".yaml", ".yml":
+		out, err = editSourceYAML(data, id, marks)
+	default:
+		return fmt.Errorf("edição automática não suportada para este formato; edite o arquivo manualmente")
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, out, 0644)
+}
+
+func editSourceJSON(data []byte, id string, marks []sourceField) ([]byte, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	kept := items[:0]
+	for _, item := range items {
+		var head struct {
+			ID string `json:"id"`
+		}
+		json.Unmarshal(item, &head)
+		switch {
+		case head.ID != id:
+			kept = append(kept, item)
+		case len(marks) > 0:
+			edited, err := setJSONFields(item, marks)
+			if err != nil {
+				return nil, err
+			}
+			kept = append(kept, edited)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // URLs com & ficam legíveis, como no arquivo original
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(kept); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func editSourceYAML(data []byte, id string, marks []sourceField) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("esperada uma lista de fontes")
+	}
+	seq := doc.Content[0]
+
+	kept := seq.Content[:0]
+	for _, item := range seq.Content {
+		if yamlMapValue(item, "id") != id {
+			kept = append(kept, item)
+			continue
+		}
+		if len(marks) == 0 {
+			continue
+		}
+		for _, field := range marks {
+			var value yaml.Node
+			if err := value.Encode(field.Value); err != nil {
+				return nil, err
+			}
+			setYAMLMapValue(item, field.Key, &value)
+		}
+		kept = append(kept, item)
+	}
+	seq.Content = kept
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	enc.Close()
+	return buf.Bytes(), nil
+}
+
+// setJSONFields grava as chaves no objeto: as existentes são substituídas no lugar e as
+// novas vão para o fim, sem reordenar as demais.
+func setJSONFields(item json.RawMessage, fields []sourceField) (json.RawMessage, error) {
+	type pair struct {
+		key   string
+		value json.RawMessage
+	}
+	var pairs []pair
+	dec := json.NewDecoder(bytes.NewReader(item))
+	if _, err := dec.Token(); err != nil { // {
+		return nil, err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair{tok.(string), value})
+	}
+
+	for _, field := range fields {
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for i := range pairs {
+			if pairs[i].key == field.Key {
+				pairs[i].value, found = value, true
+			}
+		}
+		if !found {
+			pairs = append(pairs, pair{field.Key, value})
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, p := range pairs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(p.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(p.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// yamlMapValue devolve o valor escalar de key num mapeamento YAML ("" se ausente).
+func yamlMapValue(node *yaml.Node, key string) string {
+	if node.Kind != yaml.MappingNode {
+		return ""
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+// setYAMLMapValue substitui o valor de key no mapeamento (mantendo o comentário da linha) ou,
+// se ausente, acrescenta o par no fim.
+func setYAMLMapValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			value.LineComment = node.Content[i+1].LineComment
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
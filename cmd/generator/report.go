@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+// ==========================================
+// RELATÓRIO DA EXECUÇÃO
+// ==========================================
+
+// RunReport é o relatório estruturado de uma execução, para automações e dashboards.
+type RunReport struct {
+	StartedAt  time.Time   `json:"started_at"`
+	FinishedAt time.Time   `json:"finished_at"`
+	DurationMs int64       `json:"duration_ms"`
+	Summary    RunSummary  `json:"summary"`
+	Apps       []AppReport `json:"apps"`
+}
+
+type RunSummary struct {
+	Total           int   `json:"total"`
+	Updated         int   `json:"updated"`
+	Pending         int   `json:"pending"`
+	Skipped         int   `json:"skipped"`
+	Failed          int   `json:"failed"`
+	Orphaned        int   `json:"orphaned"`
+	Removed         int   `json:"removed"`
+	BytesDownloaded int64 `json:"bytes_downloaded"`
+}
+
+type AppReport struct {
+	ID              string `json:"id"`
+	Status          string `json:"status"` // "updated", "pending", "skipped", "failed", "orphaned", "removed"
+	OldVersion      string `json:"old_version,omitempty"`
+	NewVersion      string `json:"new_version,omitempty"`
+	Downgrade       bool   `json:"downgrade,omitempty"` // Versão nova menor que a anterior (version_scheme)
+	Failures        int    `json:"consecutive_failures,omitempty"`
+	Stale           bool   `json:"stale,omitempty"`
+	BreakerOpen     bool   `json:"breaker_open,omitempty"` // Não checado: circuit breaker aberto
+	BytesDownloaded int64  `json:"bytes_downloaded"`
+	DurationMs      int64  `json:"duration_ms"`
+	Error           string `json:"error,omitempty"`
+	ErrorClass      string `json:"error_class,omitempty"`       // "html_response", "robots_disallowed", "checksum_mismatch" ou "timeout"
+	Mirror          string `json:"mirror,omitempty"`            // Mirror usado porque a origem falhou
+	Fallback        string `json:"fallback_strategy,omitempty"` // Estratégia de reserva usada porque a principal falhou
+}
+
+// buildReport consolida os resultados (na ordem das fontes) num RunReport.
+func buildReport(startedAt time.Time, results []appResult) RunReport {
+	finished := time.Now()
+	report := RunReport{
+		StartedAt:  startedAt,
+		FinishedAt: finished,
+		DurationMs: finished.Sub(startedAt).Milliseconds(),
+		Apps:       make([]AppReport, 0, len(results)),
+	}
+
+	for _, res := range results {
+		entry := AppReport{
+			ID:              res.ID,
+			Status:          res.Status(),
+			OldVersion:      res.OldVersion,
+			NewVersion:      res.NewVersion,
+			Downgrade:       res.Downgrade,
+			Failures:        res.App.ConsecutiveFailures,
+			Stale:           res.App.Stale,
+			BreakerOpen:     res.BreakerOpen,
+			BytesDownloaded: res.BytesDownloaded,
+			DurationMs:      res.Duration.Milliseconds(),
+			Mirror:          res.Mirror,
+			Fallback:        res.Fallback,
+		}
+		if res.Err != nil {
+			entry.Error, entry.ErrorClass = res.Err.Error(), errorClass(res)
+		}
+		report.Apps = append(report.Apps, entry)
+
+		report.Summary.Total++
+		report.Summary.BytesDownloaded += res.BytesDownloaded
+		switch entry.Status {
+		case "updated":
+			report.Summary.Updated++
+		case "pending":
+			report.Summary.Pending++
+		case "failed":
+			report.Summary.Failed++
+		case "orphaned":
+			report.Summary.Orphaned++
+		case "removed":
+			report.Summary.Removed++
+		default:
+			report.Summary.Skipped++
+		}
+	}
+
+	return report
+}
+
+func writeReport(path string, report RunReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// errorClass classifica a falha de um app para o relatório, para que automações tratem cada
+// caso sem interpretar a mensagem ("" quando não há classe específica).
+func errorClass(res appResult) string {
+	switch {
+	case res.Err == nil:
+		return ""
+	case errors.Is(res.Err, errHTMLResponse):
+		return "html_response"
+	case errors.Is(res.Err, errRobotsDisallowed):
+		return "robots_disallowed"
+	case res.ChecksumMismatch:
+		return "checksum_mismatch"
+	case errors.Is(res.Err, context.DeadlineExceeded):
+		return "timeout"
+	}
+	return ""
+}
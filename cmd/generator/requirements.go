@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// ==========================================
+// REQUISITOS DO SISTEMA
+// ==========================================
+
+// Requirements diz o que a máquina precisa ter para instalar a versão publicada, para que
+// instaladores recusem uma atualização incompatível antes de baixá-la. Os campos declarados
+// na fonte prevalecem; os ausentes são lidos do próprio pacote quando possível (Depends e
+// libc6 do .deb, Requires e símbolos GLIBC_ do .rpm, LSMinimumSystemVersion do Info.plist).
+type Requirements struct {
+	MinOSVersion string   `json:"min_os_version,omitempty"` // Versão mínima do sistema (ex: 12.0 no macOS, 10.0.19041 no Windows)
+	Glibc        string   `json:"glibc,omitempty"`          // Versão mínima da glibc (ex: 2.31)
+	Depends      []string `json:"depends,omitempty"`        // Dependências de runtime, na sintaxe do gerenciador de pacotes
+}
+
+func (r *Requirements) empty() bool {
+	return r == nil || (r.MinOSVersion == "" && r.Glibc == "" && len(r.Depends) == 0)
+}
+
+// over devolve base com os campos preenchidos em r por cima (nil se nada sobrar).
+func (r *Requirements) over(base *Requirements) *Requirements {
+	var merged Requirements
+	if base != nil {
+		merged = *base
+	}
+	if r != nil {
+		if r.MinOSVersion != "" {
+			merged.MinOSVersion = r.MinOSVersion
+		}
+		if r.Glibc != "" {
+			merged.Glibc = r.Glibc
+		}
+		if len(r.Depends) > 0 {
+			merged.Depends = r.Depends
+		}
+	}
+	if merged.empty() {
+		return nil
+	}
+	return &merged
+}
+
+// requirements lê os requisitos embutidos no artefato (nil se o formato não os traz).
+func (s *pkgMetaSniffer) requirements() *Requirements {
+	var req *Requirements
+	switch {
+	case s.isDeb():
+		if control, err := debControl(s.head); err == nil {
+			req = debRequirements(control)
+		}
+	case s.isRPM():
+		if tags, err := rpmHeader(s.head); err == nil {
+			req = rpmRequirements(tags)
+		}
+	case bytes.HasPrefix(s.head, []byte("PK\x03\x04")):
+		if plist, err := zipInfoPlist(s.plist.hits); err == nil {
+			if values, err := plistValues(plist); err == nil {
+				req = &Requirements{MinOSVersion: values["LSMinimumSystemVersion"]}
+			}
+		}
+	}
+	if req.empty() {
+		return nil
+	}
+	return req
+}
+
+// libc6Depends casa "libc6 (>= 2.31)" (também com arquitetura, ex: libc6:amd64).
+var libc6Depends = regexp.MustCompile(`^libc6(?::\S+)?\s*\(\s*>=\s*([0-9]+\.[0-9]+)`)
+
+// debRequirements lê Pre-Depends e Depends do control; a glibc mínima vem de libc6.
+func debRequirements(control map[string]string) *Requirements {
+	req := &Requirements{}
+	for _, field := range []string{"Pre-Depends", "Depends"} {
+		for _, dep := range strings.Split(control[field], ",") {
+			dep = strings.TrimSpace(dep)
+			if dep == "" {
+				continue
+			}
+			req.Depends = append(req.Depends, dep)
+			for _, alt := range strings.Split(dep, "|") {
+				if m := libc6Depends.FindStringSubmatch(strings.TrimSpace(alt)); m != nil {
+					req.Glibc = newerVersion(req.Glibc, m[1])
+				}
+			}
+		}
+	}
+	return req
+}
+
+// glibcSymbol casa as dependências de símbolos versionados da glibc, ex: libc.so.6(GLIBC_2.34)(64bit).
+var glibcSymbol = regexp.MustCompile(`^libc\.so\.6\(GLIBC_([0-9]+\.[0-9]+)`)
+
+// Bits de RPMTAG_REQUIREFLAGS que formam o operador da comparação.
+const (
+	rpmSenseLess    = 0x02
+	rpmSenseGreater = 0x04
+	rpmSenseEqual   = 0x08
+)
+
+// rpmRequirements lê os Requires do header. Bibliotecas (sonames), caminhos e as capacidades
+// internas do rpm (rpmlib, config) ficam de fora de depends: o rpm as resolve sozinho e elas
+// não dizem nada a um instalador; dos sonames da glibc sai a versão mínima.
+func rpmRequirements(tags map[uint32][]string) *Requirements {
+	req := &Requirements{}
+	names, flags, versions := tags[rpmTagRequireName], tags[rpmTagRequireFlags], tags[rpmTagRequireVersion]
+	for i, name := range names {
+		if m := glibcSymbol.FindStringSubmatch(name); m != nil {
+			req.Glibc = newerVersion(req.Glibc, m[1])
+			continue
+		}
+		if strings.HasPrefix(name, "rpmlib(") || strings.HasPrefix(name, "config(") || strings.HasPrefix(name, "/") || strings.Contains(name, ".so") {
+			continue
+		}
+		dep := name
+		if i < len(versions) && versions[i] != "" && i < len(flags) {
+			var sense int
+			for _, c := range flags[i] {
+				sense = sense*10 + int(c-'0')
+			}
+			op := ""
+			if sense&rpmSenseLess != 0 {
+				op += "<"
+			}
+			if sense&rpmSenseGreater != 0 {
+				op += ">"
+			}
+			if sense&rpmSenseEqual != 0 {
+				op += "="
+			}
+			if op != "" {
+				dep += " " + op + " " + versions[i]
+			}
+		}
+		req.Depends = append(req.Depends, dep)
+	}
+	return req
+}
+
+// newerVersion devolve a maior entre a e b (a pode ser vazia).
+func newerVersion(a, b string) string {
+	if a == "" || compareVersions(schemeDebian, b, a) > 0 {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ==========================================
+// ROBOTS.TXT (RESPECT_ROBOTS)
+// ==========================================
+
+// Com respect_robots, as estratégias que raspam páginas ou leem feeds de sites comuns
+// consultam o robots.txt do host da url configurada antes da checagem (RFC 9309) e respeitam o
+// Crawl-delay entre checagens ao mesmo host. APIs de registries (crates, maven...) têm regras
+// próprias de uso e ficam de fora. A estratégia script não está na lista porque a consulta é
+// feita a cada http.get/http.head do script (ver scriptHTTP), e não só para a url da fonte.
+
+// robotsStrategies são as estratégias sujeitas ao robots.txt.
+var robotsStrategies = map[string]bool{
+	"direct_url_head": true,
+	"direct_static":   true,
+	"browser":         true,
+	"electron":        true,
+	"squirrel":        true,
+	"appinstaller":    true,
+}
+
+// robotsAgent é o token do gerador nas linhas User-agent.
+const robotsAgent = "updater-registry"
+
+// robotsMaxAge é a validade do robots.txt em cache (o modo servidor roda indefinidamente).
+const robotsMaxAge = 24 * time.Hour
+
+var errRobotsDisallowed = errors.New("bloqueado pelo robots.txt")
+
+type robotsRule struct {
+	allow   bool
+	length  int // Tamanho do padrão: a regra mais específica vence
+	pattern *regexp.Regexp
+}
+
+// robotsHost guarda o robots.txt de um host e serializa as checagens a ele (Crawl-delay).
+type robotsHost struct {
+	mu      sync.Mutex
+	fetched time.Time
+	rules   []robotsRule
+	delay   time.Duration
+	last    time.Time // Última checagem liberada para o host
+}
+
+var robotsHosts sync.Map // "esquema://host" -> *robotsHost
+
+// robotsAllow confere se o robots.txt do host permite a url e espera o Crawl-delay desde a
+// última checagem ao mesmo host. Um robots.txt ausente (4xx) libera tudo; um inacessível (5xx,
+// erro de rede) bloqueia, como manda a RFC.
+func robotsAllow(ctx context.Context, conf map[string]string, rawURL string) error {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil // FTP, arquivos locais...
+	}
+	value, _ := robotsHosts.LoadOrStore(u.Scheme+"://"+u.Host, &robotsHost{})
+	host := value.(*robotsHost)
+
+	host.mu.Lock()
+	defer host.mu.Unlock()
+	if time.Since(host.fetched) > robotsMaxAge {
+		rules, delay, err := fetchRobots(ctx, conf, u)
+		if err != nil {
+			return fmt.Errorf("robots.txt de %s inacessível: %w", u.Host, err)
+		}
+		host.rules, host.delay, host.fetched = rules, delay, time.Now()
+	}
+
+	target := u.EscapedPath()
+	if target == "" {
+		target = "/"
+	}
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+	if !robotsAllowed(host.rules, target) {
+		return fmt.Errorf("%w: %s", errRobotsDisallowed, rawURL)
+	}
+
+	if wait := time.Until(host.last.Add(host.delay)); wait > 0 {
+		verbosef("     robots.txt: aguardando o Crawl-delay de %s (%s)", u.Host, wait.Round(time.Second))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	host.last = time.Now()
+	return nil
+}
+
+// fetchRobots baixa e interpreta o robots.txt do host de u.
+func fetchRobots(ctx context.Context, conf map[string]string, u *neturl.URL) ([]robotsRule, time.Duration, error) {
+	transport, err := sourceTransport(conf)
+	if err != nil {
+		return nil, 0, err
+	}
+	robotsURL := u.Scheme + "://" + u.Host + "/robots.txt"
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("User-Agent", "updater-registry (+https://github.com/luizhanauer/updater-registry)")
+	debugf("     GET %s", robotsURL)
+
+	client := &http.Client{Timeout: time.Duration(cfg.HTTPTimeout), Transport: transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 500:
+		return nil, 0, fmt.Errorf("status %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return nil, 0, nil // Sem robots.txt: tudo liberado
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 500<<10)) // A RFC exige ler ao menos 500 KiB
+	if err != nil {
+		return nil, 0, err
+	}
+	rules, delay := parseRobots(body, robotsAgent)
+	return rules, delay, nil
+}
+
+// parseRobots extrai as regras e o Crawl-delay que valem para agent: os grupos que citam o
+// agente ou, se nenhum citar, os de "*".
+func parseRobots(body []byte, agent string) ([]robotsRule, time.Duration) {
+	type group struct {
+		agents []string
+		rules  []robotsRule
+		delay  time.Duration
+	}
+	var groups []*group
+	var current *group
+	inAgents := false // Linhas User-agent seguidas formam um mesmo grupo
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if !inAgents || current == nil {
+				current = &group{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			inAgents = true
+			continue
+		case "allow", "disallow":
+			if current != nil && value != "" {
+				current.rules = append(current.rules, robotsRule{allow: key == "allow", length: len(value), pattern: robotsPattern(value)})
+			}
+		case "crawl-delay":
+			if current != nil {
+				if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+					current.delay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		}
+		inAgents = false
+	}
+
+	agent = strings.ToLower(agent)
+	for _, wanted := range []string{agent, "*"} {
+		var rules []robotsRule
+		var delay time.Duration
+		matched := false
+		for _, g := range groups {
+			for _, a := range g.agents {
+				if a == wanted {
+					matched = true
+					rules = append(rules, g.rules...)
+					delay = max(delay, g.delay)
+					break
+				}
+			}
+		}
+		if matched {
+			return rules, delay
+		}
+	}
+	return nil, 0
+}
+
+// robotsPattern compila um caminho do robots.txt: "*" casa qualquer sequência e um "$" final
+// ancora no fim da URL.
+func robotsPattern(path string) *regexp.Regexp {
+	anchored := strings.HasSuffix(path, "$")
+	path = strings.TrimSuffix(path, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(path), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// robotsAllowed aplica as regras a target (caminho e query): vence a regra de padrão mais
+// longo que casa e, no empate, Allow. /robots.txt é sempre liberado.
+func robotsAllowed(rules []robotsRule, target string) bool {
+	if target == "/robots.txt" {
+		return true
+	}
+	best, allowed := -1, true
+	for _, rule := range rules {
+		if !rule.pattern.MatchString(target) {
+			continue
+		}
+		if rule.length > best || (rule.length == best && rule.allow) {
+			best, allowed = rule.length, rule.allow
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRobotsAllowed(t *testing.T) {
+	robots := `
+User-agent: *
+Disallow: /
+
+User-agent: updater-registry
+Disallow: /downloads/
+Allow: /downloads/public/
+Disallow: /*.php$
+Allow: /page
+Disallow: /page
+Crawl-delay: 2
+`
+	rules, delay := parseRobots([]byte(robots), robotsAgent)
+	if delay != 2*time.Second {
+		t.Errorf("Crawl-delay = %s, esperado 2s", delay)
+	}
+
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"/", true},                         // O grupo do agente substitui o de "*"
+		{"/downloads/app.deb", false},       // Disallow
+		{"/downloads/public/app.deb", true}, // Allow mais longo vence
+		{"/downloads/public", false},        // Não casa o Allow (falta a barra)
+		{"/index.php", false},               // "*" e "$"
+		{"/index.php?x=1", true},            // "$" ancora no fim da URL
+		{"/page", true},                     // Empate: Allow vence
+		{"/robots.txt", true},               // Sempre liberado
+		{"/Downloads/app.deb", true},        // Caminhos diferenciam maiúsculas
+	}
+	for _, tt := range tests {
+		if got := robotsAllowed(rules, tt.target); got != tt.want {
+			t.Errorf("robotsAllowed(%q) = %v, esperado %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestParseRobotsGroups(t *testing.T) {
+	tests := []struct {
+		name   string
+		robots string
+		target string
+		want   bool
+	}{
+		{"sem grupos", "", "/x", true},
+		{"só curinga", "User-agent: *\nDisallow: /x", "/x", false},
+		{"agente sem diferenciar maiúsculas", "User-agent: Updater-Registry\nDisallow: /x\n\nUser-agent: *\nAllow: /", "/x", false},
+		{"user-agent seguidos formam um grupo", "User-agent: outro\nUser-agent: updater-registry\nDisallow: /x", "/x", false},
+		{"grupos do agente se somam", "User-agent: updater-registry\nDisallow: /a\n\nUser-agent: updater-registry\nDisallow: /b", "/b", false},
+		{"outro agente não vale", "User-agent: outro\nDisallow: /", "/x", true},
+		{"disallow vazio libera", "User-agent: *\nDisallow:", "/x", true},
+		{"comentários", "User-agent: * # todos\nDisallow: /x # privado", "/x", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, _ := parseRobots([]byte(tt.robots), robotsAgent)
+			if got := robotsAllowed(rules, tt.target); got != tt.want {
+				t.Errorf("robotsAllowed(%q) = %v, esperado %v", tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+// Na estratégia script, cada URL pedida pelo script passa pelo robots.txt.
+func TestCheckScriptRespectsRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private/\n")
+			return
+		}
+		fmt.Fprint(w, `{"version": "1.0.0"}`)
+	}))
+	defer server.Close()
+
+	saved := cfg
+	t.Cleanup(func() { cfg = saved })
+	cfg.RespectRobots = true
+
+	script := func(path string) SourceApp {
+		return SourceApp{ID: "foo", Strategy: "script", Config: ConfigMap{"script": fmt.Sprintf(`
+def check(config):
+    resp = http.get("%s%s")
+    return {"version": json.decode(resp.body)["version"], "url": "%s/foo.deb"}
+`, server.URL, path, server.URL)}}
+	}
+
+	if _, err := checkScript(context.Background(), script("/latest.json")); err != nil {
+		t.Errorf("URL liberada: %v", err)
+	}
+	_, err := checkScript(context.Background(), script("/private/latest.json"))
+	if !errors.Is(err, errRobotsDisallowed) {
+		t.Errorf("erro = %v, esperado bloqueio do robots.txt", err)
+	}
+}
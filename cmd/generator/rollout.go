@@ -0,0 +1,76 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ==========================================
+// LIBERAÇÃO GRADUAL (ROLLOUT_PERCENT)
+// ==========================================
+
+// Uma entrada com rollout_percent entre 1 e 99 só deve ser oferecida como atualização a essa
+// fração dos clientes. Cada cliente cai num balde fixo de 0 a 99, calculado a partir de um
+// client_id aleatório e persistente (o mesmo da telemetria) e recebe a versão nova se o balde
+// for menor que a porcentagem:
+//
+//	balde = uint32 big-endian dos 4 primeiros bytes de SHA-256("<client_id>:<app_id>:<versão>") % 100
+//
+// O app e a versão entram no hash para que os mesmos clientes não sejam sempre os primeiros de
+// todo lançamento, e aumentar a porcentagem só acrescenta clientes: quem já recebeu continua
+// dentro. Este arquivo é a implementação de referência dos clientes.
+
+// rolloutBucket devolve o balde (0 a 99) do cliente para a versão do app.
+func rolloutBucket(clientID, appID, version string) int {
+	sum := sha256.Sum256([]byte(clientID + ":" + appID + ":" + version))
+	return int(binary.BigEndian.Uint32(sum[:4]) % 100)
+}
+
+// offeredTo diz se a versão publicada deve ser oferecida ao cliente. Sem client_id, o cliente
+// não participa da liberação gradual e espera a versão chegar a 100%.
+func (app CatalogApp) offeredTo(clientID string) bool {
+	if app.RolloutPercent <= 0 || app.RolloutPercent >= 100 {
+		return true
+	}
+	if clientID == "" {
+		return false
+	}
+	return rolloutBucket(clientID, app.ID, app.Version) < app.RolloutPercent
+}
+
+// handleRollout atende GET /api/v1/apps/{id}/rollout?client_id=..., para clientes que preferem
+// não calcular o balde (scripts de shell, por exemplo) e para conferir uma implementação.
+func handleRollout(cache *catalogCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		clientID := strings.TrimSpace(r.URL.Query().Get("client_id"))
+		if clientID == "" || len(clientID) > telemetryMaxFieldBytes {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("client_id é obrigatório (até %d bytes)", telemetryMaxFieldBytes))
+			return
+		}
+		catalog, err := cache.get()
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		app, ok := catalog.lookup(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "app não encontrado")
+			return
+		}
+
+		percent := app.RolloutPercent
+		if percent <= 0 || percent > 100 {
+			percent = 100
+		}
+		writeJSON(w, http.StatusOK, RolloutStatus{
+			App:            app.ID,
+			Version:        app.Version,
+			RolloutPercent: percent,
+			Bucket:         rolloutBucket(clientID, app.ID, app.Version),
+			Eligible:       app.offeredTo(clientID),
+		})
+	}
+}
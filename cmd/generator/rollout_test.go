@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Valores de referência para os clientes que reimplementam o cálculo.
+func TestRolloutBucket(t *testing.T) {
+	tests := []struct {
+		clientID, appID, version string
+		want                     int
+	}{
+		{"client-1", "foo", "1.2.3", 43},
+		{"client-2", "foo", "1.2.3", 6},
+		{"client-1", "foo", "1.2.4", 61},
+		{"client-1", "bar", "1.2.3", 9},
+		{"", "foo", "1.0", 22},
+	}
+	for _, tt := range tests {
+		for range 2 { // Estável entre chamadas
+			if got := rolloutBucket(tt.clientID, tt.appID, tt.version); got != tt.want {
+				t.Errorf("rolloutBucket(%q, %q, %q) = %d, esperado %d", tt.clientID, tt.appID, tt.version, got, tt.want)
+			}
+		}
+	}
+}
+
+// A fração de clientes que recebe a versão acompanha a porcentagem, e aumentá-la só acrescenta
+// clientes.
+func TestRolloutDistribution(t *testing.T) {
+	const clients = 20000
+	buckets := make([]int, clients)
+	for i := range buckets {
+		buckets[i] = rolloutBucket(fmt.Sprintf("client-%d", i), "foo", "1.2.3")
+	}
+
+	for _, percent := range []int{1, 5, 10, 25, 50, 75, 90, 99} {
+		offered := 0
+		for _, bucket := range buckets {
+			if bucket < percent {
+				offered++
+			}
+		}
+		got := float64(offered) * 100 / clients
+		if diff := got - float64(percent); diff < -1.5 || diff > 1.5 {
+			t.Errorf("rollout de %d%%: %.2f%% dos clientes", percent, got)
+		}
+	}
+
+	for i, bucket := range buckets {
+		app := CatalogApp{ID: "foo", Version: "1.2.3"}
+		clientID := fmt.Sprintf("client-%d", i)
+		previous := false
+		for percent := 1; percent <= 100; percent++ {
+			app.RolloutPercent = percent
+			offered := app.offeredTo(clientID)
+			if previous && !offered {
+				t.Fatalf("%s (balde %d) saiu do rollout ao subir para %d%%", clientID, bucket, percent)
+			}
+			previous = offered
+		}
+	}
+}
+
+func TestOfferedTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		percent  int
+		clientID string
+		want     bool
+	}{
+		{"sem rollout", 0, "", true},
+		{"100%", 100, "", true},
+		{"sem client_id espera os 100%", 99, "", false},
+		{"balde dentro", 44, "client-1", true}, // Balde 43
+		{"balde no limite", 43, "client-1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := CatalogApp{ID: "foo", Version: "1.2.3", RolloutPercent: tt.percent}
+			if got := app.offeredTo(tt.clientID); got != tt.want {
+				t.Errorf("offeredTo(%q) = %v, esperado %v", tt.clientID, got, tt.want)
+			}
+		})
+	}
+}
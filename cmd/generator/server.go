@@ -0,0 +1,433 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ==========================================
+// MODO SERVIDOR
+// ==========================================
+
+// openAPISpec é o contrato das rotas abaixo, servido em /openapi.json. Rotas novas ou
+// mudanças de formato começam por ele: os corpos da API são gerados dele (api.gen.go), o
+// server_test.go confere as rotas e os tipos do catálogo, escritos à mão.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+//go:generate go tool oapi-codegen -config oapi-codegen.yaml openapi.json
+
+// routeMux é onde as rotas são registradas: o *http.ServeMux do servidor ou, nos testes, um
+// registro das rotas para conferi-las com o openapi.json.
+type routeMux interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// ServerConfig ajusta o subcomando "serve", que expõe o catálogo gerado por HTTP.
+type ServerConfig struct {
+	Listen         string   `json:"listen"`          // Endereço de escuta (padrão: :8080)
+	ReloadInterval Duration `json:"reload_interval"` // De quanto em quanto tempo reler o catálogo (padrão: 1m)
+	MaxCatalogAge  Duration `json:"max_catalog_age"` // /readyz falha com um catálogo gerado há mais que isso (0 = não conferir)
+	ShutdownGrace  Duration `json:"shutdown_grace"`  // Quanto esperar as requisições em andamento no SIGTERM (padrão: 30s)
+
+	TLS  ServerTLSConfig `json:"tls"`  // HTTPS direto no servidor (padrão: HTTP simples)
+	CORS CORSConfig      `json:"cors"` // Origens liberadas para dashboards no navegador (padrão: nenhuma)
+
+	Telemetry TelemetryConfig `json:"telemetry"` // Relatos opt-in dos clientes e estatísticas de adoção (padrão: desativado)
+
+	RateLimit RateLimitConfig `json:"rate_limit"` // Limite de requisições por IP e por chave (padrão: sem limite)
+	Proxy     ProxyConfig     `json:"proxy"`      // GET /download/{id} com cache local dos artefatos (padrão: desativado)
+
+	// Chaves e papéis da raiz; admin_token_env tem como padrão UPDATER_ADMIN_TOKEN
+	AccessConfig
+
+	// Catálogos adicionais, servidos em /t/{name}/ com as mesmas rotas da raiz
+	Tenants []TenantConfig `json:"tenants"`
+}
+
+// TenantConfig é um catálogo de outro produto ou time hospedado no mesmo servidor, com
+// fontes e chaves próprias. Sem chave admin, as rotas administrativas dele ficam
+// desativadas: as chaves da raiz não valem para os tenants.
+type TenantConfig struct {
+	Name    string   `json:"name"`
+	Catalog string   `json:"catalog"` // catalog.json do tenant (local, s3://, gs:// ou az://)
+	Sources []string `json:"sources"` // Fontes usadas pelo refresh (vazio = refresh indisponível)
+	Pending string   `json:"pending"` // Pendências do tenant (vazio = refresh publica direto)
+	Staging string   `json:"staging"` // Staging promovido por POST /api/v1/promote (vazio = rota indisponível)
+
+	AccessConfig
+}
+
+// servedCatalog é um catálogo servido (a raiz ou um tenant) com o estado que vive junto dele.
+type servedCatalog struct {
+	tenant    string // "" na raiz
+	cache     *catalogCache
+	sources   []string        // Conferidas no SIGHUP
+	telemetry *telemetryStore // nil sem server.telemetry.enabled
+	downloads *downloadStore  // nil sem server.proxy.enabled
+}
+
+// persistedState é um estado do servidor mantido em memória e gravado a cada
+// stateFlushInterval e no encerramento (telemetria, contagem de downloads).
+type persistedState interface {
+	flush() error
+	location() string
+}
+
+// stateFlushInterval é de quanto em quanto tempo gravar os estados alterados.
+const stateFlushInterval = 30 * time.Second
+
+// states devolve os estados gravados deste catálogo.
+func (s servedCatalog) states() []persistedState {
+	var states []persistedState
+	if s.telemetry != nil {
+		states = append(states, s.telemetry)
+	}
+	if s.downloads != nil {
+		states = append(states, s.downloads)
+	}
+	return states
+}
+
+// rootTenant descreve o catálogo da raiz com a configuração global.
+func rootTenant() TenantConfig {
+	access := cfg.Server.AccessConfig
+	if access.AdminTokenEnv == "" {
+		access.AdminTokenEnv = "UPDATER_ADMIN_TOKEN"
+	}
+	return TenantConfig{Catalog: cfg.CatalogPath, Sources: cfg.Sources, Pending: cfg.PendingPath, Staging: cfg.StagingPath, AccessConfig: access}
+}
+
+// catalogCache mantém o catálogo em memória e o relê de path quando expira, para que um
+// "generate" rodando em paralelo (cron, CI) seja refletido sem reiniciar. Um catálogo em disco
+// também é relido assim que o arquivo muda, e qualquer um no SIGHUP (ver invalidate).
+type catalogCache struct {
+	path     string
+	mu       sync.Mutex
+	catalog  Catalog
+	loadedAt time.Time
+	modTime  time.Time // Modificação do arquivo local na última leitura
+	expired  bool
+}
+
+func (c *catalogCache) get() (Catalog, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	modTime := c.localModTime()
+	fresh := time.Since(c.loadedAt) < time.Duration(cfg.Server.ReloadInterval) && modTime.Equal(c.modTime)
+	if !c.loadedAt.IsZero() && !c.expired && fresh {
+		return c.catalog, nil
+	}
+
+	catalog, err := readCatalog(c.path)
+	if err != nil {
+		// Mantém a última cópia boa se o backend falhar momentaneamente
+		if !c.loadedAt.IsZero() {
+			errorf(" [ERRO] Falha ao reler o catálogo (usando cópia em memória): %v", err)
+			return c.catalog, nil
+		}
+		return Catalog{}, err
+	}
+
+	c.catalog, c.loadedAt, c.modTime, c.expired = catalog, time.Now(), modTime, false
+	debugf("     catálogo recarregado (%d apps)", len(catalog.Apps))
+	return catalog, nil
+}
+
+// localModTime é a data de modificação do catálogo em disco (zero nos backends remotos, que só
+// são relidos quando o reload_interval expira). O gerador grava com rename atômico, então um
+// arquivo novo nunca é lido pela metade.
+func (c *catalogCache) localModTime() time.Time {
+	path, ok := localPath(c.path)
+	if !ok {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// invalidate força a releitura na próxima consulta, mantendo a cópia atual caso ela falhe.
+func (c *catalogCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expired = true
+}
+
+// set substitui a cópia em memória (após um refresh gravar o catálogo).
+func (c *catalogCache) set(catalog Catalog) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.catalog, c.loadedAt = catalog, time.Now()
+}
+
+// runServe sobe o servidor HTTP e só retorna no SIGTERM/Ctrl+C, depois de terminar as
+// requisições em andamento, ou em caso de erro fatal.
+func runServe() {
+	mux := http.NewServeMux()
+	metrics := newServerMetrics()
+	var limiter *rateLimiter
+	if limits := cfg.Server.RateLimit; limits.PerIP > 0 || limits.PerKey > 0 || hasKeyRateLimit() {
+		limiter = newRateLimiter(limits)
+	}
+	tenants := append([]TenantConfig{rootTenant()}, cfg.Server.Tenants...)
+	seen := make(map[string]bool)
+	for i, tenant := range tenants {
+		prefix := ""
+		if i > 0 {
+			if tenant.Name == "" || tenant.Catalog == "" || strings.Contains(tenant.Name, "/") || seen[tenant.Name] {
+				log.Fatalf("server.tenants[%d]: 'name' único (sem '/') e 'catalog' são obrigatórios", i-1)
+			}
+			seen[tenant.Name] = true
+			prefix = "/t/" + tenant.Name
+		}
+		if err := tenant.validate(); err != nil {
+			log.Fatalf("server%s: %v", prefix, err)
+		}
+		// readCatalog decifra (e, com private_only, junta os apps privados a) o catálogo de
+		// produção: servi-lo só é seguro com as leituras exigindo chave
+		if tenant.Catalog == cfg.CatalogPath && cfg.Encrypt.enabled() && !tenant.PrivateRead {
+			log.Fatalf("server%s: com encrypt, o catálogo é servido decifrado; ative private_read para exigir chave nas leituras", prefix)
+		}
+
+		cache := &catalogCache{path: tenant.Catalog}
+		if _, err := cache.get(); err != nil {
+			log.Fatalf("Falha ao carregar o catálogo %s: %v", tenant.Catalog, err)
+		}
+		served := servedCatalog{tenant: tenant.Name, cache: cache, sources: tenant.Sources}
+		if cfg.Server.Telemetry.Enabled {
+			var err error
+			if served.telemetry, err = newTelemetryStore(tenant.Name); err != nil {
+				log.Fatalf("Falha ao carregar a telemetria: %v", err)
+			}
+		}
+		if cfg.Server.Proxy.Enabled {
+			var err error
+			if served.downloads, err = newDownloadStore(tenant.Name); err != nil {
+				log.Fatalf("Falha ao carregar a contagem de downloads: %v", err)
+			}
+		}
+		metrics.catalogs = append(metrics.catalogs, served)
+		registerRoutes(mux, prefix, tenant, served, limiter, metrics)
+		infof(">>> Servindo %s em %s%s/", tenant.Catalog, cfg.Server.Listen, prefix)
+	}
+
+	registerSpecRoute(mux, limiter)
+	registerHealthRoutes(mux, metrics, tenants[0].AccessConfig)
+
+	server := &http.Server{Addr: cfg.Server.Listen, Handler: metrics.instrument(withCORS(cfg.Server.CORS, mux))}
+	var redirect *http.Server
+	if cfg.Server.TLS.enabled() {
+		var err error
+		if redirect, err = configureTLS(server, cfg.Server.TLS); err != nil {
+			log.Fatal(err)
+		}
+	}
+	go reloadOnHangup(metrics.catalogs)
+	var states []persistedState
+	for _, served := range metrics.catalogs {
+		states = append(states, served.states()...)
+	}
+	flushing := make(chan struct{})
+	go flushLoop(states, flushing)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errs := make(chan error, 2)
+	go func() {
+		if server.TLSConfig != nil {
+			errs <- server.ListenAndServeTLS("", "") // Certificados vêm de TLSConfig.GetCertificate
+			return
+		}
+		errs <- server.ListenAndServe()
+	}()
+	if redirect != nil {
+		infof(">>> Redirecionando HTTP em %s para HTTPS", redirect.Addr)
+		go func() { errs <- redirect.ListenAndServe() }()
+	}
+	select {
+	case err := <-errs:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+
+	// Para de aceitar conexões e espera as requisições em andamento (downloads do proxy,
+	// refresh e promoção, que gravam o catálogo) até shutdown_grace
+	grace := time.Duration(cfg.Server.ShutdownGrace)
+	infof(">>> Encerrando: aguardando as requisições em andamento (até %s)...", grace)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	if redirect != nil {
+		redirect.Shutdown(shutdownCtx)
+	}
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		errorf(" [ERRO] Requisições interrompidas no encerramento: %v", err)
+		server.Close()
+	}
+
+	// Grava o que ainda está só em memória
+	close(flushing)
+	flushStates(states)
+	infof(">>> Servidor encerrado.")
+}
+
+// flushLoop grava os estados a cada stateFlushInterval até done fechar.
+func flushLoop(states []persistedState, done <-chan struct{}) {
+	if len(states) == 0 {
+		return
+	}
+	ticker := time.NewTicker(stateFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			flushStates(states)
+		}
+	}
+}
+
+func flushStates(states []persistedState) {
+	for _, state := range states {
+		if err := state.flush(); err != nil {
+			errorf(" [ERRO] Falha ao gravar %s: %v", state.location(), err)
+		}
+	}
+}
+
+// reloadOnHangup relê os catálogos e confere as fontes a cada SIGHUP, sem derrubar conexões:
+// um catálogo ou fonte com erro é só registrado no log, e a cópia anterior continua servida.
+func reloadOnHangup(catalogs []servedCatalog) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	for range hangup {
+		infof(">>> SIGHUP: relendo catálogos e fontes")
+		for _, served := range catalogs {
+			served.cache.invalidate()
+			if catalog, err := served.cache.get(); err == nil {
+				infof("     %s: %d apps", served.cache.path, len(catalog.Apps))
+			}
+			if len(served.sources) == 0 {
+				continue
+			}
+			if _, err := readSources(served.sources...); err != nil {
+				errorf(" [ERRO] Fontes de %s com erro (o refresh vai falhar até a correção): %v", served.cache.path, err)
+			}
+		}
+	}
+}
+
+// registerSpecRoute serve o openapi.json, global e público.
+func registerSpecRoute(mux routeMux, limiter *rateLimiter) {
+	mux.HandleFunc("GET /openapi.json", limiter.wrap("", AccessConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPISpec)
+	}))
+}
+
+// registerRoutes registra as rotas de um catálogo sob prefix ("" na raiz, /t/{name} nos tenants).
+func registerRoutes(mux routeMux, prefix string, tenant TenantConfig, served servedCatalog, limiter *rateLimiter, metrics *serverMetrics) {
+	cache := served.cache
+	guard := func(role string, handler http.HandlerFunc) http.HandlerFunc {
+		return limiter.wrap(prefix, tenant.AccessConfig, requireRole(tenant.AccessConfig, role, handler))
+	}
+	read := func(pattern string, handler http.HandlerFunc) {
+		mux.HandleFunc("GET "+prefix+pattern, guard(roleRead, handler))
+	}
+
+	read("/catalog.json", func(w http.ResponseWriter, r *http.Request) {
+		catalog, err := cache.get()
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, catalog)
+	})
+
+	read("/api/v1/apps", func(w http.ResponseWriter, r *http.Request) {
+		catalog, err := cache.get()
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		apps := make([]CatalogApp, 0, len(catalog.Apps))
+		for _, app := range catalog.Apps {
+			apps = append(apps, app)
+		}
+		sort.Slice(apps, func(i, j int) bool { return apps[i].ID < apps[j].ID })
+		writeJSON(w, http.StatusOK, apps)
+	})
+
+	read("/api/v1/apps/{id}", func(w http.ResponseWriter, r *http.Request) {
+		catalog, err := cache.get()
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		app, ok := catalog.lookup(r.PathValue("id")) // Ids antigos (aliases) resolvem para o atual
+		if !ok {
+			writeError(w, http.StatusNotFound, "app não encontrado")
+			return
+		}
+		writeJSON(w, http.StatusOK, app)
+	})
+
+	read("/api/v1/apps/{id}/rollout", handleRollout(cache))
+	read("/api/v1/apps/{id}/install-plan", handleInstallPlan(cache))
+
+	read("/badge/{file}", func(w http.ResponseWriter, r *http.Request) {
+		serveBadge(w, r, cache)
+	})
+
+	if served.downloads != nil {
+		proxy := newDownloadProxy(prefix, tenant, cache, metrics, served.downloads)
+		read("/download/{id}", proxy.handle)
+		read("/api/v1/downloads", served.downloads.handleStats(cache))
+		read("/api/v1/apps/{id}/downloads", served.downloads.handleStats(cache))
+	}
+
+	if served.telemetry != nil {
+		// Relatos contam como leitura: públicos, a menos que private_read exija uma chave
+		mux.HandleFunc("POST "+prefix+"/api/v1/telemetry", guard(roleRead, served.telemetry.handleReport(cache)))
+		read("/api/v1/adoption", served.telemetry.handleAdoption(cache))
+		read("/api/v1/apps/{id}/adoption", served.telemetry.handleAdoption(cache))
+	}
+
+	writes := &sync.Mutex{} // Refresh e promoção gravam o mesmo catálogo
+	refresher := &appRefresher{mu: writes, cache: cache, tenant: tenant}
+	mux.HandleFunc("POST "+prefix+"/api/v1/apps/{id}/refresh", guard(roleAdmin, refresher.handle))
+
+	if tenant.Staging != "" {
+		promoter := &catalogPromoter{mu: writes, cache: cache, tenant: tenant, root: prefix == ""}
+		mux.HandleFunc("POST "+prefix+"/api/v1/promote", guard(roleAdmin, promoter.handle))
+	}
+}
+
+// writeJSON responde com o valor serializado e o status informado.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// writeError responde no formato Error da especificação: {"error": "..."}.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
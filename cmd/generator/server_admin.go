@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ==========================================
+// MODO SERVIDOR: ROTAS ADMINISTRATIVAS
+// ==========================================
+
+// appRefresher re-executa a estratégia de um único app e grava o catálogo.
+// Os refreshes são serializados (entre si e com a promoção, pelo mesmo mu): cada um lê,
+// altera e grava o catálogo inteiro.
+type appRefresher struct {
+	mu     *sync.Mutex
+	cache  *catalogCache
+	tenant TenantConfig
+}
+
+func (a *appRefresher) handle(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	// As fontes são relidas a cada refresh, para refletir edições sem reiniciar o servidor
+	sources, err := readSources(a.tenant.Sources...)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var src SourceApp
+	found := false
+	for _, s := range sources {
+		if s.ID == id || slices.Contains(s.Aliases, id) {
+			src, found = s, true
+			break
+		}
+	}
+	if !found {
+		writeError(w, http.StatusNotFound, "fonte não encontrada")
+		return
+	}
+	id = src.ID // Um alias é atendido pelo id atual
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Lê direto do armazenamento (não do cache) para não sobrescrever um "generate" recente
+	catalog, err := readCatalog(a.tenant.Catalog)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	catalog.Aliases = aliasMap(sources)
+	migrateAliases(&catalog, catalog.Aliases)
+
+	// Com aprovação manual, o refresh também só retém a atualização
+	var pending PendingChanges
+	compare := catalog
+	if a.tenant.Pending != "" {
+		if pending, err = loadPending(a.tenant.Pending); err != nil {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		compare = pending.overlay(catalog)
+	}
+
+	// O refresh sob demanda não espera check_interval nem o circuit breaker (ver interval.go)
+	infof(">>> Refresh sob demanda: %s", id)
+	res := processApp(withForcedCheck(r.Context()), src, compare)
+	if a.tenant.Pending != "" {
+		res = pending.hold(res, catalog)
+		if err := savePending(a.tenant.Pending, pending); err != nil {
+			writeError(w, http.StatusInternalServerError, "falha ao gravar as pendências: "+err.Error())
+			return
+		}
+	}
+
+	if res.Checked {
+		trackFailures(&res) // Zera as falhas seguidas, a marca stale e o circuit breaker
+	}
+
+	resp := RefreshResult{ID: id, Status: RefreshResultStatus(res.Status()), OldVersion: res.OldVersion, NewVersion: res.NewVersion}
+	if res.Err != nil {
+		resp.Error = res.Err.Error()
+	}
+
+	if res.HasApp && (res.Changed || res.Checked) {
+		catalog.Apps[id] = res.App
+		catalog.LastUpdated = time.Now()
+		catalog.Stats = computeStats(catalog.Apps)
+		if err := writeCatalog(a.tenant.Catalog, catalog); err != nil {
+			writeError(w, http.StatusInternalServerError, "falha ao gravar o catálogo: "+err.Error())
+			return
+		}
+		a.cache.set(catalog)
+	}
+	if res.HasApp {
+		app := res.App
+		resp.App = &app
+	}
+
+	status := http.StatusOK
+	if res.Err != nil {
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, resp)
+}
+
+// catalogPromoter promove o staging do tenant pela API, como o subcomando promote.
+type catalogPromoter struct {
+	mu     *sync.Mutex
+	cache  *catalogCache
+	tenant TenantConfig
+	root   bool // Só o catálogo da raiz é publicado (IPFS, rsync) após a promoção
+}
+
+func (p *catalogPromoter) handle(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	force := r.URL.Query().Get("force") == "true"
+	catalog, issues, err := promoteCatalog(p.tenant.Staging, p.tenant.Catalog, force)
+	resp := PromoteResult{Issues: make([]PromoteIssue, 0, len(issues))}
+	for _, issue := range issues {
+		resp.Issues = append(resp.Issues, PromoteIssue{ID: issue.ID, Error: issue.Error, Message: issue.Message})
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		status := http.StatusInternalServerError
+		if errors.Is(err, errPromotionBlocked) {
+			status = http.StatusConflict
+		}
+		writeJSON(w, status, resp)
+		return
+	}
+
+	p.cache.set(catalog)
+	resp.Promoted, resp.Apps = true, len(catalog.Apps)
+	infof(">>> Staging %s promovido para %s pela API (%d apps).", p.tenant.Staging, p.tenant.Catalog, len(catalog.Apps))
+	if p.root && !publishProduction() {
+		resp.Error = "catálogo promovido, mas a publicação via rsync falhou"
+		writeJSON(w, http.StatusBadGateway, resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
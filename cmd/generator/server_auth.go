@@ -0,0 +1,130 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ==========================================
+// MODO SERVIDOR: CHAVES DE API E PAPÉIS
+// ==========================================
+
+// Papéis aceitos em api_keys: read só consulta; admin também dispara refresh e promoção.
+const (
+	roleRead  = "read"
+	roleAdmin = "admin"
+)
+
+// AccessConfig controla quem acessa as rotas de um catálogo (a raiz ou um tenant).
+type AccessConfig struct {
+	AdminTokenEnv string   `json:"admin_token_env"` // Variável com um token de papel admin (legado, equivale a uma chave admin)
+	APIKeys       []APIKey `json:"api_keys"`        // Chaves aceitas, cada uma com seu papel
+	PrivateRead   bool     `json:"private_read"`    // Leituras também exigem chave (read ou admin); padrão: leitura pública
+}
+
+// APIKey é uma credencial do modo servidor. O valor nunca fica no arquivo de configuração:
+// vem de uma variável de ambiente (key_env) ou é conferido pelo SHA-256 (key_sha256).
+type APIKey struct {
+	Name      string `json:"name"`       // Identifica a chave nos logs
+	KeyEnv    string `json:"key_env"`    // Variável com o valor da chave
+	KeySHA256 string `json:"key_sha256"` // SHA-256 (hex) do valor da chave
+	Role      string `json:"role"`       // read ou admin
+	RateLimit int    `json:"rate_limit"` // Requisições por minuto desta chave (0 = server.rate_limit.per_key)
+}
+
+// validate confere as chaves ao subir o servidor.
+func (a AccessConfig) validate() error {
+	for i, key := range a.APIKeys {
+		if key.Role != roleRead && key.Role != roleAdmin {
+			return fmt.Errorf("api_keys[%d]: role inválido %q (use read ou admin)", i, key.Role)
+		}
+		if (key.KeyEnv == "") == (key.KeySHA256 == "") {
+			return fmt.Errorf("api_keys[%d]: informe exatamente um de key_env ou key_sha256", i)
+		}
+		if key.KeySHA256 != "" && !sha256HexRe.MatchString(key.KeySHA256) {
+			return fmt.Errorf("api_keys[%d]: key_sha256 não é um SHA-256 em hexadecimal", i)
+		}
+	}
+	return nil
+}
+
+// authenticate devolve a chave que confere com token; ok é false se nenhuma conferir.
+func (a AccessConfig) authenticate(token string) (key APIKey, ok bool) {
+	if token == "" {
+		return APIKey{}, false
+	}
+	if a.AdminTokenEnv != "" {
+		if expected := os.Getenv(a.AdminTokenEnv); expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			return APIKey{Name: a.AdminTokenEnv, Role: roleAdmin}, true
+		}
+	}
+	sum := sha256.Sum256([]byte(token))
+	digest := hex.EncodeToString(sum[:])
+	for _, key := range a.APIKeys {
+		switch {
+		case key.KeyEnv != "":
+			if expected := os.Getenv(key.KeyEnv); expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+				return key, true
+			}
+		case subtle.ConstantTimeCompare([]byte(digest), []byte(strings.ToLower(key.KeySHA256))) == 1:
+			return key, true
+		}
+	}
+	return APIKey{}, false
+}
+
+// hasAdmin indica se alguma credencial de papel admin está disponível; sem nenhuma, as
+// rotas administrativas ficam desativadas (403), nunca abertas.
+func (a AccessConfig) hasAdmin() bool {
+	if a.AdminTokenEnv != "" && os.Getenv(a.AdminTokenEnv) != "" {
+		return true
+	}
+	for _, key := range a.APIKeys {
+		if key.Role == roleAdmin && (key.KeySHA256 != "" || os.Getenv(key.KeyEnv) != "") {
+			return true
+		}
+	}
+	return false
+}
+
+// requestToken lê a credencial de "Authorization: Bearer <chave>" ou do cabeçalho X-API-Key.
+func requestToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return r.Header.Get("X-API-Key")
+}
+
+// requireRole protege a rota com o papel exigido. Leituras só exigem chave com private_read.
+func requireRole(access AccessConfig, role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if role == roleRead && !access.PrivateRead {
+			next(w, r)
+			return
+		}
+		if role == roleAdmin && !access.hasAdmin() {
+			writeError(w, http.StatusForbidden, "rotas administrativas desativadas (nenhuma chave admin configurada)")
+			return
+		}
+
+		key, ok := access.authenticate(requestToken(r))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="updater-registry"`)
+			writeError(w, http.StatusUnauthorized, "chave inválida")
+			return
+		}
+		if role == roleAdmin && key.Role != roleAdmin {
+			writeError(w, http.StatusForbidden, "a chave "+key.Name+" não tem o papel admin")
+			return
+		}
+		if role == roleAdmin {
+			infof(">>> %s %s autorizado para a chave %s", r.Method, r.URL.Path, key.Name)
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// MODO SERVIDOR: CORS
+// ==========================================
+
+// CORSConfig libera a API para páginas de outras origens, para que dashboards web consultem o
+// registry direto do navegador.
+type CORSConfig struct {
+	AllowedOrigins []string `json:"allowed_origins"` // Ex: https://painel.example.com, https://*.example.com ou * (vazio = CORS desativado)
+	MaxAge         Duration `json:"max_age"`         // Por quanto tempo o navegador guarda o preflight (padrão: 10m)
+}
+
+// Cabeçalhos que o navegador pode enviar (chaves de API, retomada de downloads) e ler da resposta.
+const (
+	corsAllowHeaders  = "Authorization, X-API-Key, Content-Type, Range, If-Range, If-None-Match"
+	corsExposeHeaders = "ETag, Content-Range, Content-Disposition, Retry-After, WWW-Authenticate"
+)
+
+// allowed diz se origin está entre as origens liberadas.
+func (c CORSConfig) allowed(origin string) bool {
+	for _, pattern := range c.AllowedOrigins {
+		if pattern == "*" || strings.EqualFold(pattern, origin) {
+			return true
+		}
+		// https://*.example.com: qualquer subdomínio, nunca o próprio example.com
+		if prefix, suffix, ok := strings.Cut(pattern, "*"); ok {
+			rest, found := strings.CutPrefix(origin, prefix)
+			if found && strings.HasSuffix(rest, suffix) && len(rest) > len(suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withCORS responde os preflights (OPTIONS) e acrescenta os cabeçalhos de CORS às respostas de
+// origens liberadas. Origens fora da lista recebem a resposta sem eles, e o navegador a bloqueia.
+func withCORS(conf CORSConfig, next http.Handler) http.Handler {
+	if len(conf.AllowedOrigins) == 0 {
+		return next
+	}
+	maxAge := time.Duration(conf.MaxAge)
+	if maxAge <= 0 {
+		maxAge = 10 * time.Minute
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		if !conf.allowed(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// A origem é ecoada (com Vary: Origin) em vez de "*", igual para uma lista de origens ou *
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(maxAge.Seconds())))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Access-Control-Expose-Headers", corsExposeHeaders)
+		next.ServeHTTP(w, r)
+	})
+}
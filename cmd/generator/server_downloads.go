@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ==========================================
+// MODO SERVIDOR: CONTAGEM DE DOWNLOADS
+// ==========================================
+
+// DownloadStats são as contagens gravadas em proxy.stats_path. O arquivo é JSON simples, para
+// que páginas estáticas e planilhas também possam consumi-lo.
+type DownloadStats struct {
+	Apps map[string]*AppDownloads `json:"apps"`
+}
+
+// AppDownloads conta as entregas de um app pelo proxy, por versão.
+type AppDownloads struct {
+	ID           string           `json:"id"`
+	Total        int64            `json:"total"`
+	Versions     map[string]int64 `json:"versions"`
+	LastDownload time.Time        `json:"last_download,omitzero"`
+}
+
+// downloadStore mantém as contagens de um catálogo em memória e as grava periodicamente.
+type downloadStore struct {
+	path string
+
+	mu    sync.Mutex
+	stats DownloadStats
+	dirty bool
+
+	flushMu sync.Mutex
+}
+
+func newDownloadStore(tenant string) (*downloadStore, error) {
+	path := cfg.Server.Proxy.StatsPath
+	if path == "" {
+		path = "downloads.json"
+	}
+	if tenant != "" {
+		path = profilePath(path, tenant)
+	}
+
+	store := &downloadStore{path: path, stats: DownloadStats{Apps: make(map[string]*AppDownloads)}}
+	data, err := readBlob(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.stats); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if store.stats.Apps == nil {
+		store.stats.Apps = make(map[string]*AppDownloads)
+	}
+	return store, nil
+}
+
+// countable diz se a resposta conta como um download: um trecho que não começa do zero é a
+// retomada de um download já contado, e HEAD e 304 não entregaram nada.
+func countable(status int, r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	switch status {
+	case http.StatusOK:
+		return true
+	case http.StatusPartialContent:
+		return strings.HasPrefix(r.Header.Get("Range"), "bytes=0-")
+	}
+	return false
+}
+
+// record conta um download da versão publicada do app.
+func (s *downloadStore) record(app CatalogApp) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := s.stats.Apps[app.ID]
+	if counts == nil {
+		counts = &AppDownloads{ID: app.ID, Versions: make(map[string]int64)}
+		s.stats.Apps[app.ID] = counts
+	}
+	counts.Total++
+	counts.Versions[app.Version]++
+	counts.LastDownload = time.Now().UTC()
+	s.dirty = true
+}
+
+// snapshot copia as contagens de um app (zeradas se ele nunca foi baixado).
+func (s *downloadStore) snapshot(id string) AppDownloads {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := AppDownloads{ID: id, Versions: make(map[string]int64)}
+	if counts := s.stats.Apps[id]; counts != nil {
+		result.Total, result.LastDownload = counts.Total, counts.LastDownload
+		for version, n := range counts.Versions {
+			result.Versions[version] = n
+		}
+	}
+	return result
+}
+
+// flush grava as contagens se houve downloads desde a última gravação.
+func (s *downloadStore) flush() error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(s.stats, "", "  ")
+	s.dirty = false
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := writeBlob(s.path, data, "application/json"); err != nil {
+		s.mu.Lock()
+		s.dirty = true // Tenta de novo na próxima rodada
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *downloadStore) location() string {
+	return s.path
+}
+
+// handleStats atende GET /api/v1/apps/{id}/downloads e, sem id, GET /api/v1/downloads: todos os
+// apps do catálogo, dos mais baixados para os menos (popularidade).
+func (s *downloadStore) handleStats(cache *catalogCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		catalog, err := cache.get()
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		if id := r.PathValue("id"); id != "" {
+			app, ok := catalog.lookup(id)
+			if !ok {
+				writeError(w, http.StatusNotFound, "app não encontrado")
+				return
+			}
+			writeJSON(w, http.StatusOK, s.snapshot(app.ID))
+			return
+		}
+
+		all := make([]AppDownloads, 0, len(catalog.Apps))
+		for id := range catalog.Apps {
+			all = append(all, s.snapshot(id))
+		}
+		sort.Slice(all, func(i, j int) bool {
+			if all[i].Total != all[j].Total {
+				return all[i].Total > all[j].Total
+			}
+			return all[i].ID < all[j].ID
+		})
+		writeJSON(w, http.StatusOK, all)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ==========================================
+// MODO SERVIDOR: SAÚDE E MÉTRICAS
+// ==========================================
+
+// serverMetrics acumula os contadores expostos em /metrics, no formato texto do Prometheus.
+type serverMetrics struct {
+	catalogs []servedCatalog
+
+	mu        sync.Mutex
+	requests  map[requestKey]uint64
+	downloads map[downloadKey]uint64
+}
+
+type requestKey struct {
+	route  string // Padrão da rota (ex: "GET /api/v1/apps/{id}"), para não gerar um rótulo por id
+	status int
+}
+
+type downloadKey struct {
+	tenant, app string
+}
+
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{requests: make(map[requestKey]uint64), downloads: make(map[downloadKey]uint64)}
+}
+
+// statusRecorder guarda o status escrito pelo handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// instrument conta as requisições por rota e status. O ServeMux preenche r.Pattern na própria
+// requisição, então ele já está disponível depois de next.
+func (m *serverMetrics) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		m.mu.Lock()
+		m.requests[requestKey{route, rec.status}]++
+		m.mu.Unlock()
+	})
+}
+
+// download conta uma entrega do proxy de downloads.
+func (m *serverMetrics) download(tenant, app string) {
+	m.mu.Lock()
+	m.downloads[downloadKey{tenant, app}]++
+	m.mu.Unlock()
+}
+
+// readiness confere cada catálogo: carregado e, com server.max_catalog_age, gerado há menos que
+// isso (um cron de generate parado deixa a instância "não pronta").
+func (m *serverMetrics) readiness() Readiness {
+	result := Readiness{Ready: true, Catalogs: make([]CatalogReadiness, 0, len(m.catalogs))}
+	for _, served := range m.catalogs {
+		status := CatalogReadiness{Tenant: served.tenant}
+		catalog, err := served.cache.get()
+		switch {
+		case err != nil:
+			status.Error = err.Error()
+		default:
+			status.Apps, status.LastUpdated = len(catalog.Apps), catalog.LastUpdated
+			age := time.Since(catalog.LastUpdated)
+			status.AgeSeconds = int64(age.Seconds())
+			if maxAge := time.Duration(cfg.Server.MaxCatalogAge); maxAge > 0 && age > maxAge {
+				status.Error = fmt.Sprintf("catálogo gerado há %s (limite: %s)", age.Round(time.Second), maxAge)
+			} else {
+				status.Ready = true
+			}
+		}
+		result.Ready = result.Ready && status.Ready
+		result.Catalogs = append(result.Catalogs, status)
+	}
+	return result
+}
+
+// registerHealthRoutes registra /healthz, /readyz e /metrics, globais (fora dos tenants) e sem
+// limite de requisições, para que balanceadores e o Prometheus nunca recebam 429.
+func registerHealthRoutes(mux routeMux, metrics *serverMetrics, access AccessConfig) {
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		readiness := metrics.readiness()
+		status := http.StatusOK
+		if !readiness.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, readiness)
+	})
+
+	// Com private_read, as métricas exigem uma chave da raiz, como as demais leituras
+	mux.HandleFunc("GET /metrics", requireRole(access, roleRead, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write([]byte(metrics.exposition()))
+	}))
+}
+
+// exposition monta /metrics no formato texto do Prometheus.
+func (m *serverMetrics) exposition() string {
+	var b strings.Builder
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	m.mu.Lock()
+	requests := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		requests = append(requests, key)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i].route != requests[j].route {
+			return requests[i].route < requests[j].route
+		}
+		return requests[i].status < requests[j].status
+	})
+	metric("updater_http_requests_total", "counter", "Requisições atendidas, por rota e status.")
+	for _, key := range requests {
+		fmt.Fprintf(&b, "updater_http_requests_total{route=%s,status=\"%d\"} %d\n", promLabel(key.route), key.status, m.requests[key])
+	}
+
+	downloads := make([]downloadKey, 0, len(m.downloads))
+	for key := range m.downloads {
+		downloads = append(downloads, key)
+	}
+	sort.Slice(downloads, func(i, j int) bool {
+		if downloads[i].tenant != downloads[j].tenant {
+			return downloads[i].tenant < downloads[j].tenant
+		}
+		return downloads[i].app < downloads[j].app
+	})
+	metric("updater_downloads_total", "counter", "Artefatos entregues pelo proxy de downloads, por app.")
+	for _, key := range downloads {
+		fmt.Fprintf(&b, "updater_downloads_total{tenant=%s,app=%s} %d\n", promLabel(key.tenant), promLabel(key.app), m.downloads[key])
+	}
+	m.mu.Unlock()
+
+	metric("updater_catalog_age_seconds", "gauge", "Segundos desde a última geração do catálogo (last_updated).")
+	var apps strings.Builder
+	for _, served := range m.catalogs {
+		catalog, err := served.cache.get()
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "updater_catalog_age_seconds{tenant=%s} %.0f\n", promLabel(served.tenant), time.Since(catalog.LastUpdated).Seconds())
+		fmt.Fprintf(&apps, "updater_catalog_apps{tenant=%s} %d\n", promLabel(served.tenant), len(catalog.Apps))
+	}
+	metric("updater_catalog_apps", "gauge", "Apps no catálogo.")
+	b.WriteString(apps.String())
+	return b.String()
+}
+
+// promLabel escapa o valor de um rótulo do Prometheus (entre aspas).
+func promLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
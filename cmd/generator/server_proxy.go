@@ -0,0 +1,225 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ==========================================
+// MODO SERVIDOR: PROXY DE DOWNLOADS COM CACHE
+// ==========================================
+
+// ProxyConfig ativa GET /download/{id}, que entrega o artefato publicado a partir de um cache
+// local: frotas atrás de políticas de saída restritivas só precisam alcançar o registry.
+type ProxyConfig struct {
+	Enabled   bool   `json:"enabled"`
+	CacheDir  string `json:"cache_dir"`  // Onde guardar os artefatos (padrão: download-cache)
+	StatsPath string `json:"stats_path"` // Contagem de downloads por app e versão (padrão: downloads.json; tenants ganham o nome como sufixo)
+}
+
+// downloadProxy baixa o artefato da origem na primeira requisição, confere o hash publicado no
+// catálogo e guarda o arquivo; as seguintes saem do disco. Só a versão atual de cada app fica
+// no cache: ao baixar uma nova, as anteriores são apagadas.
+type downloadProxy struct {
+	dir     string // Cache deste catálogo (o dos tenants fica em cache_dir/t/{name})
+	cache   *catalogCache
+	tenant  TenantConfig
+	metrics *serverMetrics
+	counts  *downloadStore
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex // Um download por artefato, mesmo com várias requisições simultâneas
+}
+
+func newDownloadProxy(prefix string, tenant TenantConfig, cache *catalogCache, metrics *serverMetrics, counts *downloadStore) *downloadProxy {
+	dir := cfg.Server.Proxy.CacheDir
+	if dir == "" {
+		dir = "download-cache"
+	}
+	return &downloadProxy{dir: filepath.Join(dir, filepath.FromSlash(prefix)), cache: cache, tenant: tenant, metrics: metrics, counts: counts, locks: make(map[string]*sync.Mutex)}
+}
+
+func (p *downloadProxy) handle(w http.ResponseWriter, r *http.Request) {
+	catalog, err := p.cache.get()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	app, ok := catalog.lookup(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "app não encontrado")
+		return
+	}
+
+	file, err := p.fetch(r, app)
+	if err != nil {
+		errorf(" [ERRO] Proxy de download %s: %v", app.ID, err)
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	p.metrics.download(p.tenant.Name, app.ID)
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	p.serve(rec, r, app, file)
+	if countable(rec.status, r) {
+		p.counts.record(app)
+	}
+}
+
+// cachePath é o arquivo do artefato no cache, identificado pelo hash publicado (ou pela versão,
+// quando o catálogo não tem hash).
+func (p *downloadProxy) cachePath(app CatalogApp) string {
+	key := app.Checksum
+	switch {
+	case key != "":
+	case app.SHA512 != "":
+		key = "sha512-" + app.SHA512[:min(len(app.SHA512), 64)]
+	default:
+		key = "v" + strings.NewReplacer("/", "_", "\\", "_").Replace(app.Version)
+	}
+	return filepath.Join(p.dir, app.ID, key)
+}
+
+// lock devolve a trava do artefato, criando-a na primeira vez.
+func (p *downloadProxy) lock(file string) *sync.Mutex {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.locks[file] == nil {
+		p.locks[file] = &sync.Mutex{}
+	}
+	return p.locks[file]
+}
+
+// fetch garante o artefato no cache e devolve o caminho dele.
+func (p *downloadProxy) fetch(r *http.Request, app CatalogApp) (string, error) {
+	file := p.cachePath(app)
+	if _, err := os.Stat(file); err == nil {
+		debugf("     proxy: %s %s do cache", app.ID, app.Version)
+		return file, nil
+	}
+
+	lock := p.lock(file)
+	lock.Lock()
+	defer lock.Unlock()
+	if _, err := os.Stat(file); err == nil {
+		return file, nil // Baixado por uma requisição concorrente
+	}
+
+	// Com a origem fora do ar, os mirrors publicados na entrada são tentados em ordem
+	var body io.ReadCloser
+	var err error
+	for _, u := range append([]string{app.DownloadURL}, app.Mirrors...) {
+		infof(">>> Proxy: baixando %s %s de %s", app.ID, app.Version, u)
+		if body, _, err = openArtifact(r.Context(), p.source(app.ID), u); err == nil || r.Context().Err() != nil {
+			break
+		}
+		errorf(" [AVISO] Proxy: falha ao baixar %s de %s: %v", app.ID, u, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name()) // Sem efeito depois do Rename
+
+	sum256, sum512 := sha256.New(), sha512.New()
+	size, err := io.Copy(io.MultiWriter(tmp, sum256, sum512), body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// O artefato só entra no cache se bater com o que o catálogo publicou
+	if got := hex.EncodeToString(sum256.Sum(nil)); app.Checksum != "" && !strings.EqualFold(got, app.Checksum) {
+		return "", fmt.Errorf("SHA-256 da origem (%s) difere do catálogo (%s)", got, app.Checksum)
+	}
+	if got := hex.EncodeToString(sum512.Sum(nil)); app.SHA512 != "" && !strings.EqualFold(got, app.SHA512) {
+		return "", fmt.Errorf("SHA-512 da origem difere do catálogo")
+	}
+	if app.Size > 0 && size != app.Size {
+		return "", fmt.Errorf("tamanho da origem (%d bytes) difere do catálogo (%d)", size, app.Size)
+	}
+
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return "", err
+	}
+	p.pruneVersions(file)
+	return file, nil
+}
+
+// source devolve a fonte do app (para a autenticação e o TLS da origem), relida das fontes do
+// catálogo; sem elas, o download é feito sem configuração extra.
+func (p *downloadProxy) source(id string) SourceApp {
+	if len(p.tenant.Sources) > 0 {
+		if sources, err := readSources(p.tenant.Sources...); err == nil {
+			for _, src := range sources {
+				if src.ID == id {
+					return src
+				}
+			}
+		}
+	}
+	return SourceApp{ID: id}
+}
+
+// pruneVersions apaga do cache as versões anteriores do app, mantendo só keep.
+func (p *downloadProxy) pruneVersions(keep string) {
+	entries, err := os.ReadDir(filepath.Dir(keep))
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := filepath.Join(filepath.Dir(keep), entry.Name())
+		if name == keep || strings.HasPrefix(entry.Name(), ".download-") {
+			continue
+		}
+		if err := os.Remove(name); err == nil {
+			verbosef("     proxy: versão antiga removida do cache: %s", name)
+		}
+	}
+}
+
+// serve entrega o arquivo do cache com o nome original do artefato. http.ServeContent atende
+// Range (e If-Range/If-None-Match pelo ETag, que é a chave do cache), para que o updater do
+// cliente retome um download interrompido contra o próprio registry.
+func (p *downloadProxy) serve(w http.ResponseWriter, r *http.Request, app CatalogApp, file string) {
+	f, err := os.Open(file)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	name := path.Base(strings.SplitN(app.DownloadURL, "?", 2)[0])
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	w.Header().Set("ETag", `"`+filepath.Base(file)+`"`)
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ==========================================
+// MODO SERVIDOR: LIMITE DE REQUISIÇÕES
+// ==========================================
+
+// RateLimitConfig limita o ritmo de cada cliente, para que uma frota consultando o catálogo a
+// cada segundo não derrube uma instância pequena. Requisições com uma chave de API válida
+// contam para a chave; as demais, para o IP de origem.
+type RateLimitConfig struct {
+	PerIP      int  `json:"per_ip"`      // Requisições por minuto por IP (0 = sem limite)
+	PerKey     int  `json:"per_key"`     // Requisições por minuto por chave de API (0 = sem limite)
+	Burst      int  `json:"burst"`       // Rajada tolerada de uma vez (padrão: o limite por minuto)
+	TrustProxy bool `json:"trust_proxy"` // Usa o último IP de X-Forwarded-For, o anexado pelo proxy (só atrás de um proxy confiável)
+}
+
+// tokenBucket é o balde de um cliente: enche a rate fichas por segundo até burst.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter guarda os baldes de todos os clientes, inclusive dos tenants (as chaves levam o
+// prefixo do tenant, já que os nomes só são únicos dentro dele).
+type rateLimiter struct {
+	conf      RateLimitConfig
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func newRateLimiter(conf RateLimitConfig) *rateLimiter {
+	return &rateLimiter{conf: conf, buckets: make(map[string]*tokenBucket), lastSweep: time.Now()}
+}
+
+// allow consome uma ficha do balde do cliente. Sem fichas, devolve quanto esperar pela próxima.
+func (l *rateLimiter) allow(client string, perMinute int) (bool, time.Duration) {
+	rate := float64(perMinute) / 60
+	burst := float64(l.conf.Burst)
+	if burst <= 0 {
+		burst = float64(perMinute)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep descarta, no máximo uma vez por minuto, os baldes parados há mais de dez minutos (já
+// cheios de novo), para que a memória não cresça com cada IP que passou pelo servidor.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	for client, b := range l.buckets {
+		if now.Sub(b.last) > 10*time.Minute {
+			delete(l.buckets, client)
+		}
+	}
+	l.lastSweep = now
+}
+
+// wrap aplica o limite antes da autenticação, para que tentativas de adivinhar chaves também
+// esbarrem no limite do IP. Sem limites configurados (l nil), devolve next intacto.
+func (l *rateLimiter) wrap(prefix string, access AccessConfig, next http.HandlerFunc) http.HandlerFunc {
+	if l == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, perMinute := "ip:"+l.clientIP(r), l.conf.PerIP
+		if key, ok := access.authenticate(requestToken(r)); ok {
+			client, perMinute = "key:"+prefix+"/"+key.Name, l.conf.PerKey
+			if key.RateLimit > 0 {
+				perMinute = key.RateLimit
+			}
+		}
+		if perMinute <= 0 {
+			next(w, r)
+			return
+		}
+
+		if ok, wait := l.allow(client, perMinute); !ok {
+			seconds := int(math.Ceil(wait.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			debugf("     limite de requisições atingido: %s %s (%s)", r.Method, r.URL.Path, client)
+			writeError(w, http.StatusTooManyRequests, "limite de requisições atingido; tente de novo em "+strconv.Itoa(seconds)+"s")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// clientIP é o IP de origem da requisição, sem a porta. Atrás do proxy, vale a entrada mais à
+// direita de X-Forwarded-For: é a que o proxy anexou; as anteriores vêm do cliente e podem ser
+// forjadas para escapar do limite.
+func (l *rateLimiter) clientIP(r *http.Request) string {
+	if l.conf.TrustProxy {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			entries := strings.Split(values[len(values)-1], ",")
+			if last := strings.TrimSpace(entries[len(entries)-1]); last != "" {
+				return last
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// hasKeyRateLimit indica se alguma chave (da raiz ou de um tenant) tem rate_limit próprio.
+func hasKeyRateLimit() bool {
+	tenants := append([]TenantConfig{rootTenant()}, cfg.Server.Tenants...)
+	for _, tenant := range tenants {
+		for _, key := range tenant.APIKeys {
+			if key.RateLimit > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustProxy bool
+		forwarded  []string
+		want       string
+	}{
+		{"sem proxy ignora o cabeçalho", false, []string{"6.6.6.6"}, "10.0.0.1"},
+		{"proxy sem cabeçalho", true, nil, "10.0.0.1"},
+		{"entrada única", true, []string{"203.0.113.7"}, "203.0.113.7"},
+		{"entrada forjada antes da do proxy", true, []string{"6.6.6.6, 203.0.113.7"}, "203.0.113.7"},
+		{"vários cabeçalhos", true, []string{"6.6.6.6", "7.7.7.7, 203.0.113.7"}, "203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newRateLimiter(RateLimitConfig{TrustProxy: tt.trustProxy})
+			r := httptest.NewRequest("GET", "/catalog.json", nil)
+			r.RemoteAddr = "10.0.0.1:4321"
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if got := l.clientIP(r); got != tt.want {
+				t.Errorf("clientIP = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Um cliente que forja um X-Forwarded-For diferente a cada requisição continua no mesmo balde.
+func TestRateLimitSpoofedForwardedFor(t *testing.T) {
+	l := newRateLimiter(RateLimitConfig{PerIP: 2, TrustProxy: true})
+	handler := l.wrap("", AccessConfig{}, func(w http.ResponseWriter, r *http.Request) {})
+
+	codes := make([]int, 0, 3)
+	for _, spoofed := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"} {
+		r := httptest.NewRequest("GET", "/catalog.json", nil)
+		r.RemoteAddr = "10.0.0.1:4321"
+		r.Header.Set("X-Forwarded-For", spoofed+", 203.0.113.7") // O proxy anexa o IP real
+		w := httptest.NewRecorder()
+		handler(w, r)
+		codes = append(codes, w.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("status = %v, esperado [200 200 429]", codes)
+	}
+}
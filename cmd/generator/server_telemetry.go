@@ -0,0 +1,261 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ==========================================
+// MODO SERVIDOR: TELEMETRIA DOS CLIENTES (OPT-IN)
+// ==========================================
+
+// TelemetryConfig ativa POST /api/v1/telemetry, onde clientes que optarem por isso informam a
+// versão instalada e o resultado das atualizações. Os relatos viram estatísticas de adoção por
+// app, úteis para decidir quando descontinuar versões antigas.
+type TelemetryConfig struct {
+	Enabled bool     `json:"enabled"`
+	Path    string   `json:"path"`   // Onde guardar os agregados (padrão: telemetry.json; tenants ganham o nome como sufixo)
+	Window  Duration `json:"window"` // Clientes sem relato há mais que isso saem da adoção (padrão: 720h)
+}
+
+const (
+	telemetryMaxBody       = 4 << 10 // Um relato é um JSON pequeno
+	telemetryMaxFieldBytes = 128     // Limite de version e client_id
+)
+
+// O corpo de POST /api/v1/telemetry é TelemetryReport (api.gen.go). client_id é um identificador
+// aleatório gerado pelo cliente (nunca dados da máquina ou do usuário); só o SHA-256 dele é
+// guardado, para contar clientes distintos sem somar o mesmo cliente a cada relato. Os eventos:
+// installed (versão em uso, relato periódico), update_ok e update_failed (resultado da
+// atualização para version).
+
+// TelemetryStats são os agregados gravados em telemetry.path.
+type TelemetryStats struct {
+	Apps map[string]*AppTelemetry `json:"apps"`
+}
+
+// AppTelemetry agrega os relatos de um app.
+type AppTelemetry struct {
+	Clients map[string]telemetryClient `json:"clients"` // SHA-256 do client_id -> último relato
+	Updates map[string]*UpdateOutcomes `json:"updates"` // Versão alvo -> resultados
+}
+
+type telemetryClient struct {
+	Version string    `json:"version"`
+	Seen    time.Time `json:"seen"`
+}
+
+// UpdateOutcomes conta os resultados das atualizações para uma versão.
+type UpdateOutcomes struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// telemetryStore mantém os agregados de um catálogo em memória e os grava periodicamente.
+type telemetryStore struct {
+	path   string
+	window time.Duration
+
+	mu    sync.Mutex
+	stats TelemetryStats
+	dirty bool
+
+	flushMu sync.Mutex // Gravações em ordem: a do encerramento nunca é sobrescrita por uma antiga
+}
+
+func newTelemetryStore(tenant string) (*telemetryStore, error) {
+	conf := cfg.Server.Telemetry
+	path := conf.Path
+	if path == "" {
+		path = "telemetry.json"
+	}
+	if tenant != "" {
+		path = profilePath(path, tenant)
+	}
+	window := time.Duration(conf.Window)
+	if window <= 0 {
+		window = 30 * 24 * time.Hour
+	}
+
+	store := &telemetryStore{path: path, window: window, stats: TelemetryStats{Apps: make(map[string]*AppTelemetry)}}
+	data, err := readBlob(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.stats); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if store.stats.Apps == nil {
+		store.stats.Apps = make(map[string]*AppTelemetry)
+	}
+	return store, nil
+}
+
+// record soma um relato aos agregados.
+func (s *telemetryStore) record(id string, report TelemetryReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app := s.stats.Apps[id]
+	if app == nil {
+		app = &AppTelemetry{Clients: make(map[string]telemetryClient), Updates: make(map[string]*UpdateOutcomes)}
+		s.stats.Apps[id] = app
+	}
+	switch report.Event {
+	case TelemetryReportEventUpdateOk, TelemetryReportEventUpdateFailed:
+		outcome := app.Updates[report.Version]
+		if outcome == nil {
+			outcome = &UpdateOutcomes{}
+			app.Updates[report.Version] = outcome
+		}
+		if report.Event == TelemetryReportEventUpdateOk {
+			outcome.Succeeded++
+		} else {
+			outcome.Failed++
+		}
+	}
+	// Uma atualização que falhou não muda a versão em uso
+	if report.ClientID != "" && report.Event != TelemetryReportEventUpdateFailed {
+		sum := sha256.Sum256([]byte(report.ClientID))
+		app.Clients[hex.EncodeToString(sum[:])] = telemetryClient{Version: report.Version, Seen: time.Now().UTC()}
+	}
+	s.dirty = true
+}
+
+// adoption resume os relatos de um app; current é a versão publicada no catálogo.
+func (s *telemetryStore) adoption(id, current string) AppAdoption {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := AppAdoption{App: id, Versions: make(map[string]int), Updates: make(map[string]UpdateOutcomes)}
+	app := s.stats.Apps[id]
+	if app == nil {
+		return result
+	}
+	cutoff := time.Now().Add(-s.window)
+	for _, client := range app.Clients {
+		if client.Seen.Before(cutoff) {
+			continue
+		}
+		result.Clients++
+		result.Versions[client.Version]++
+	}
+	if result.Clients > 0 {
+		result.Latest = float64(result.Versions[current]) / float64(result.Clients)
+	}
+	for version, outcome := range app.Updates {
+		result.Updates[version] = *outcome
+	}
+	return result
+}
+
+// flush grava os agregados se houve relatos desde a última gravação, descartando antes os
+// clientes fora da janela.
+func (s *telemetryStore) flush() error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	cutoff := time.Now().Add(-s.window)
+	for _, app := range s.stats.Apps {
+		for hash, client := range app.Clients {
+			if client.Seen.Before(cutoff) {
+				delete(app.Clients, hash)
+			}
+		}
+	}
+	data, err := json.MarshalIndent(s.stats, "", "  ")
+	s.dirty = false
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := writeBlob(s.path, data, "application/json"); err != nil {
+		s.mu.Lock()
+		s.dirty = true // Tenta de novo na próxima rodada
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *telemetryStore) location() string {
+	return s.path
+}
+
+// handleReport atende POST /api/v1/telemetry.
+func (s *telemetryStore) handleReport(cache *catalogCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var report TelemetryReport
+		r.Body = http.MaxBytesReader(w, r.Body, telemetryMaxBody)
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			writeError(w, http.StatusBadRequest, "relato inválido: "+err.Error())
+			return
+		}
+		switch {
+		case !report.Event.Valid():
+			writeError(w, http.StatusBadRequest, "event deve ser installed, update_ok ou update_failed")
+			return
+		case report.Version == "" || len(report.Version) > telemetryMaxFieldBytes || len(report.ClientID) > telemetryMaxFieldBytes:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("version é obrigatório; version e client_id têm até %d bytes", telemetryMaxFieldBytes))
+			return
+		}
+
+		catalog, err := cache.get()
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		app, ok := catalog.lookup(report.App) // Clientes antigos podem relatar pelo alias
+		if !ok {
+			writeError(w, http.StatusNotFound, "app não encontrado")
+			return
+		}
+		s.record(app.ID, report)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleAdoption atende GET /api/v1/apps/{id}/adoption e, sem id, GET /api/v1/adoption (todos
+// os apps do catálogo, ordenados por id).
+func (s *telemetryStore) handleAdoption(cache *catalogCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		catalog, err := cache.get()
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		if id := r.PathValue("id"); id != "" {
+			app, ok := catalog.lookup(id)
+			if !ok {
+				writeError(w, http.StatusNotFound, "app não encontrado")
+				return
+			}
+			writeJSON(w, http.StatusOK, s.adoption(app.ID, app.Version))
+			return
+		}
+
+		all := make([]AppAdoption, 0, len(catalog.Apps))
+		for _, app := range catalog.Apps {
+			all = append(all, s.adoption(app.ID, app.Version))
+		}
+		sort.Slice(all, func(i, j int) bool { return all[i].App < all[j].App })
+		writeJSON(w, http.StatusOK, all)
+	}
+}
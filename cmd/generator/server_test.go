@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// routeRecorder guarda os padrões registrados ("GET /catalog.json").
+type routeRecorder map[string]bool
+
+func (r routeRecorder) HandleFunc(pattern string, _ func(http.ResponseWriter, *http.Request)) {
+	r[pattern] = true
+}
+
+// normalizeRoute troca cada segmento com parâmetro por {}: o ServeMux só aceita parâmetros de
+// segmento inteiro (/badge/{file}), e o openapi.json descreve o formato (/badge/{id}.svg).
+func normalizeRoute(method, path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.Contains(segment, "{") {
+			segments[i] = "{}"
+		}
+	}
+	return strings.ToUpper(method) + " " + strings.Join(segments, "/")
+}
+
+// Toda rota do servidor (com os recursos opcionais ligados) está no openapi.json, e vice-versa.
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	served := servedCatalog{
+		cache:     &catalogCache{path: "catalog.json"},
+		telemetry: &telemetryStore{},
+		downloads: &downloadStore{},
+	}
+	tenant := TenantConfig{Catalog: "catalog.json", Staging: "staging.json"}
+	routes := routeRecorder{}
+	registerRoutes(routes, "", tenant, served, nil, newServerMetrics())
+	registerSpecRoute(routes, nil)
+	registerHealthRoutes(routes, newServerMetrics(), AccessConfig{})
+
+	registered := make(map[string]bool)
+	for pattern := range routes {
+		method, path, _ := strings.Cut(pattern, " ")
+		registered[normalizeRoute(method, path)] = true
+	}
+
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatal(err)
+	}
+	documented := make(map[string]bool)
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if slices.Contains([]string{"get", "post", "put", "patch", "delete"}, method) {
+				documented[normalizeRoute(method, path)] = true
+			}
+		}
+	}
+
+	for route := range registered {
+		if !documented[route] {
+			t.Errorf("rota %s registrada mas ausente do openapi.json", route)
+		}
+	}
+	for route := range documented {
+		if !registered[route] {
+			t.Errorf("rota %s documentada no openapi.json mas não registrada", route)
+		}
+	}
+}
+
+// Os tipos do catálogo e dos agregados em disco são escritos à mão (fora do api.gen.go, ver
+// oapi-codegen.yaml): os campos JSON de cada um precisam ser os do schema correspondente.
+func TestSpecMatchesCatalogTypes(t *testing.T) {
+	var spec struct {
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	types := map[string]any{
+		"Catalog":        Catalog{},
+		"CatalogStats":   CatalogStats{},
+		"CatalogApp":     CatalogApp{},
+		"ChannelRelease": ChannelRelease{},
+		"Requirements":   Requirements{},
+		"InstallScript":  InstallScript{},
+		"AppDownloads":   AppDownloads{},
+		"UpdateOutcomes": UpdateOutcomes{},
+	}
+	for name, value := range types {
+		schema, ok := spec.Components.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s ausente do openapi.json", name)
+			continue
+		}
+		var fields []string
+		typ := reflect.TypeOf(value)
+		for i := range typ.NumField() {
+			tag, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if tag != "" && tag != "-" {
+				fields = append(fields, tag)
+			}
+		}
+		slices.Sort(fields)
+		documented := slices.Sorted(maps.Keys(schema.Properties))
+		if !slices.Equal(fields, documented) {
+			t.Errorf("%s: campos do tipo %v, do schema %v", name, fields, documented)
+		}
+	}
+}
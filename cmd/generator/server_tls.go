@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// ==========================================
+// MODO SERVIDOR: TLS (ARQUIVOS OU ACME)
+// ==========================================
+
+// ServerTLSConfig faz o próprio servidor terminar o TLS, para que uma instância isolada numa
+// VPS não precise de um proxy reverso só para o HTTPS. Use cert_file/key_file ou acme.
+type ServerTLSConfig struct {
+	CertFile       string     `json:"cert_file"`       // Certificado PEM (com a cadeia)
+	KeyFile        string     `json:"key_file"`        // Chave privada PEM
+	ACME           ACMEConfig `json:"acme"`            // Certificado automático (Let's Encrypt)
+	RedirectListen string     `json:"redirect_listen"` // Ex: ":80": redireciona HTTP para HTTPS e atende o desafio HTTP-01
+}
+
+// ACMEConfig obtém e renova o certificado sozinho via ACME.
+type ACMEConfig struct {
+	Domains      []string `json:"domains"`       // Domínios aceitos (o certificado só é pedido para eles)
+	Email        string   `json:"email"`         // Contato para avisos de expiração
+	CacheDir     string   `json:"cache_dir"`     // Onde guardar conta e certificados (padrão: acme-cache)
+	DirectoryURL string   `json:"directory_url"` // Diretório ACME (padrão: Let's Encrypt produção)
+}
+
+func (t ServerTLSConfig) enabled() bool {
+	return t.CertFile != "" || t.KeyFile != "" || len(t.ACME.Domains) > 0
+}
+
+func (t ServerTLSConfig) validate() error {
+	files := t.CertFile != "" || t.KeyFile != ""
+	if files && (t.CertFile == "" || t.KeyFile == "") {
+		return fmt.Errorf("server.tls: informe cert_file e key_file juntos")
+	}
+	if files && len(t.ACME.Domains) > 0 {
+		return fmt.Errorf("server.tls: use cert_file/key_file ou acme, não os dois")
+	}
+	return nil
+}
+
+// configureTLS prepara server.TLSConfig e devolve o servidor HTTP de redirect_listen (nil sem
+// ele). Com ACME, o desafio TLS-ALPN-01 é atendido na própria porta HTTPS; o HTTP-01, só com
+// redirect_listen na porta 80.
+func configureTLS(server *http.Server, conf ServerTLSConfig) (*http.Server, error) {
+	if err := conf.validate(); err != nil {
+		return nil, err
+	}
+
+	redirect := http.HandlerFunc(redirectHTTPS)
+	if len(conf.ACME.Domains) > 0 {
+		cacheDir := conf.ACME.CacheDir
+		if cacheDir == "" {
+			cacheDir = "acme-cache"
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(conf.ACME.Domains...),
+			Cache:      autocert.DirCache(cacheDir),
+			Email:      conf.ACME.Email,
+		}
+		if conf.ACME.DirectoryURL != "" {
+			manager.Client = &acme.Client{DirectoryURL: conf.ACME.DirectoryURL}
+		}
+		server.TLSConfig = manager.TLSConfig()
+		infof(">>> TLS via ACME para %v (cache em %s)", conf.ACME.Domains, cacheDir)
+		if conf.RedirectListen == "" {
+			return nil, nil
+		}
+		return &http.Server{Addr: conf.RedirectListen, Handler: manager.HTTPHandler(redirect)}, nil
+	}
+
+	certs := &certReloader{certFile: conf.CertFile, keyFile: conf.KeyFile}
+	if _, err := certs.load(); err != nil {
+		return nil, err
+	}
+	server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, GetCertificate: certs.get}
+	if conf.RedirectListen == "" {
+		return nil, nil
+	}
+	return &http.Server{Addr: conf.RedirectListen, Handler: redirect}, nil
+}
+
+// redirectHTTPS manda o cliente para a mesma URL em HTTPS, no host pedido e na porta de listen.
+func redirectHTTPS(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if _, port, err := net.SplitHostPort(cfg.Server.Listen); err == nil && port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+}
+
+// certReloader relê cert_file/key_file quando mudam (renovação pelo certbot, por exemplo),
+// conferindo a data de modificação no máximo uma vez por minuto.
+type certReloader struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+func (c *certReloader) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.checked) < time.Minute {
+		return c.cert, nil
+	}
+	c.checked = time.Now()
+	if info, err := os.Stat(c.certFile); err != nil || info.ModTime().Equal(c.modTime) {
+		return c.cert, nil
+	}
+	cert, err := c.loadLocked()
+	if err != nil {
+		errorf(" [ERRO] Falha ao reler o certificado (mantendo o anterior): %v", err)
+		return c.cert, nil
+	}
+	infof(">>> Certificado TLS relido de %s", c.certFile)
+	return cert, nil
+}
+
+func (c *certReloader) load() (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checked = time.Now()
+	return c.loadLocked()
+}
+
+func (c *certReloader) loadLocked() (*tls.Certificate, error) {
+	info, err := os.Stat(c.certFile)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("server.tls: %w", err)
+	}
+	c.cert, c.modTime = &cert, info.ModTime()
+	return c.cert, nil
+}
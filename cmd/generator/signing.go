@@ -0,0 +1,214 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// ==========================================
+// ASSINATURA DO CATÁLOGO
+// ==========================================
+
+// loadSigningKey lê uma chave privada Ed25519 em PEM PKCS#8, como a gerada por
+// "openssl genpkey -algorithm ed25519".
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: nenhum bloco PEM", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: a chave não é Ed25519", path)
+	}
+	return priv, nil
+}
+
+// catalogSignature devolve a assinatura Ed25519 (base64) dos bytes exatos do catálogo,
+// gravada ao lado dele em catalog.json.sig para que clientes confiram o arquivo baixado com a
+// chave pública embutida.
+func catalogSignature(data []byte) ([]byte, error) {
+	key, err := loadSigningKey(cfg.SigningKey)
+	if err != nil {
+		return nil, err
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+	return []byte(sig + "\n"), nil
+}
+
+// ------------------------------------------
+// Várias chaves e rotação
+// ------------------------------------------
+
+// SigningKeyConfig é uma chave de signing_keys, com o período em que os clientes devem
+// aceitá-la. Para trocar de chave sem um "dia D", a nova entra com not_before futuro e a antiga
+// ganha not_after: durante a sobreposição, o catálogo sai assinado pelas duas.
+type SigningKeyConfig struct {
+	File      string `json:"file"`       // Chave Ed25519 em PEM PKCS#8
+	NotBefore string `json:"not_before"` // AAAA-MM-DD (vazio = desde sempre)
+	NotAfter  string `json:"not_after"`  // AAAA-MM-DD, inclusive (vazio = sem prazo)
+}
+
+// KeyManifest é o <catálogo>.keys: as chaves públicas e a validade de cada uma. Ele também é
+// assinado (em <catálogo>.keys.sigs), para que um cliente só aprenda uma chave nova através
+// de uma que já conhece.
+type KeyManifest struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Keys        []ManifestKey `json:"keys"`
+}
+
+type ManifestKey struct {
+	KeyID     string `json:"keyid"`
+	Algorithm string `json:"algorithm"`            // Sempre "ed25519"
+	PublicKey string `json:"public_key"`           // Os 32 bytes da chave pública, em base64
+	NotBefore string `json:"not_before,omitempty"` // AAAA-MM-DD
+	NotAfter  string `json:"not_after,omitempty"`  // AAAA-MM-DD, inclusive
+}
+
+// Signatures é o conteúdo de um arquivo .sigs: uma assinatura por chave válida.
+type Signatures struct {
+	Signatures []KeySignature `json:"signatures"`
+}
+
+type KeySignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"` // Ed25519 dos bytes exatos do arquivo, em base64
+}
+
+// signingKey é uma chave de signing_keys já carregada.
+type signingKey struct {
+	SigningKeyConfig
+	priv ed25519.PrivateKey
+	id   string
+}
+
+// keyID identifica a chave pelos 8 primeiros bytes do SHA-256 da chave pública, em hexadecimal.
+func keyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// loadSigningKeys carrega signing_keys e a signing_key (sem prazo, para que a chave antiga
+// continue assinando durante a migração para signing_keys; se ela também estiver em
+// signing_keys, vale o período de lá).
+func loadSigningKeys() ([]signingKey, error) {
+	configs := cfg.SigningKeys
+	if cfg.SigningKey != "" {
+		configs = append(slices.Clip(configs), SigningKeyConfig{File: cfg.SigningKey})
+	}
+	var keys []signingKey
+	seen := make(map[string]bool)
+	for _, c := range configs {
+		for _, date := range []string{c.NotBefore, c.NotAfter} {
+			if _, err := time.Parse(time.DateOnly, date); date != "" && err != nil {
+				return nil, fmt.Errorf("%s: data inválida %q (use AAAA-MM-DD)", c.File, date)
+			}
+		}
+		priv, err := loadSigningKey(c.File)
+		if err != nil {
+			return nil, err
+		}
+		id := keyID(priv.Public().(ed25519.PublicKey))
+		if seen[id] {
+			continue // A signing_key já listada em signing_keys
+		}
+		seen[id] = true
+		keys = append(keys, signingKey{SigningKeyConfig: c, priv: priv, id: id})
+	}
+	return keys, nil
+}
+
+// expired diz se a chave já passou do not_after (o dia inteiro vale).
+func (k signingKey) expired(now time.Time) bool {
+	if k.NotAfter == "" {
+		return false
+	}
+	end, _ := time.Parse(time.DateOnly, k.NotAfter)
+	return !now.Before(end.AddDate(0, 0, 1))
+}
+
+// signAll assina data com cada chave ainda não expirada. Chaves com not_before futuro também
+// assinam: os clientes só passam a aceitá-las na data, mas já encontram a assinatura pronta.
+func signAll(keys []signingKey, data []byte, now time.Time) ([]byte, error) {
+	var out Signatures
+	for _, k := range keys {
+		if k.expired(now) {
+			verbosef("     assinatura: chave %s expirou em %s; não assina mais", k.id, k.NotAfter)
+			continue
+		}
+		out.Signatures = append(out.Signatures, KeySignature{KeyID: k.id, Sig: base64.StdEncoding.EncodeToString(ed25519.Sign(k.priv, data))})
+	}
+	if len(out.Signatures) == 0 {
+		return nil, fmt.Errorf("todas as chaves de signing_keys expiraram")
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+// signedFile é um arquivo gravado ao lado do catálogo (sufixo no nome do catálogo).
+type signedFile struct {
+	suffix, contentType string
+	data                []byte
+}
+
+// catalogSignatureFiles devolve os arquivos de assinatura de data: o .sig da signing_key e,
+// com signing_keys, o .sigs (todas as chaves válidas), o manifesto .keys e o .keys.sigs.
+func catalogSignatureFiles(data []byte) ([]signedFile, error) {
+	var files []signedFile
+	if cfg.SigningKey != "" {
+		sig, err := catalogSignature(data)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, signedFile{".sig", "text/plain", sig})
+	}
+	if len(cfg.SigningKeys) == 0 {
+		return files, nil
+	}
+
+	keys, err := loadSigningKeys()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	manifest := KeyManifest{GeneratedAt: now.Truncate(time.Second)}
+	for _, k := range keys {
+		manifest.Keys = append(manifest.Keys, ManifestKey{
+			KeyID:     k.id,
+			Algorithm: "ed25519",
+			PublicKey: base64.StdEncoding.EncodeToString(k.priv.Public().(ed25519.PublicKey)),
+			NotBefore: k.NotBefore,
+			NotAfter:  k.NotAfter,
+		})
+	}
+	manifestData, _ := json.MarshalIndent(manifest, "", "  ")
+
+	sigs, err := signAll(keys, data, now)
+	if err != nil {
+		return nil, err
+	}
+	manifestSigs, err := signAll(keys, manifestData, now)
+	if err != nil {
+		return nil, err
+	}
+	return append(files,
+		signedFile{".sigs", "application/json", sigs},
+		signedFile{".keys", "application/json", manifestData},
+		signedFile{".keys.sigs", "application/json", manifestSigs},
+	), nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// SNAPSHOTS DO CATÁLOGO E ROLLBACK
+// ==========================================
+
+type SnapshotConfig struct {
+	Dir    string   `json:"dir"`     // Diretório local dos snapshots (vazio = desativado)
+	Keep   int      `json:"keep"`    // Quantos snapshots manter, dos mais recentes (0 = sem limite)
+	MaxAge Duration `json:"max_age"` // Remove snapshots mais antigos que isso (0 = sem limite)
+}
+
+func (s SnapshotConfig) enabled() bool {
+	return s.Dir != ""
+}
+
+// snapshotLayout dá nome aos arquivos (catalog-2024-06-01T12:00:00.json). Em UTC e com
+// largura fixa, a ordem alfabética é a cronológica.
+const snapshotLayout = "2006-01-02T15:04:05"
+
+// snapshotCatalog arquiva uma cópia do catálogo de produção recém-gravado e aplica a
+// retenção configurada.
+func snapshotCatalog(data []byte) error {
+	if err := os.MkdirAll(cfg.Snapshots.Dir, 0755); err != nil {
+		return err
+	}
+	name := "catalog-" + time.Now().UTC().Format(snapshotLayout) + ".json"
+	if err := os.WriteFile(filepath.Join(cfg.Snapshots.Dir, name), data, 0644); err != nil {
+		return err
+	}
+	debugf("     snapshot %s", name)
+	return pruneSnapshots()
+}
+
+// listSnapshots devolve os snapshots existentes, do mais antigo para o mais recente.
+func listSnapshots() ([]string, error) {
+	entries, err := os.ReadDir(cfg.Snapshots.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if _, ok := snapshotTime(entry.Name()); ok && !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// snapshotTime extrai o horário do nome de um snapshot.
+func snapshotTime(name string) (time.Time, bool) {
+	stamp, ok := strings.CutPrefix(name, "catalog-")
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(snapshotLayout, strings.TrimSuffix(stamp, ".json"))
+	return t, err == nil
+}
+
+// pruneSnapshots remove o que passa de snapshots.keep ou de snapshots.max_age. O mais
+// recente nunca é removido.
+func pruneSnapshots() error {
+	names, err := listSnapshots()
+	if err != nil {
+		return err
+	}
+	for i, name := range names[:max(len(names)-1, 0)] {
+		expired := cfg.Snapshots.Keep > 0 && len(names)-i > cfg.Snapshots.Keep
+		if t, _ := snapshotTime(name); cfg.Snapshots.MaxAge > 0 && time.Since(t) > time.Duration(cfg.Snapshots.MaxAge) {
+			expired = true
+		}
+		if !expired {
+			continue
+		}
+		if err := os.Remove(filepath.Join(cfg.Snapshots.Dir, name)); err != nil {
+			return err
+		}
+		debugf("     snapshot %s removido (retenção)", name)
+	}
+	return nil
+}
+
+// runRollback restaura um snapshot como catálogo de produção e o publica como o promote.
+// Sem argumento, lista os snapshots disponíveis.
+func runRollback(args []string) {
+	if !cfg.Snapshots.enabled() {
+		log.Fatal("rollback: snapshots desativados (configure snapshots.dir)")
+	}
+	if len(args) == 0 {
+		names, err := listSnapshots()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(names) == 0 {
+			infof(">>> Nenhum snapshot em %s.", cfg.Snapshots.Dir)
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return
+	}
+	if len(args) > 1 {
+		log.Fatal("Uso: generator rollback [snapshot]")
+	}
+
+	// Aceita o nome listado ou um caminho qualquer
+	path := args[0]
+	if _, err := os.Stat(path); err != nil {
+		path = filepath.Join(cfg.Snapshots.Dir, args[0])
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Falha ao ler o snapshot: %v", err)
+	}
+	var catalog Catalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		log.Fatalf("Snapshot inválido %s: %v", path, err)
+	}
+	if catalog.Apps == nil {
+		catalog.Apps = make(map[string]CatalogApp)
+	}
+
+	saveCatalog(cfg.CatalogPath, catalog)
+	infof(">>> Catálogo %s restaurado de %s (%d apps).", cfg.CatalogPath, path, len(catalog.Apps))
+	if !publishProduction() {
+		os.Exit(exitPublishFailed)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// sourceExtensions são as extensões reconhecidas como arquivos de fontes.
+// Todas compartilham o mesmo schema de SourceApp (as chaves do JSON).
+var sourceExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// ==========================================
+// CARREGAMENTO DAS FONTES
+// ==========================================
+
+// defaultSourcesPath procura apps.source.* no diretório atual, na ordem de sourceExtensions.
+// Se nenhum existir, devolve o nome JSON tradicional para que o erro seja claro.
+func defaultSourcesPath() string {
+	for _, ext := range sourceExtensions {
+		candidate := "apps.source" + ext
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return "apps.source.json"
+}
+
+// loadSources carrega as fontes a partir de uma lista de arquivos e/ou diretórios.
+// Diretórios (ex: sources.d/) têm todos os seus arquivos de fontes carregados em ordem alfabética,
+// permitindo organizar um catálogo grande por categoria. IDs duplicados abortam a execução.
+func loadSources(paths ...string) []SourceApp {
+	sources, err := readSources(paths...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return sources
+}
+
+// readSources é a versão de loadSources que devolve o erro, para o modo servidor,
+// que relê as fontes a cada refresh sem derrubar o processo.
+func readSources(paths ...string) ([]SourceApp, error) {
+	files, err := expandSourcePaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []SourceApp
+	origin := make(map[string]string) // ID -> arquivo onde foi declarado
+
+	for _, file := range files {
+		fileSources, err := readSourceFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, src := range fileSources {
+			if prev, dup := origin[src.ID]; dup {
+				return nil, fmt.Errorf("ID duplicado '%s' em %s (já declarado em %s)", src.ID, file, prev)
+			}
+			origin[src.ID] = file
+			sources = append(sources, src)
+		}
+	}
+
+	return sources, nil
+}
+
+// selectSources aplica os filtros --only, --skip e --tag, devolvendo as fontes a processar e
+// as que ficaram de fora. IDs de --only que não existem abortam, para que um erro de digitação
+// não vire uma execução vazia.
+func selectSources(sources []SourceApp) (selected, filtered []SourceApp) {
+	only, skip, tags := toSet(cfg.Only), toSet(cfg.Skip), toSet(cfg.Tags)
+
+	known := make(map[string]bool)
+	for _, src := range sources {
+		known[src.ID] = true
+	}
+	for id := range only {
+		if !known[id] {
+			log.Fatalf("--only: fonte desconhecida '%s'", id)
+		}
+	}
+
+	for _, src := range sources {
+		keep := !skip[src.ID] && (len(only) == 0 || only[src.ID])
+		if keep && len(tags) > 0 {
+			keep = false
+			for _, tag := range src.Tags {
+				if tags[tag] {
+					keep = true
+					break
+				}
+			}
+		}
+		if keep {
+			selected = append(selected, src)
+		} else {
+			filtered = append(filtered, src)
+		}
+	}
+	return selected, filtered
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+// expandSourcePaths resolve a lista de caminhos em arquivos concretos,
+// expandindo diretórios para os arquivos de fonte que contêm.
+func expandSourcePaths(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+
+		var matches []string
+		for _, ext := range sourceExtensions {
+			found, err := filepath.Glob(filepath.Join(p, "*"+ext))
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, found...)
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("nenhum arquivo de fontes encontrado em %v", paths)
+	}
+	return files, nil
+}
+
+// readSourceFile lê um único arquivo de fontes, detectando o formato pela extensão.
+// JSON e YAML são uma lista de apps; TOML, que exige uma tabela na raiz, usa [[apps]].
+func readSourceFile(path string) ([]SourceApp, error) {
+	data, err := readAsJSON(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.ToLower(filepath.Ext(path)) == ".toml" {
+		var table struct {
+			Apps []SourceApp `json:"apps"`
+		}
+		if err := json.Unmarshal(data, &table); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return table.Apps, nil
+	}
+
+	var sources []SourceApp
+	if err := json.Unmarshal(data, &sources); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return sources, nil
+}
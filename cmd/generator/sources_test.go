@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// A mesma fonte, escrita com os tipos nativos de cada formato, deve carregar igual.
+func TestReadSourceFileFormats(t *testing.T) {
+	files := map[string]string{
+		"apps.source.json": `[
+  {"id": "foo", "name": "Foo", "install_type": "deb", "strategy": "github_release",
+   "config": {"repo": "acme/foo", "include_prereleases": true, "max_releases": 5, "asset_regex": "amd64\\.deb$"},
+   "fallbacks": [{"strategy": "direct_url_head", "config": {"url": "https://acme.org/foo.deb", "follow": false}}]}
+]`,
+		"apps.source.yaml": `
+- id: foo
+  name: Foo
+  install_type: deb
+  strategy: github_release
+  config:
+    repo: acme/foo
+    include_prereleases: true
+    max_releases: 5
+    asset_regex: 'amd64\.deb$'
+  fallbacks:
+    - strategy: direct_url_head
+      config:
+        url: https://acme.org/foo.deb
+        follow: false
+`,
+		"apps.source.toml": `
+[[apps]]
+id = "foo"
+name = "Foo"
+install_type = "deb"
+strategy = "github_release"
+
+[apps.config]
+repo = "acme/foo"
+include_prereleases = true
+max_releases = 5
+asset_regex = 'amd64\.deb$'
+
+[[apps.fallbacks]]
+strategy = "direct_url_head"
+config = { url = "https://acme.org/foo.deb", follow = false }
+`,
+	}
+
+	want := SourceApp{
+		ID: "foo", Name: "Foo", InstallType: "deb", Strategy: "github_release",
+		Config: ConfigMap{"repo": "acme/foo", "include_prereleases": "true", "max_releases": "5", "asset_regex": `amd64\.deb$`},
+		Fallbacks: []SourceFallback{{Strategy: "direct_url_head",
+			Config: ConfigMap{"url": "https://acme.org/foo.deb", "follow": "false"}}},
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		t.Run(filepath.Ext(name), func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			sources, err := readSourceFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sources) != 1 {
+				t.Fatalf("%d fontes, esperada 1", len(sources))
+			}
+			if !reflect.DeepEqual(sources[0], want) {
+				t.Errorf("fonte = %+v\nesperada %+v", sources[0], want)
+			}
+		})
+	}
+}
+
+func TestConfigMapRejectsNested(t *testing.T) {
+	var c ConfigMap
+	if err := c.UnmarshalJSON([]byte(`{"repo": {"owner": "acme"}}`)); err == nil {
+		t.Error("objeto aninhado em config deveria ser rejeitado")
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import "time"
+
+// ==========================================
+// FALHAS CONSECUTIVAS E ENTRADAS STALE
+// ==========================================
+
+// trackFailures atualiza na entrada o contador de falhas consecutivas e a marca stale: uma
+// checagem bem-sucedida zera os dois; uma falha incrementa o contador e, se a última
+// verificação bem-sucedida for mais antiga que cfg.StaleAfter, marca a entrada como stale
+// (res.Stale indica que isso aconteceu nesta execução, para notificar uma vez só).
+func trackFailures(res *appResult) {
+	if !res.HasApp || res.Orphaned {
+		return
+	}
+	if res.Checked {
+		res.App.ConsecutiveFailures, res.App.Stale = 0, false
+		res.App.BreakerUntil = time.Time{}
+		return
+	}
+	if res.Err == nil {
+		return
+	}
+
+	res.App.ConsecutiveFailures++
+	tripBreaker(res)
+	if cfg.StaleAfter <= 0 || res.App.Stale {
+		return
+	}
+	verified := res.App.LastChecked
+	if verified.IsZero() {
+		verified = res.App.LastUpdated // Entradas anteriores ao last_checked
+	}
+	if !verified.IsZero() && time.Since(verified) > time.Duration(cfg.StaleAfter) {
+		res.App.Stale, res.Stale = true, true
+		errorf(" [STALE] %s: sem verificação bem-sucedida desde %s (%d falhas seguidas).",
+			res.ID, verified.Format(time.RFC3339), res.App.ConsecutiveFailures)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import "runtime/debug"
+
+// ==========================================
+// ESTATÍSTICAS DO CATÁLOGO
+// ==========================================
+
+// generatorVersion identifica o gerador que produziu o catálogo.
+// Pode ser fixado no build: go build -ldflags "-X main.generatorVersion=1.2.3"
+var generatorVersion = "dev"
+
+// CatalogStats resume o catálogo para clientes e dashboards (saúde do registro num relance).
+type CatalogStats struct {
+	AppCount         int            `json:"app_count"`
+	TotalBytes       int64          `json:"total_bytes"`
+	InstallTypes     map[string]int `json:"install_types"`
+	GeneratorVersion string         `json:"generator_version"`
+}
+
+func computeStats(apps map[string]CatalogApp) CatalogStats {
+	stats := CatalogStats{
+		InstallTypes:     make(map[string]int),
+		GeneratorVersion: resolveGeneratorVersion(),
+	}
+	for _, app := range apps {
+		stats.AppCount++
+		stats.TotalBytes += app.Size
+		stats.InstallTypes[app.InstallType]++
+	}
+	return stats
+}
+
+// resolveGeneratorVersion usa a versão injetada no build ou, na falta dela,
+// a revisão do git registrada pelo toolchain (ex: "dev+994b402").
+func resolveGeneratorVersion() string {
+	if generatorVersion != "dev" {
+		return generatorVersion
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return generatorVersion
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" && len(setting.Value) >= 7 {
+			return generatorVersion + "+" + setting.Value[:7]
+		}
+	}
+	return generatorVersion
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ==========================================
+// ARMAZENAMENTO DO CATÁLOGO
+// ==========================================
+
+// blobStore lê e grava um único objeto (o catalog.json) num backend de armazenamento.
+// Read devolve um erro que satisfaz errors.Is(err, os.ErrNotExist) quando o objeto não existe.
+type blobStore interface {
+	Read() ([]byte, error)
+	Write(data []byte, contentType string) error
+}
+
+// openStore escolhe o backend pelo esquema do local: caminhos comuns (ou file://) usam o
+// disco; s3://bucket/chave usa S3 ou compatível (MinIO); gs://bucket/objeto usa o Google
+// Cloud Storage; az://conta/container/blob usa o Azure Blob Storage.
+func openStore(location string) (blobStore, error) {
+	scheme, rest, found := strings.Cut(location, "://")
+	if !found {
+		return fileStore(location), nil
+	}
+
+	switch scheme {
+	case "file":
+		return fileStore(rest), nil
+	case "s3":
+		bucket, key, _ := strings.Cut(rest, "/")
+		if bucket == "" || key == "" {
+			return nil, fmt.Errorf("local S3 inválido: %s (esperado s3://bucket/chave)", location)
+		}
+		return newS3Store(bucket, key), nil
+	case "gs":
+		bucket, object, _ := strings.Cut(rest, "/")
+		if bucket == "" || object == "" {
+			return nil, fmt.Errorf("local GCS inválido: %s (esperado gs://bucket/objeto)", location)
+		}
+		return gcsStore{bucket: bucket, object: object}, nil
+	case "az":
+		parts := strings.SplitN(rest, "/", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("local Azure inválido: %s (esperado az://conta/container/blob)", location)
+		}
+		return azureStore{account: parts[0], container: parts[1], blob: parts[2]}, nil
+	default:
+		return nil, fmt.Errorf("esquema de armazenamento não suportado: %s", scheme)
+	}
+}
+
+// localPath devolve o caminho em disco de location, ou false se o backend for remoto.
+func localPath(location string) (string, bool) {
+	store, err := openStore(location)
+	if err != nil {
+		return "", false
+	}
+	file, ok := store.(fileStore)
+	return string(file), ok
+}
+
+// readBlob lê o objeto em location pelo backend correspondente.
+func readBlob(location string) ([]byte, error) {
+	store, err := openStore(location)
+	if err != nil {
+		return nil, err
+	}
+	return store.Read()
+}
+
+// writeBlob grava data em location pelo backend correspondente.
+func writeBlob(location string, data []byte, contentType string) error {
+	store, err := openStore(location)
+	if err != nil {
+		return err
+	}
+	return store.Write(data, contentType)
+}
+
+// fileStore é o backend em disco local.
+type fileStore string
+
+func (f fileStore) Read() ([]byte, error) {
+	path, err := url.PathUnescape(string(f))
+	if err != nil {
+		path = string(f)
+	}
+	return os.ReadFile(path)
+}
+
+func (f fileStore) Write(data []byte, _ string) error {
+	path := string(f)
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// storageError resume uma resposta de erro de um backend HTTP (status e início do corpo).
+func storageError(backend string, resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s status %d: %s", backend, resp.StatusCode, strings.TrimSpace(string(msg)))
+}
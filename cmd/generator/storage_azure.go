@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// ARMAZENAMENTO: AZURE BLOB STORAGE
+// ==========================================
+
+// AzureConfig ajusta o acesso ao Azure Blob Storage. A autorização é um SAS token com
+// permissão de leitura e escrita no container, lido de AZURE_STORAGE_SAS_TOKEN.
+type AzureConfig struct {
+	Endpoint string `json:"endpoint"` // padrão: https://{conta}.blob.core.windows.net (Azurite: http://127.0.0.1:10000/{conta})
+}
+
+type azureStore struct {
+	account, container, blob string
+}
+
+func (a azureStore) blobURL() string {
+	endpoint := strings.ReplaceAll(cfg.Azure.Endpoint, "{conta}", a.account)
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", a.account)
+	}
+	u := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(endpoint, "/"), a.container, awsURIEncode(a.blob))
+	if sas := strings.TrimPrefix(os.Getenv("AZURE_STORAGE_SAS_TOKEN"), "?"); sas != "" {
+		u += "?" + sas
+	}
+	return u
+}
+
+func (a azureStore) Read() ([]byte, error) {
+	resp, err := a.do("GET", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return nil, fmt.Errorf("az://%s/%s/%s: %w", a.account, a.container, a.blob, os.ErrNotExist)
+	}
+	if resp.StatusCode != 200 {
+		return nil, storageError("azure", resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (a azureStore) Write(data []byte, contentType string) error {
+	resp, err := a.do("PUT", data, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 201 {
+		return storageError("azure", resp)
+	}
+	return nil
+}
+
+func (a azureStore) do(method string, body []byte, contentType string) (*http.Response, error) {
+	req, err := http.NewRequest(method, a.blobURL(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-version", "2021-08-06")
+	if method == "PUT" {
+		req.Header.Set("x-ms-blob-type", "BlockBlob")
+		if contentType != "" {
+			req.Header.Set("x-ms-blob-content-type", contentType)
+		}
+	}
+
+	debugf("     %s az://%s/%s/%s", method, a.account, a.container, a.blob)
+	client := &http.Client{Timeout: time.Duration(cfg.HTTPTimeout)}
+	return client.Do(req)
+}
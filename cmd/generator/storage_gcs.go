@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// ARMAZENAMENTO: GOOGLE CLOUD STORAGE
+// ==========================================
+
+// GCSConfig ajusta o acesso ao Google Cloud Storage (API JSON). O token OAuth vem de
+// GOOGLE_OAUTH_ACCESS_TOKEN (ex: saída de google-github-actions/auth ou
+// `gcloud auth print-access-token`).
+type GCSConfig struct {
+	Endpoint string `json:"endpoint"` // padrão: STORAGE_EMULATOR_HOST ou https://storage.googleapis.com
+}
+
+type gcsStore struct {
+	bucket, object string
+}
+
+func (g gcsStore) endpoint() string {
+	if cfg.GCS.Endpoint != "" {
+		return strings.TrimSuffix(cfg.GCS.Endpoint, "/")
+	}
+	if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+		if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		return strings.TrimSuffix(host, "/")
+	}
+	return "https://storage.googleapis.com"
+}
+
+func (g gcsStore) Read() ([]byte, error) {
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", g.endpoint(), url.PathEscape(g.bucket), url.PathEscape(g.object))
+	resp, err := g.do("GET", u, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return nil, fmt.Errorf("gs://%s/%s: %w", g.bucket, g.object, os.ErrNotExist)
+	}
+	if resp.StatusCode != 200 {
+		return nil, storageError("gcs", resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (g gcsStore) Write(data []byte, contentType string) error {
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s", g.endpoint(), url.PathEscape(g.bucket), url.QueryEscape(g.object))
+	resp, err := g.do("POST", u, data, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return storageError("gcs", resp)
+	}
+	return nil
+}
+
+func (g gcsStore) do(method, u string, body []byte, contentType string) (*http.Response, error) {
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	debugf("     %s %s", method, u)
+	client := &http.Client{Timeout: time.Duration(cfg.HTTPTimeout)}
+	return client.Do(req)
+}
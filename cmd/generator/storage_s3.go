@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// ARMAZENAMENTO: S3 / MINIO
+// ==========================================
+
+// S3Config ajusta o acesso a S3 ou serviços compatíveis. As credenciais seguem as variáveis
+// padrão da AWS (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN).
+type S3Config struct {
+	Endpoint string `json:"endpoint"` // ex: https://minio.local:9000 (vazio = AWS); usa path-style
+	Region   string `json:"region"`   // padrão: AWS_REGION ou us-east-1
+}
+
+type s3Store struct {
+	bucket, key string
+}
+
+func newS3Store(bucket, key string) s3Store {
+	return s3Store{bucket: bucket, key: key}
+}
+
+func (s s3Store) region() string {
+	if cfg.S3.Region != "" {
+		return cfg.S3.Region
+	}
+	if r := os.Getenv("AWS_REGION"); r != "" {
+		return r
+	}
+	return "us-east-1"
+}
+
+// objectURL monta a URL do objeto: virtual-hosted na AWS, path-style em endpoints próprios.
+func (s s3Store) objectURL() string {
+	endpoint := cfg.S3.Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	if endpoint == "" {
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.region(), awsURIEncode(s.key))
+	}
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(endpoint, "/"), s.bucket, awsURIEncode(s.key))
+}
+
+func (s s3Store) Read() ([]byte, error) {
+	resp, err := s.do("GET", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return nil, fmt.Errorf("s3://%s/%s: %w", s.bucket, s.key, os.ErrNotExist)
+	}
+	if resp.StatusCode != 200 {
+		return nil, storageError("s3", resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (s s3Store) Write(data []byte, contentType string) error {
+	resp, err := s.do("PUT", data, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return storageError("s3", resp)
+	}
+	return nil
+}
+
+// do executa a requisição assinada com AWS Signature V4.
+func (s s3Store) do(method string, body []byte, contentType string) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.objectURL(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("s3: AWS_ACCESS_KEY_ID e AWS_SECRET_ACCESS_KEY são obrigatórios")
+	}
+	signAWSv4(req, body, accessKey, secretKey, os.Getenv("AWS_SESSION_TOKEN"), s.region(), "s3", time.Now().UTC())
+
+	debugf("     %s %s", method, req.URL)
+	client := &http.Client{Timeout: time.Duration(cfg.HTTPTimeout)}
+	return client.Do(req)
+}
+
+// signAWSv4 assina a requisição (cabeçalho Authorization) conforme AWS Signature Version 4.
+func signAWSv4(req *http.Request, body []byte, accessKey, secretKey, sessionToken, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	var names []string
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.TrimSpace(req.Header.Get(name)))
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// awsURIEncode codifica a chave do objeto segmento a segmento, como o S3 espera na assinatura.
+func awsURIEncode(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+)
+
+// ==========================================
+// ESTRATÉGIA: MSIX (.appinstaller)
+// ==========================================
+
+// checkAppInstaller lê o .appinstaller que o fornecedor publica para apps MSIX. Config aceita:
+//   - url: URL do .appinstaller (obrigatório)
+//
+// A versão e a URL vêm do MainPackage (ou MainBundle): o atributo Version da raiz é a versão do
+// próprio .appinstaller, não do app. O pacote .msix/.msixbundle é baixado para o hash.
+func checkAppInstaller(ctx context.Context, src SourceApp) (checkResult, error) {
+	conf := src.Config
+	if conf["url"] == "" {
+		return checkResult{}, fmt.Errorf("appinstaller: 'url' é obrigatório")
+	}
+
+	body, base, err := sourceFetch(ctx, conf, conf["url"], "")
+	if err != nil {
+		return checkResult{}, fmt.Errorf("appinstaller: %w", err)
+	}
+
+	// <AppInstaller xmlns="http://schemas.microsoft.com/appx/appinstaller/..." Version="...">
+	//   <MainPackage Name="..." Version="1.2.3.0" Uri="https://.../App.msix"/>
+	type pkg struct {
+		Name    string `xml:"Name,attr"`
+		Version string `xml:"Version,attr"`
+		URI     string `xml:"Uri,attr"`
+	}
+	var doc struct {
+		XMLName     xml.Name `xml:"AppInstaller"`
+		MainPackage *pkg     `xml:"MainPackage"`
+		MainBundle  *pkg     `xml:"MainBundle"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return checkResult{}, fmt.Errorf("appinstaller: xml inválido: %w", err)
+	}
+	entry := doc.MainPackage
+	if entry == nil {
+		entry = doc.MainBundle
+	}
+	if entry == nil || entry.Version == "" || entry.URI == "" {
+		return checkResult{}, fmt.Errorf("appinstaller: sem MainPackage/MainBundle com Version e Uri")
+	}
+
+	ref, err := url.Parse(entry.URI)
+	if err != nil {
+		return checkResult{}, fmt.Errorf("appinstaller: Uri inválida: %w", err)
+	}
+	return checkResult{Version: entry.Version, URL: base.ResolveReference(ref).String()}, nil
+}
@@ -0,0 +1,99 @@
+//go:build chromedp
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// ==========================================
+// ESTRATÉGIA: NAVEGADOR HEADLESS (chromedp)
+// ==========================================
+
+// checkBrowser renderiza a página com um Chrome headless, para fornecedores que só expõem
+// o link de download depois que o JavaScript roda. Só existe em builds com -tags chromedp
+// e exige Chrome/Chromium instalado. Config aceita:
+//   - url: página de download (obrigatório)
+//   - selector: seletor CSS do link de download (obrigatório)
+//   - attribute: atributo com a URL (padrão: href)
+//   - version_selector: seletor CSS cujo texto contém a versão (padrão: o próprio link)
+//   - regex: 1º grupo = versão, aplicado ao texto de version_selector ou à URL do link
+//   - timeout: duração máxima da renderização (padrão: 60s)
+func checkBrowser(ctx context.Context, src SourceApp) (checkResult, error) {
+	conf := src.Config
+	if conf["url"] == "" || conf["selector"] == "" {
+		return checkResult{}, fmt.Errorf("browser: 'url' e 'selector' são obrigatórios")
+	}
+	re, err := regexp.Compile(conf["regex"])
+	if err != nil || conf["regex"] == "" {
+		return checkResult{}, fmt.Errorf("browser: 'regex' inválido ou ausente")
+	}
+
+	attribute := conf["attribute"]
+	if attribute == "" {
+		attribute = "href"
+	}
+	timeout := 60 * time.Second
+	if v := conf["timeout"]; v != "" {
+		if timeout, err = time.ParseDuration(v); err != nil {
+			return checkResult{}, fmt.Errorf("browser: timeout inválido: %w", err)
+		}
+	}
+
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(ctx, chromedp.DefaultExecAllocatorOptions[:]...)
+	defer cancelAlloc()
+	ctx, cancel := chromedp.NewContext(allocCtx)
+	defer cancel()
+	ctx, cancelTimeout := context.WithTimeout(ctx, timeout)
+	defer cancelTimeout()
+
+	var link, location, versionText string
+	var found bool
+	actions := []chromedp.Action{
+		chromedp.Navigate(conf["url"]),
+		chromedp.WaitReady(conf["selector"], chromedp.ByQuery),
+		chromedp.AttributeValue(conf["selector"], attribute, &link, &found, chromedp.ByQuery),
+		chromedp.Location(&location),
+	}
+	if sel := conf["version_selector"]; sel != "" {
+		actions = append(actions, chromedp.Text(sel, &versionText, chromedp.ByQuery))
+	}
+
+	debugf("     [browser] %s", conf["url"])
+	if err := chromedp.Run(ctx, actions...); err != nil {
+		return checkResult{}, fmt.Errorf("browser: %w", err)
+	}
+	if !found || link == "" {
+		return checkResult{}, fmt.Errorf("browser: atributo '%s' ausente em '%s'", attribute, conf["selector"])
+	}
+
+	// Links relativos são resolvidos contra a página final (após redirects)
+	base, err := url.Parse(location)
+	if err != nil {
+		return checkResult{}, err
+	}
+	ref, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return checkResult{}, err
+	}
+	downloadURL := base.ResolveReference(ref).String()
+	verbosef("     URL encontrada: %s", downloadURL)
+
+	target := downloadURL
+	if conf["version_selector"] != "" {
+		target = versionText
+	}
+	matches := re.FindStringSubmatch(target)
+	if len(matches) < 2 {
+		return checkResult{}, fmt.Errorf("regex falhou em: %s", target)
+	}
+
+	return checkResult{Version: matches[1], URL: downloadURL, Matches: matches}, nil
+}
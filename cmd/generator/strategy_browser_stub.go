@@ -0,0 +1,14 @@
+//go:build !chromedp
+
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// checkBrowser só está disponível em builds com -tags chromedp (ver strategy_browser.go),
+// para que o binário padrão não dependa do Chrome.
+func checkBrowser(_ context.Context, src SourceApp) (checkResult, error) {
+	return checkResult{}, fmt.Errorf("estratégia 'browser' requer build com -tags chromedp")
+}
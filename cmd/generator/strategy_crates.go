@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// ESTRATÉGIA: CRATES.IO
+// ==========================================
+
+// cratesIODefault é a API usada quando a fonte não define 'url'.
+const cratesIODefault = "https://crates.io"
+
+// checkCrates resolve a versão de uma ferramenta Rust pela API do crates.io. Config aceita:
+//   - crate: nome do crate (obrigatório)
+//   - url: registry compatível (padrão: crates.io)
+//   - pattern: regex que a versão deve casar (ex: fixar uma linha)
+//   - include_prereleases: "true" considera versões como 1.0.0-beta.1
+//
+// Versões retiradas (yanked) nunca são escolhidas. O sha256 publicado pelo registry acompanha
+// o tamanho do .crate, então o download normalmente é dispensado.
+func checkCrates(ctx context.Context, src SourceApp) (checkResult, error) {
+	conf := src.Config
+	name := conf["crate"]
+	if name == "" {
+		return checkResult{}, fmt.Errorf("crates: 'crate' é obrigatório")
+	}
+	pattern, err := optionalPattern("crates", conf)
+	if err != nil {
+		return checkResult{}, err
+	}
+	base := strings.TrimSuffix(conf["url"], "/")
+	if base == "" {
+		base = cratesIODefault
+	}
+
+	var resp struct {
+		Versions []struct {
+			Num       string    `json:"num"`
+			DLPath    string    `json:"dl_path"`
+			Yanked    bool      `json:"yanked"`
+			Checksum  string    `json:"checksum"`
+			CrateSize int64     `json:"crate_size"`
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"versions"`
+	}
+	if err := sourceGetJSON(ctx, conf, base+"/api/v1/crates/"+url.PathEscape(name), &resp); err != nil {
+		return checkResult{}, fmt.Errorf("crates: %w", err)
+	}
+
+	best := -1
+	for i, v := range resp.Versions {
+		if v.Yanked || !eligibleVersion(conf, pattern, v.Num) {
+			continue
+		}
+		if best < 0 || compareVersions(versionScheme(conf), v.Num, resp.Versions[best].Num) > 0 {
+			best = i
+		}
+	}
+	if best < 0 {
+		return checkResult{}, fmt.Errorf("crates: nenhuma versão elegível de %s", name)
+	}
+	v := resp.Versions[best]
+
+	res := checkResult{Version: v.Num, URL: base + v.DLPath, ReleasedAt: v.CreatedAt.UTC()}
+	if base == cratesIODefault {
+		// O dl_path redireciona para a CDN; publicamos a URL final, estável
+		res.URL = fmt.Sprintf("https://static.crates.io/crates/%s/%s-%s.crate", name, name, v.Num)
+	}
+	if v.Checksum != "" && v.CrateSize > 0 {
+		res.Checksum, res.Size = v.Checksum, v.CrateSize
+	}
+	return res, nil
+}
+
+// optionalPattern compila o 'pattern' opcional das estratégias de registries; nil sem pattern.
+func optionalPattern(strategy string, conf map[string]string) (*regexp.Regexp, error) {
+	if conf["pattern"] == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(conf["pattern"])
+	if err != nil {
+		return nil, fmt.Errorf("%s: pattern inválido: %w", strategy, err)
+	}
+	return re, nil
+}
+
+// eligibleVersion aplica pattern e include_prereleases (versão semver com "-") a uma versão.
+func eligibleVersion(conf map[string]string, pattern *regexp.Regexp, version string) bool {
+	if pattern != nil && !pattern.MatchString(version) {
+		return false
+	}
+	return conf["include_prereleases"] == "true" || !strings.Contains(version, "-")
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ==========================================
+// ESTRATÉGIA: ELECTRON-UPDATER (latest.yml)
+// ==========================================
+
+// checkElectron lê o latest.yml (latest-linux.yml, latest-mac.yml...) publicado pelo
+// electron-builder para o electron-updater. Config aceita:
+//   - url: URL do arquivo .yml (obrigatório)
+//   - file_filter: trecho do nome que escolhe entre os arquivos listados (ex: ".AppImage",
+//     "arm64"); padrão: o 'path' principal do .yml
+//
+// O .yml já traz versão, tamanho e sha512 de cada arquivo, então o download é dispensado: o
+// catálogo publica o sha512 e deixa o checksum (SHA-256) vazio.
+func checkElectron(ctx context.Context, src SourceApp) (checkResult, error) {
+	conf := src.Config
+	if conf["url"] == "" {
+		return checkResult{}, fmt.Errorf("electron: 'url' é obrigatório")
+	}
+
+	body, base, err := sourceFetch(ctx, conf, conf["url"], "")
+	if err != nil {
+		return checkResult{}, fmt.Errorf("electron: %w", err)
+	}
+	var doc struct {
+		Version string `yaml:"version"`
+		Files   []struct {
+			URL    string `yaml:"url"`
+			SHA512 string `yaml:"sha512"`
+			Size   int64  `yaml:"size"`
+		} `yaml:"files"`
+		Path        string `yaml:"path"`
+		SHA512      string `yaml:"sha512"`
+		ReleaseDate string `yaml:"releaseDate"`
+	}
+	if err := yaml.Unmarshal(body, &doc); err != nil {
+		return checkResult{}, fmt.Errorf("electron: yml inválido: %w", err)
+	}
+	if doc.Version == "" {
+		return checkResult{}, fmt.Errorf("electron: yml sem 'version'")
+	}
+
+	// Arquivo escolhido: o primeiro que casar com file_filter, ou o 'path' principal (o
+	// electron-builder antigo só preenchia path/sha512, sem 'files')
+	filter := strings.ToLower(conf["file_filter"])
+	file, sha512, size := "", "", int64(0)
+	for _, f := range doc.Files {
+		if (filter != "" && strings.Contains(strings.ToLower(f.URL), filter)) || (filter == "" && f.URL == doc.Path) {
+			file, sha512, size = f.URL, f.SHA512, f.Size
+			break
+		}
+	}
+	if file == "" && filter == "" {
+		file, sha512 = doc.Path, doc.SHA512
+		if file == "" && len(doc.Files) > 0 {
+			file, sha512, size = doc.Files[0].URL, doc.Files[0].SHA512, doc.Files[0].Size
+		}
+	}
+	if file == "" {
+		return checkResult{}, fmt.Errorf("electron: nenhum arquivo do yml casa com '%s'", conf["file_filter"])
+	}
+
+	ref, err := url.Parse(file)
+	if err != nil {
+		return checkResult{}, fmt.Errorf("electron: %w", err)
+	}
+	res := checkResult{Version: doc.Version, URL: base.ResolveReference(ref).String(), Size: size}
+	if raw, err := base64.StdEncoding.DecodeString(sha512); err == nil && len(raw) == 64 {
+		res.SHA512 = hex.EncodeToString(raw)
+	}
+	if t, err := time.Parse(time.RFC3339, doc.ReleaseDate); err == nil {
+		res.ReleasedAt = t.UTC()
+	}
+	return res, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// ESTRATÉGIA: PLUGIN EXTERNO (exec)
+// ==========================================
+
+// execOutput é o JSON que o plugin deve escrever no stdout.
+type execOutput struct {
+	Version    string    `json:"version"`
+	URL        string    `json:"url"`
+	Size       int64     `json:"size"`
+	Checksum   string    `json:"checksum"` // SHA256 opcional; com size, dispensa o download
+	ReleasedAt time.Time `json:"released_at"`
+}
+
+// checkExec executa um programa externo que recebe o SourceApp (JSON) no stdin e devolve
+// execOutput no stdout, permitindo novos fornecedores sem alterar o gerador. Config aceita:
+//   - command: executável (obrigatório)
+//   - args: argumentos separados por espaço
+//   - timeout: duração máxima da execução (padrão: 60s)
+//
+// O stderr do plugin aparece no log em modo -vv e na mensagem de erro quando ele falha.
+func checkExec(ctx context.Context, src SourceApp) (checkResult, error) {
+	command := src.Config["command"]
+	if command == "" {
+		return checkResult{}, fmt.Errorf("exec: 'command' não configurado")
+	}
+
+	timeout := 60 * time.Second
+	if v := src.Config["timeout"]; v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return checkResult{}, fmt.Errorf("exec: timeout inválido: %w", err)
+		}
+		timeout = d
+	}
+
+	input, err := json.Marshal(src)
+	if err != nil {
+		return checkResult{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command, strings.Fields(src.Config["args"])...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	debugf("     EXEC %s %s", command, src.Config["args"])
+	err = cmd.Run()
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		debugf("     stderr: %s", msg)
+	}
+	if err != nil {
+		return checkResult{}, fmt.Errorf("exec %s: %v: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var out execOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return checkResult{}, fmt.Errorf("exec %s: saída inválida: %w", command, err)
+	}
+	if out.Version == "" || out.URL == "" {
+		return checkResult{}, fmt.Errorf("exec %s: 'version' e 'url' são obrigatórios na saída", command)
+	}
+
+	return checkResult{
+		Version:    out.Version,
+		URL:        out.URL,
+		Size:       out.Size,
+		Checksum:   out.Checksum,
+		ReleasedAt: out.ReleasedAt,
+	}, nil
+}
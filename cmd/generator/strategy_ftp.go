@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+)
+
+// ==========================================
+// ESTRATÉGIA: DIRETÓRIO FTP/FTPS
+// ==========================================
+
+// checkFTP lista um diretório FTP e escolhe o arquivo mais novo que casa com o padrão,
+// para fornecedores (firmware, embarcados) que só publicam via FTP. Config aceita:
+//   - url: diretório, ex: ftp://ftp.vendor.com/pub/firmware/ (ftps:// = TLS implícito,
+//     ftpes:// = TLS explícito via AUTH TLS)
+//   - pattern: regex do nome do arquivo; o 1º grupo é a versão (obrigatório)
+//   - username_env, password_env: variáveis de ambiente com as credenciais (padrão: anônimo)
+//
+// O mais novo é decidido pela versão (version_scheme, padrão semver) e, em empate, pela data de modificação.
+func checkFTP(ctx context.Context, src SourceApp) (checkResult, error) {
+	dirURL, err := url.Parse(src.Config["url"])
+	if err != nil || !isFTPURL(src.Config["url"]) {
+		return checkResult{}, fmt.Errorf("ftp: url inválida: %s", src.Config["url"])
+	}
+	re, err := regexp.Compile(src.Config["pattern"])
+	if err != nil || src.Config["pattern"] == "" {
+		return checkResult{}, fmt.Errorf("ftp: 'pattern' inválido ou ausente")
+	}
+
+	conn, err := ftpConnect(ctx, src, dirURL)
+	if err != nil {
+		return checkResult{}, err
+	}
+	defer conn.Quit()
+	stop := context.AfterFunc(ctx, func() { conn.Quit() })
+	defer stop()
+
+	debugf("     LIST %s", dirURL.Path)
+	entries, err := conn.List(dirURL.Path)
+	if err != nil {
+		return checkResult{}, fmt.Errorf("ftp: %w", err)
+	}
+
+	var best *ftp.Entry
+	bestVersion, bestMatch := "", []string(nil)
+	for _, e := range entries {
+		if e.Type != ftp.EntryTypeFile {
+			continue
+		}
+		m := re.FindStringSubmatch(e.Name)
+		if len(m) < 2 {
+			continue
+		}
+		c := compareVersions(versionScheme(src.Config), m[1], bestVersion)
+		if best == nil || c > 0 || (c == 0 && e.Time.After(best.Time)) {
+			best, bestVersion, bestMatch = e, m[1], m
+		}
+	}
+	if best == nil {
+		return checkResult{}, fmt.Errorf("ftp: nenhum arquivo casa com '%s' em %s", re, dirURL.Path)
+	}
+
+	fileURL := *dirURL
+	fileURL.User = nil // Credenciais nunca vão para o catálogo
+	fileURL.Path = path.Join(dirURL.Path, best.Name)
+
+	return checkResult{
+		Version:    bestVersion,
+		URL:        fileURL.String(),
+		Matches:    bestMatch,
+		Size:       int64(best.Size),
+		ReleasedAt: best.Time.UTC(),
+	}, nil
+}
+
+func isFTPURL(raw string) bool {
+	return strings.HasPrefix(raw, "ftp://") || strings.HasPrefix(raw, "ftps://") || strings.HasPrefix(raw, "ftpes://")
+}
+
+// ftpConnect abre e autentica uma conexão conforme o esquema da URL.
+func ftpConnect(ctx context.Context, src SourceApp, u *url.URL) (*ftp.ServerConn, error) {
+	host := u.Host
+	opts := []ftp.DialOption{ftp.DialWithTimeout(time.Duration(cfg.HTTPTimeout)), ftp.DialWithContext(ctx)}
+
+	tlsConf, err := sourceTLSConfig(src.Config)
+	if err != nil {
+		return nil, fmt.Errorf("ftp: %w", err)
+	}
+	if tlsConf == nil {
+		tlsConf = &tls.Config{}
+	}
+	tlsConf.ServerName = u.Hostname()
+
+	switch u.Scheme {
+	case "ftps":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "990")
+		}
+		opts = append(opts, ftp.DialWithTLS(tlsConf))
+	case "ftpes":
+		opts = append(opts, ftp.DialWithExplicitTLS(tlsConf))
+	}
+	if u.Port() == "" && u.Scheme != "ftps" {
+		host = net.JoinHostPort(u.Hostname(), "21")
+	}
+
+	conn, err := ftp.Dial(host, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("ftp: %w", err)
+	}
+
+	user, pass := "anonymous", "anonymous"
+	if env := src.Config["username_env"]; env != "" {
+		user = os.Getenv(env)
+	}
+	if env := src.Config["password_env"]; env != "" {
+		pass = os.Getenv(env)
+	}
+	if err := conn.Login(user, pass); err != nil {
+		conn.Quit()
+		return nil, fmt.Errorf("ftp: login: %w", err)
+	}
+	return conn, nil
+}
+
+// ftpReadCloser encerra a transferência e a conexão juntas.
+type ftpReadCloser struct {
+	*ftp.Response
+	conn *ftp.ServerConn
+	stop func() bool // Desfaz o vínculo com o cancelamento do contexto
+}
+
+func (r ftpReadCloser) Close() error {
+	r.stop()
+	err := r.Response.Close()
+	r.conn.Quit()
+	return err
+}
+
+// openFTP abre o download de um arquivo FTP para downloadAndHash.
+func openFTP(ctx context.Context, src SourceApp, rawURL string) (io.ReadCloser, time.Time, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	conn, err := ftpConnect(ctx, src, u)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	// MDTM é opcional no protocolo: sem ele, a data vem da listagem em checkFTP
+	modified, _ := conn.GetTime(u.Path)
+
+	debugf("     RETR %s", u.Path)
+	resp, err := conn.Retr(u.Path)
+	if err != nil {
+		conn.Quit()
+		return nil, time.Time{}, fmt.Errorf("ftp: %w", err)
+	}
+	// Cancelamento (ex: --timeout) desbloqueia a leitura da conexão de dados
+	stop := context.AfterFunc(ctx, func() { resp.SetDeadline(time.Now()) })
+	return ftpReadCloser{resp, conn, stop}, modified.UTC(), nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ==========================================
+// ESTRATÉGIA: PROXY DE MÓDULOS GO
+// ==========================================
+
+// goProxyDefault é o proxy usado quando a fonte não define 'url'.
+const goProxyDefault = "https://proxy.golang.org"
+
+// checkGoProxy resolve a versão de uma ferramenta Go pelo protocolo GOPROXY. Config aceita:
+//   - module: caminho do módulo, ex: github.com/golangci/golangci-lint/v2 (obrigatório)
+//   - url: proxy (padrão: proxy.golang.org)
+//   - pattern: regex que a versão deve casar; com ela, a versão vem de @v/list (maior que casar,
+//     sem prereleases, a menos que include_prereleases: "true") em vez de @latest
+//
+// O artefato publicado é o zip do módulo ({module}/@v/{versão}.zip), cujo sha256 é calculado
+// no download como o de qualquer outro app.
+func checkGoProxy(ctx context.Context, src SourceApp) (checkResult, error) {
+	conf := src.Config
+	if conf["module"] == "" {
+		return checkResult{}, fmt.Errorf("goproxy: 'module' é obrigatório")
+	}
+	proxy := strings.TrimSuffix(conf["url"], "/")
+	if proxy == "" {
+		proxy = goProxyDefault
+	}
+	base := proxy + "/" + escapeModulePath(conf["module"])
+
+	var version string
+	var released time.Time
+	if conf["pattern"] == "" {
+		var info struct {
+			Version string    `json:"Version"`
+			Time    time.Time `json:"Time"`
+		}
+		if err := sourceGetJSON(ctx, conf, base+"/@latest", &info); err != nil {
+			return checkResult{}, fmt.Errorf("goproxy: %w", err)
+		}
+		version, released = info.Version, info.Time
+	} else {
+		pattern, err := regexp.Compile(conf["pattern"])
+		if err != nil {
+			return checkResult{}, fmt.Errorf("goproxy: pattern inválido: %w", err)
+		}
+		body, err := sourceGet(ctx, conf, base+"/@v/list", "text/plain")
+		if err != nil {
+			return checkResult{}, fmt.Errorf("goproxy: %w", err)
+		}
+		for _, v := range strings.Fields(string(body)) {
+			if !eligibleVersion(conf, pattern, v) {
+				continue
+			}
+			if version == "" || compareSemver(strings.TrimPrefix(v, "v"), strings.TrimPrefix(version, "v")) > 0 {
+				version = v
+			}
+		}
+	}
+	if version == "" {
+		return checkResult{}, fmt.Errorf("goproxy: nenhuma versão elegível para %s", conf["module"])
+	}
+
+	return checkResult{
+		Version:    version,
+		URL:        fmt.Sprintf("%s/@v/%s.zip", base, escapeModulePath(version)),
+		ReleasedAt: released.UTC(),
+	}, nil
+}
+
+// escapeModulePath aplica o escape do GOPROXY: maiúsculas viram "!" + minúscula
+// (github.com/Azure/x -> github.com/!azure/x), porque nem todo sistema de arquivos
+// diferencia maiúsculas.
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ==========================================
+// ESTRATÉGIA: PLUGINS JETBRAINS
+// ==========================================
+
+// jetbrainsPluginsDefault é o repositório usado quando a fonte não define 'url'.
+const jetbrainsPluginsDefault = "https://plugins.jetbrains.com"
+
+// checkJetBrains resolve a versão mais nova de um plugin compatível com um build da IDE, pelo
+// endpoint /plugins/list que a própria IDE consulta. Config aceita:
+//   - plugin: ID XML do plugin, ex: "org.rust.lang" (obrigatório)
+//   - build: build da IDE, com prefixo do produto, ex: "IU-241.15989.150" (obrigatório; é o
+//     que decide a compatibilidade)
+//   - channel: canal de releases, ex: "eap" (padrão: estável)
+//   - url: repositório próprio compatível (padrão: plugins.jetbrains.com)
+func checkJetBrains(ctx context.Context, src SourceApp) (checkResult, error) {
+	conf := src.Config
+	if conf["plugin"] == "" || conf["build"] == "" {
+		return checkResult{}, fmt.Errorf("jetbrains: 'plugin' e 'build' são obrigatórios")
+	}
+	base := strings.TrimSuffix(conf["url"], "/")
+	if base == "" {
+		base = jetbrainsPluginsDefault
+	}
+
+	query := url.Values{"pluginId": {conf["plugin"]}, "build": {conf["build"]}}
+	if ch := conf["channel"]; ch != "" {
+		query.Set("channel", ch)
+	}
+	body, err := sourceGet(ctx, conf, base+"/plugins/list?"+query.Encode(), "application/xml")
+	if err != nil {
+		return checkResult{}, fmt.Errorf("jetbrains: %w", err)
+	}
+
+	// Resposta: <plugin-repository><category><idea-plugin><id/><version/>...; só vêm versões
+	// compatíveis com o build, a mais nova primeiro
+	var repo struct {
+		Categories []struct {
+			Plugins []struct {
+				ID      string `xml:"id"`
+				Version string `xml:"version"`
+			} `xml:"idea-plugin"`
+		} `xml:"category"`
+	}
+	if err := xml.Unmarshal(body, &repo); err != nil {
+		return checkResult{}, fmt.Errorf("jetbrains: resposta inválida: %w", err)
+	}
+	for _, cat := range repo.Categories {
+		for _, p := range cat.Plugins {
+			if p.ID != conf["plugin"] || p.Version == "" {
+				continue
+			}
+			download := url.Values{"pluginId": {p.ID}, "version": {p.Version}}
+			if ch := conf["channel"]; ch != "" {
+				download.Set("channel", ch)
+			}
+			return checkResult{Version: p.Version, URL: base + "/plugin/download?" + download.Encode()}, nil
+		}
+	}
+	return checkResult{}, fmt.Errorf("jetbrains: nenhuma versão de %s compatível com %s", conf["plugin"], conf["build"])
+}
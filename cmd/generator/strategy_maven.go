@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ==========================================
+// ESTRATÉGIA: REPOSITÓRIO MAVEN
+// ==========================================
+
+// mavenCentral é o repositório usado quando a fonte não define 'url'.
+const mavenCentral = "https://repo1.maven.org/maven2"
+
+// checkMaven resolve a versão pelo maven-metadata.xml do artefato, para ferramentas JVM
+// distribuídas por repositórios Maven. Config aceita:
+//   - group_id, artifact_id: coordenadas do artefato (obrigatórios)
+//   - url: repositório (padrão: Maven Central)
+//   - extension: extensão do arquivo (padrão: jar); classifier: ex. "linux-x86_64" ou "all"
+//   - pattern: regex que a versão deve casar, para fixar uma linha (ex: "^2\."); sem ela vale o
+//     <release> do metadata, ou a maior das <versions>
+//
+// Versões -SNAPSHOT nunca são escolhidas.
+func checkMaven(ctx context.Context, src SourceApp) (checkResult, error) {
+	conf := src.Config
+	groupID, artifactID := conf["group_id"], conf["artifact_id"]
+	if groupID == "" || artifactID == "" {
+		return checkResult{}, fmt.Errorf("maven: 'group_id' e 'artifact_id' são obrigatórios")
+	}
+	var pattern *regexp.Regexp
+	if conf["pattern"] != "" {
+		var err error
+		if pattern, err = regexp.Compile(conf["pattern"]); err != nil {
+			return checkResult{}, fmt.Errorf("maven: pattern inválido: %w", err)
+		}
+	}
+
+	repo := strings.TrimSuffix(conf["url"], "/")
+	if repo == "" {
+		repo = mavenCentral
+	}
+	base := fmt.Sprintf("%s/%s/%s", repo, strings.ReplaceAll(groupID, ".", "/"), artifactID)
+
+	body, err := sourceGet(ctx, conf, base+"/maven-metadata.xml", "application/xml")
+	if err != nil {
+		return checkResult{}, fmt.Errorf("maven: %w", err)
+	}
+	var meta struct {
+		Versioning struct {
+			Latest   string   `xml:"latest"`
+			Release  string   `xml:"release"`
+			Versions []string `xml:"versions>version"`
+		} `xml:"versioning"`
+	}
+	if err := xml.Unmarshal(body, &meta); err != nil {
+		return checkResult{}, fmt.Errorf("maven: metadata inválido: %w", err)
+	}
+
+	version := meta.Versioning.Release
+	if pattern != nil || version == "" {
+		version = ""
+		scheme := versionScheme(conf)
+		for _, v := range meta.Versioning.Versions {
+			if strings.HasSuffix(v, "-SNAPSHOT") || (pattern != nil && !pattern.MatchString(v)) {
+				continue
+			}
+			if version == "" || compareVersions(scheme, v, version) > 0 {
+				version = v
+			}
+		}
+	}
+	if version == "" {
+		return checkResult{}, fmt.Errorf("maven: nenhuma versão elegível em %s:%s", groupID, artifactID)
+	}
+
+	extension := conf["extension"]
+	if extension == "" {
+		extension = "jar"
+	}
+	file := artifactID + "-" + version
+	if classifier := conf["classifier"]; classifier != "" {
+		file += "-" + classifier
+	}
+	return checkResult{Version: version, URL: fmt.Sprintf("%s/%s/%s.%s", base, version, file, extension)}, nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// ESTRATÉGIAS: NEXUS E ARTIFACTORY
+// ==========================================
+
+// repoArtifact é um arquivo candidato listado por um gerenciador de repositórios.
+type repoArtifact struct {
+	path         string // Caminho dentro do repositório (onde 'pattern' é aplicado)
+	downloadURL  string
+	size         int64
+	sha256       string
+	lastModified time.Time
+}
+
+// pickRepoArtifact escolhe, entre os arquivos cujo caminho casa com pattern, o de maior
+// versão (1º grupo; version_scheme, padrão semver) e, em empate, o mais recente.
+func pickRepoArtifact(conf map[string]string, pattern *regexp.Regexp, artifacts []repoArtifact) (checkResult, error) {
+	var best *repoArtifact
+	var bestMatch []string
+	scheme := versionScheme(conf)
+	for i := range artifacts {
+		a := &artifacts[i]
+		m := pattern.FindStringSubmatch(a.path)
+		if len(m) < 2 {
+			continue
+		}
+		if best != nil {
+			cmp := compareVersions(scheme, m[1], bestMatch[1])
+			if cmp < 0 || (cmp == 0 && !a.lastModified.After(best.lastModified)) {
+				continue
+			}
+		}
+		best, bestMatch = a, m
+	}
+	if best == nil {
+		return checkResult{}, fmt.Errorf("nenhum arquivo casa com o pattern entre %d listados", len(artifacts))
+	}
+	verbosef("     Arquivo escolhido: %s", best.path)
+
+	return checkResult{
+		Version:    bestMatch[1],
+		URL:        best.downloadURL,
+		Size:       best.size,
+		Checksum:   best.sha256,
+		ReleasedAt: best.lastModified.UTC(),
+		Matches:    bestMatch,
+	}, nil
+}
+
+// repoPattern compila o 'pattern' obrigatório das estratégias de repositório.
+func repoPattern(strategy string, conf map[string]string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(conf["pattern"])
+	if err != nil || conf["pattern"] == "" {
+		return nil, fmt.Errorf("%s: 'pattern' inválido ou ausente", strategy)
+	}
+	return re, nil
+}
+
+// nexusMaxPages limita a paginação da busca (50 itens por página no Nexus).
+const nexusMaxPages = 40
+
+// checkNexus busca o artefato mais novo num repositório do Sonatype Nexus 3, pela API
+// /service/rest/v1/search/assets. Config aceita:
+//   - url: endereço do Nexus, ex: https://nexus.empresa.com (obrigatório)
+//   - repository: nome do repositório (obrigatório)
+//   - group, name: filtros da busca (opcionais; em repos raw, group é o diretório)
+//   - pattern: regex aplicada ao caminho do asset; o 1º grupo é a versão (obrigatório)
+//
+// O sha256 informado pelo Nexus dispensa o download quando o tamanho também vem na resposta.
+func checkNexus(ctx context.Context, src SourceApp) (checkResult, error) {
+	conf := src.Config
+	if conf["url"] == "" || conf["repository"] == "" {
+		return checkResult{}, fmt.Errorf("nexus: 'url' e 'repository' são obrigatórios")
+	}
+	pattern, err := repoPattern("nexus", conf)
+	if err != nil {
+		return checkResult{}, err
+	}
+
+	query := url.Values{"repository": {conf["repository"]}}
+	for _, key := range []string{"group", "name"} {
+		if v := conf[key]; v != "" {
+			query.Set(key, v)
+		}
+	}
+
+	var artifacts []repoArtifact
+	for page := 0; page < nexusMaxPages; page++ {
+		var resp struct {
+			Items []struct {
+				Path         string            `json:"path"`
+				DownloadURL  string            `json:"downloadUrl"`
+				FileSize     int64             `json:"fileSize"`
+				LastModified time.Time         `json:"lastModified"`
+				Checksum     map[string]string `json:"checksum"`
+			} `json:"items"`
+			ContinuationToken string `json:"continuationToken"`
+		}
+		endpoint := strings.TrimSuffix(conf["url"], "/") + "/service/rest/v1/search/assets?" + query.Encode()
+		if err := sourceGetJSON(ctx, conf, endpoint, &resp); err != nil {
+			return checkResult{}, fmt.Errorf("nexus: %w", err)
+		}
+		for _, item := range resp.Items {
+			artifacts = append(artifacts, repoArtifact{
+				path:         item.Path,
+				downloadURL:  item.DownloadURL,
+				size:         item.FileSize,
+				sha256:       strings.ToLower(item.Checksum["sha256"]),
+				lastModified: item.LastModified,
+			})
+		}
+		if resp.ContinuationToken == "" {
+			break
+		}
+		query.Set("continuationToken", resp.ContinuationToken)
+	}
+
+	return pickRepoArtifact(conf, pattern, artifacts)
+}
+
+// checkArtifactory lista um caminho de um repositório do JFrog Artifactory pela API de storage
+// (/api/storage/{repo}/{path}?list&deep=1) e escolhe o arquivo mais novo. Config aceita:
+//   - url: endereço do Artifactory, ex: https://empresa.jfrog.io/artifactory (obrigatório)
+//   - repository: nome do repositório (obrigatório)
+//   - path: diretório dentro do repositório (padrão: raiz)
+//   - pattern: regex aplicada ao caminho do arquivo, relativo a path; o 1º grupo é a versão (obrigatório)
+//
+// A listagem já traz tamanho e sha256 (sha2), então o download normalmente é dispensado.
+func checkArtifactory(ctx context.Context, src SourceApp) (checkResult, error) {
+	conf := src.Config
+	if conf["url"] == "" || conf["repository"] == "" {
+		return checkResult{}, fmt.Errorf("artifactory: 'url' e 'repository' são obrigatórios")
+	}
+	pattern, err := repoPattern("artifactory", conf)
+	if err != nil {
+		return checkResult{}, err
+	}
+
+	base := strings.TrimSuffix(conf["url"], "/")
+	dir := strings.Trim(conf["repository"]+"/"+strings.Trim(conf["path"], "/"), "/")
+
+	var resp struct {
+		Files []struct {
+			URI          string    `json:"uri"`
+			Size         int64     `json:"size"`
+			LastModified time.Time `json:"lastModified"`
+			Folder       bool      `json:"folder"`
+			SHA2         string    `json:"sha2"`
+		} `json:"files"`
+	}
+	endpoint := fmt.Sprintf("%s/api/storage/%s?list&deep=1&listFolders=0", base, dir)
+	if err := sourceGetJSON(ctx, conf, endpoint, &resp); err != nil {
+		return checkResult{}, fmt.Errorf("artifactory: %w", err)
+	}
+
+	var artifacts []repoArtifact
+	for _, f := range resp.Files {
+		if f.Folder {
+			continue
+		}
+		artifacts = append(artifacts, repoArtifact{
+			path:         strings.TrimPrefix(f.URI, "/"),
+			downloadURL:  base + "/" + dir + f.URI,
+			size:         f.Size,
+			sha256:       strings.ToLower(f.SHA2),
+			lastModified: f.LastModified,
+		})
+	}
+
+	return pickRepoArtifact(conf, pattern, artifacts)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// ESTRATÉGIA: RUBYGEMS
+// ==========================================
+
+// rubyGemsDefault é a API usada quando a fonte não define 'url'.
+const rubyGemsDefault = "https://rubygems.org"
+
+// checkRubyGems resolve a versão de uma ferramenta Ruby pela API do rubygems.org. Config aceita:
+//   - gem: nome da gem (obrigatório)
+//   - url: servidor compatível (padrão: rubygems.org)
+//   - platform: plataforma da gem (padrão: ruby, a gem pura)
+//   - pattern: regex que a versão deve casar (ex: fixar uma linha)
+//   - include_prereleases: "true" considera versões como 2.0.0.rc1
+//
+// A API não informa o tamanho, então a gem é baixada para o cálculo do hash.
+func checkRubyGems(ctx context.Context, src SourceApp) (checkResult, error) {
+	conf := src.Config
+	name := conf["gem"]
+	if name == "" {
+		return checkResult{}, fmt.Errorf("rubygems: 'gem' é obrigatório")
+	}
+	pattern, err := optionalPattern("rubygems", conf)
+	if err != nil {
+		return checkResult{}, err
+	}
+	base := strings.TrimSuffix(conf["url"], "/")
+	if base == "" {
+		base = rubyGemsDefault
+	}
+	platform := conf["platform"]
+	if platform == "" {
+		platform = "ruby"
+	}
+
+	var versions []struct {
+		Number     string    `json:"number"`
+		Platform   string    `json:"platform"`
+		Prerelease bool      `json:"prerelease"`
+		CreatedAt  time.Time `json:"created_at"`
+	}
+	if err := sourceGetJSON(ctx, conf, base+"/api/v1/versions/"+url.PathEscape(name)+".json", &versions); err != nil {
+		return checkResult{}, fmt.Errorf("rubygems: %w", err)
+	}
+
+	best := -1
+	for i, v := range versions {
+		if v.Platform != platform || (v.Prerelease && conf["include_prereleases"] != "true") {
+			continue
+		}
+		if pattern != nil && !pattern.MatchString(v.Number) {
+			continue
+		}
+		if best < 0 || compareVersions(versionScheme(conf), v.Number, versions[best].Number) > 0 {
+			best = i
+		}
+	}
+	if best < 0 {
+		return checkResult{}, fmt.Errorf("rubygems: nenhuma versão elegível de %s (%s)", name, platform)
+	}
+	v := versions[best]
+
+	file := name + "-" + v.Number
+	if platform != "ruby" {
+		file += "-" + platform
+	}
+	return checkResult{
+		Version:    v.Number,
+		URL:        fmt.Sprintf("%s/gems/%s.gem", base, file),
+		ReleasedAt: v.CreatedAt.UTC(),
+	}, nil
+}
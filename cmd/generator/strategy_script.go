@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// ==========================================
+// ESTRATÉGIA: SCRIPT EMBUTIDO (Starlark)
+// ==========================================
+
+// Limites do sandbox: passos de execução e tamanho de cada resposta HTTP lida pelo script.
+const (
+	scriptMaxSteps    = 10_000_000
+	scriptMaxBodySize = 10 << 20
+)
+
+// checkScript executa um script Starlark com lógica própria de resolução de versão
+// (várias chamadas de API, parsing de HTML). Config aceita:
+//   - script: código inline, ou
+//   - script_file: caminho do arquivo .star
+//
+// O script deve definir check(config) devolvendo um dict com version, url e, opcionalmente,
+// size, checksum (SHA256) e released_at (RFC 3339). O sandbox expõe apenas os módulos
+// http (get/head), re (search/findall) e json; não há acesso a arquivos nem load().
+// Requisições aos auth_hosts da fonte levam as credenciais configuradas (ver applySourceAuth).
+func checkScript(ctx context.Context, src SourceApp) (checkResult, error) {
+	code, filename := src.Config["script"], src.ID+".star"
+	if path := src.Config["script_file"]; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return checkResult{}, err
+		}
+		code, filename = string(data), path
+	}
+	if code == "" {
+		return checkResult{}, fmt.Errorf("script: 'script' ou 'script_file' não configurado")
+	}
+
+	thread := &starlark.Thread{
+		Name:  src.ID,
+		Print: func(_ *starlark.Thread, msg string) { debugf("     [script] %s", msg) },
+		Load: func(_ *starlark.Thread, module string) (starlark.StringDict, error) {
+			return nil, fmt.Errorf("load() não é permitido (%s)", module)
+		},
+	}
+	thread.SetMaxExecutionSteps(scriptMaxSteps)
+	thread.SetLocal("context", ctx)
+	thread.SetLocal("config", map[string]string(src.Config)) // Lido como map[string]string em scriptHTTP
+	stop := context.AfterFunc(ctx, func() { thread.Cancel(ctx.Err().Error()) })
+	defer stop()
+
+	predeclared := starlark.StringDict{
+		"http": scriptHTTPModule,
+		"re":   scriptReModule,
+		"json": json.Module,
+	}
+
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, filename, code, predeclared)
+	if err != nil {
+		return checkResult{}, fmt.Errorf("script: %w", err)
+	}
+
+	check, ok := globals["check"].(starlark.Callable)
+	if !ok {
+		return checkResult{}, fmt.Errorf("script: função check(config) não definida")
+	}
+
+	conf := starlark.NewDict(len(src.Config))
+	for k, v := range src.Config {
+		if k == "script" {
+			continue
+		}
+		conf.SetKey(starlark.String(k), starlark.String(v))
+	}
+
+	ret, err := starlark.Call(thread, check, starlark.Tuple{conf}, nil)
+	if err != nil {
+		return checkResult{}, fmt.Errorf("script: %w", err)
+	}
+
+	out, ok := ret.(*starlark.Dict)
+	if !ok {
+		return checkResult{}, fmt.Errorf("script: check() deve devolver um dict, devolveu %s", ret.Type())
+	}
+
+	var res checkResult
+	res.Version = scriptDictString(out, "version")
+	res.URL = scriptDictString(out, "url")
+	res.Checksum = scriptDictString(out, "checksum")
+	if v, found, _ := out.Get(starlark.String("size")); found {
+		if n, ok := v.(starlark.Int); ok {
+			res.Size, _ = n.Int64()
+		}
+	}
+	if v := scriptDictString(out, "released_at"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			res.ReleasedAt = t
+		}
+	}
+
+	if res.Version == "" || res.URL == "" {
+		return checkResult{}, fmt.Errorf("script: 'version' e 'url' são obrigatórios no retorno")
+	}
+	return res, nil
+}
+
+func scriptDictString(d *starlark.Dict, key string) string {
+	v, found, _ := d.Get(starlark.String(key))
+	if !found {
+		return ""
+	}
+	if s, ok := starlark.AsString(v); ok {
+		return s
+	}
+	return ""
+}
+
+// ------------------------------------------
+// Primitivas expostas ao script
+// ------------------------------------------
+
+var scriptHTTPModule = &starlarkstruct.Module{
+	Name: "http",
+	Members: starlark.StringDict{
+		"get":  starlark.NewBuiltin("http.get", scriptHTTP("GET")),
+		"head": starlark.NewBuiltin("http.head", scriptHTTP("HEAD")),
+	},
+}
+
+// scriptHTTP devolve http.get/http.head: (url, headers={}) -> struct(status, url, headers, body).
+// url é a URL final, após redirects.
+func scriptHTTP(method string) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var url string
+		var headers *starlark.Dict
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "url", &url, "headers?", &headers); err != nil {
+			return nil, err
+		}
+
+		ctx, _ := thread.Local("context").(context.Context)
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		// Credenciais da fonte (Basic/OAuth) para os auth_hosts; headers do script têm a palavra final
+		conf, _ := thread.Local("config").(map[string]string)
+		if err := applySourceAuth(req, conf); err != nil {
+			return nil, err
+		}
+		// Com respect_robots, cada URL pedida pelo script passa pelo robots.txt (o script
+		// monta as próprias URLs, então a checagem da url da fonte em checkApp não basta)
+		if cfg.RespectRobots {
+			if err := robotsAllow(ctx, conf, url); err != nil {
+				return nil, err
+			}
+		}
+		if headers != nil {
+			for _, item := range headers.Items() {
+				k, _ := starlark.AsString(item[0])
+				v, _ := starlark.AsString(item[1])
+				req.Header.Set(k, v)
+			}
+		}
+
+		debugf("     [script] %s %s", method, url)
+		client := &http.Client{Timeout: time.Duration(cfg.HTTPTimeout)}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, scriptMaxBodySize))
+		if err != nil {
+			return nil, err
+		}
+
+		respHeaders := starlark.NewDict(len(resp.Header))
+		for k := range resp.Header {
+			respHeaders.SetKey(starlark.String(strings.ToLower(k)), starlark.String(resp.Header.Get(k)))
+		}
+
+		return starlarkstruct.FromStringDict(starlark.String("response"), starlark.StringDict{
+			"status":  starlark.MakeInt(resp.StatusCode),
+			"url":     starlark.String(resp.Request.URL.String()),
+			"headers": respHeaders,
+			"body":    starlark.String(body),
+		}), nil
+	}
+}
+
+var scriptReModule = &starlarkstruct.Module{
+	Name: "re",
+	Members: starlark.StringDict{
+		"search":  starlark.NewBuiltin("re.search", scriptReSearch),
+		"findall": starlark.NewBuiltin("re.findall", scriptReFindall),
+	},
+}
+
+// re.search(pattern, text) -> [match, grupo1, ...] ou None
+func scriptReSearch(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern, text string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "pattern", &pattern, "text", &text); err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	m := re.FindStringSubmatch(text)
+	if m == nil {
+		return starlark.None, nil
+	}
+	out := make([]starlark.Value, len(m))
+	for i, g := range m {
+		out[i] = starlark.String(g)
+	}
+	return starlark.NewList(out), nil
+}
+
+// re.findall(pattern, text) -> lista com o 1º grupo de cada ocorrência (ou o match inteiro, sem grupos)
+func scriptReFindall(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern, text string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "pattern", &pattern, "text", &text); err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []starlark.Value
+	for _, m := range re.FindAllStringSubmatch(text, -1) {
+		if len(m) > 1 {
+			out = append(out, starlark.String(m[1]))
+		} else {
+			out = append(out, starlark.String(m[0]))
+		}
+	}
+	return starlark.NewList(out), nil
+}
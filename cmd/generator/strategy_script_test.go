@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// As requisições do script ao host da fonte levam as credenciais configuradas.
+func TestCheckScriptSourceAuth(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"version": "1.2.3"}`)
+	}))
+	defer server.Close()
+	t.Setenv("SCRIPT_TEST_TOKEN", "segredo")
+
+	src := SourceApp{ID: "foo", Strategy: "script", Config: ConfigMap{
+		"url":       server.URL,
+		"token_env": "SCRIPT_TEST_TOKEN",
+		"script": `
+def check(config):
+    resp = http.get(config["url"] + "/latest.json")
+    return {"version": json.decode(resp.body)["version"], "url": config["url"] + "/foo.deb"}
+`,
+	}}
+	res, err := checkScript(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Version != "1.2.3" {
+		t.Errorf("versão = %q", res.Version)
+	}
+	if auth != "Bearer segredo" {
+		t.Errorf("Authorization = %q, esperado o token da fonte", auth)
+	}
+}
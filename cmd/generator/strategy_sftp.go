@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// ==========================================
+// ESTRATÉGIA: SFTP (DROP PRIVADO)
+// ==========================================
+
+// checkSFTP busca o artefato mais novo num diretório SFTP de staging, onde builds internos
+// são depositados antes de entrar no catálogo. Config aceita:
+//   - url: diretório, ex: sftp://deploy@staging.local/drop/app (obrigatório)
+//   - pattern: regex do nome do arquivo; o 1º grupo é a versão (obrigatório)
+//   - key_file ou key_env: chave privada (arquivo ou variável de ambiente com o conteúdo)
+//   - passphrase_env: variável com a senha da chave, se houver
+//   - known_hosts: arquivo de hosts conhecidos (padrão: ~/.ssh/known_hosts)
+//   - insecure_ignore_host_key: "true" desativa a verificação do host (opt-in explícito)
+//   - public_url: URL publicada no catálogo, com {file} e {version}; o SFTP é usado
+//     apenas para calcular o hash, já que clientes não têm acesso ao staging
+func checkSFTP(ctx context.Context, src SourceApp) (checkResult, error) {
+	dirURL, err := url.Parse(src.Config["url"])
+	if err != nil || dirURL.Scheme != "sftp" {
+		return checkResult{}, fmt.Errorf("sftp: url inválida: %s", src.Config["url"])
+	}
+	re, err := regexp.Compile(src.Config["pattern"])
+	if err != nil || src.Config["pattern"] == "" {
+		return checkResult{}, fmt.Errorf("sftp: 'pattern' inválido ou ausente")
+	}
+
+	client, err := sftpConnect(ctx, src, dirURL)
+	if err != nil {
+		return checkResult{}, err
+	}
+	defer client.Close()
+
+	debugf("     SFTP LIST %s", dirURL.Path)
+	entries, err := client.ReadDir(dirURL.Path)
+	if err != nil {
+		return checkResult{}, fmt.Errorf("sftp: %w", err)
+	}
+
+	var best os.FileInfo
+	bestVersion, bestMatch := "", []string(nil)
+	for _, e := range entries {
+		if !e.Mode().IsRegular() {
+			continue
+		}
+		m := re.FindStringSubmatch(e.Name())
+		if len(m) < 2 {
+			continue
+		}
+		c := compareVersions(versionScheme(src.Config), m[1], bestVersion)
+		if best == nil || c > 0 || (c == 0 && e.ModTime().After(best.ModTime())) {
+			best, bestVersion, bestMatch = e, m[1], m
+		}
+	}
+	if best == nil {
+		return checkResult{}, fmt.Errorf("sftp: nenhum arquivo casa com '%s' em %s", re, dirURL.Path)
+	}
+
+	fileURL := *dirURL
+	fileURL.Path = path.Join(dirURL.Path, best.Name())
+
+	res := checkResult{
+		Version:    bestVersion,
+		URL:        fileURL.String(),
+		Matches:    bestMatch,
+		Size:       best.Size(),
+		ReleasedAt: best.ModTime().UTC(),
+	}
+	if tmpl := src.Config["public_url"]; tmpl != "" {
+		res.FetchURL = res.URL
+		res.URL = strings.NewReplacer("{file}", best.Name(), "{version}", bestVersion).Replace(tmpl)
+	}
+	return res, nil
+}
+
+// sftpConnect abre a sessão SSH autenticada por chave e o cliente SFTP sobre ela.
+// Cancelar ctx fecha a conexão, abortando qualquer operação em andamento.
+func sftpConnect(ctx context.Context, src SourceApp, u *url.URL) (*sftpClient, error) {
+	conf := src.Config
+
+	var key []byte
+	var err error
+	switch {
+	case conf["key_file"] != "":
+		if key, err = os.ReadFile(conf["key_file"]); err != nil {
+			return nil, fmt.Errorf("sftp: %w", err)
+		}
+	case conf["key_env"] != "":
+		key = []byte(os.Getenv(conf["key_env"]))
+	default:
+		return nil, fmt.Errorf("sftp: 'key_file' ou 'key_env' é obrigatório")
+	}
+
+	var signer ssh.Signer
+	if env := conf["passphrase_env"]; env != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(os.Getenv(env)))
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sftp: chave inválida: %w", err)
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if conf["insecure_ignore_host_key"] != "true" {
+		knownHosts := conf["known_hosts"]
+		if knownHosts == "" {
+			home, _ := os.UserHomeDir()
+			knownHosts = filepath.Join(home, ".ssh", "known_hosts")
+		}
+		if hostKeyCallback, err = knownhosts.New(knownHosts); err != nil {
+			return nil, fmt.Errorf("sftp: known_hosts: %w", err)
+		}
+	}
+
+	user := u.User.Username()
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "22")
+	}
+
+	dialer := net.Dialer{Timeout: time.Duration(cfg.HTTPTimeout)}
+	netConn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, fmt.Errorf("sftp: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { netConn.Close() })
+
+	conn, chans, reqs, err := ssh.NewClientConn(netConn, host, &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         time.Duration(cfg.HTTPTimeout),
+	})
+	if err != nil {
+		stop()
+		netConn.Close()
+		return nil, fmt.Errorf("sftp: %w", err)
+	}
+	sshConn := ssh.NewClient(conn, chans, reqs)
+
+	client, err := sftp.NewClient(sshConn)
+	if err != nil {
+		stop()
+		sshConn.Close()
+		return nil, fmt.Errorf("sftp: %w", err)
+	}
+	return &sftpClient{client, sshConn, stop}, nil
+}
+
+// sftpClient mantém a conexão SSH junto do cliente SFTP para fechá-los juntos.
+type sftpClient struct {
+	*sftp.Client
+	ssh  *ssh.Client
+	stop func() bool // Desfaz o vínculo com o cancelamento do contexto
+}
+
+func (c *sftpClient) Close() error {
+	c.stop()
+	err := c.Client.Close()
+	c.ssh.Close()
+	return err
+}
+
+// sftpFile fecha o arquivo remoto e a conexão ao fim do download.
+type sftpFile struct {
+	*sftp.File
+	client *sftpClient
+}
+
+func (f sftpFile) Close() error {
+	err := f.File.Close()
+	f.client.Close()
+	return err
+}
+
+// openSFTP abre o download de um arquivo SFTP para downloadAndHash.
+func openSFTP(ctx context.Context, src SourceApp, rawURL string) (io.ReadCloser, time.Time, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	client, err := sftpConnect(ctx, src, u)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	debugf("     SFTP GET %s", u.Path)
+	file, err := client.Open(u.Path)
+	if err != nil {
+		client.Close()
+		return nil, time.Time{}, fmt.Errorf("sftp: %w", err)
+	}
+
+	var modified time.Time
+	if info, err := file.Stat(); err == nil {
+		modified = info.ModTime().UTC()
+	}
+	return sftpFile{file, client}, modified, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ==========================================
+// ESTRATÉGIA: SQUIRREL.WINDOWS (RELEASES)
+// ==========================================
+
+// squirrelFullRe separa nome e versão de um pacote completo ("MyApp-1.2.3-full.nupkg"); os
+// pacotes -delta.nupkg só servem para quem já tem a versão anterior e ficam de fora.
+var squirrelFullRe = regexp.MustCompile(`^(.+?)-(\d+(?:\.\d+)*(?:-[0-9A-Za-z.-]+)?)-full\.nupkg$`)
+
+// checkSquirrel lê o arquivo RELEASES de um app que atualiza via Squirrel.Windows. Cada linha
+// traz "SHA1 arquivo tamanho". Config aceita:
+//   - url: URL do RELEASES (obrigatório)
+//   - package: nome do pacote, quando o RELEASES lista mais de um
+//   - pattern: regex que a versão deve casar
+//   - include_prereleases: "true" considera versões como 1.0.0-beta1
+//
+// O catálogo publica a URL completa do .nupkg. O SHA-1 publicado não substitui o SHA-256 do
+// catálogo: o pacote é baixado e o SHA-1 do download precisa conferir com o do RELEASES.
+func checkSquirrel(ctx context.Context, src SourceApp) (checkResult, error) {
+	conf := src.Config
+	if conf["url"] == "" {
+		return checkResult{}, fmt.Errorf("squirrel: 'url' é obrigatório")
+	}
+	pattern, err := optionalPattern("squirrel", conf)
+	if err != nil {
+		return checkResult{}, err
+	}
+
+	body, base, err := sourceFetch(ctx, conf, conf["url"], "")
+	if err != nil {
+		return checkResult{}, fmt.Errorf("squirrel: %w", err)
+	}
+
+	var best checkResult
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		sha1, file, sizeStr := fields[0], fields[1], fields[2]
+
+		ref, err := url.Parse(file)
+		if err != nil {
+			continue
+		}
+		m := squirrelFullRe.FindStringSubmatch(path.Base(ref.Path))
+		if m == nil || (conf["package"] != "" && !strings.EqualFold(m[1], conf["package"])) {
+			continue
+		}
+		if !eligibleVersion(conf, pattern, m[2]) {
+			continue
+		}
+		size, err := strconv.ParseInt(sizeStr, 10, 64)
+		if err != nil || size < 0 || len(sha1) != 40 {
+			return checkResult{}, fmt.Errorf("squirrel: linha inválida no RELEASES: %q", scanner.Text())
+		}
+		if best.Version == "" || compareVersions(versionScheme(conf), m[2], best.Version) > 0 {
+			best = checkResult{Version: m[2], URL: base.ResolveReference(ref).String(), Size: size, SHA1: strings.ToLower(sha1)}
+		}
+	}
+	if best.Version == "" {
+		return checkResult{}, fmt.Errorf("squirrel: nenhum pacote -full.nupkg elegível no RELEASES")
+	}
+	return best, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckSquirrel(t *testing.T) {
+	const (
+		sha1A = "0123456789ABCDEF0123456789ABCDEF01234567"
+		sha1B = "89abcdef0123456789abcdef0123456789abcdef"
+	)
+	tests := []struct {
+		name     string
+		releases string
+		conf     map[string]string
+		want     checkResult // URL relativa ao servidor
+		wantErr  string
+	}{
+		{
+			name: "pacote completo mais novo, sem os deltas",
+			releases: "\xef\xbb\xbf" + sha1A + " MyApp-1.0.0-full.nupkg 1000\n" +
+				sha1B + " MyApp-1.1.0-delta.nupkg 50\n" +
+				sha1B + " MyApp-1.1.0-full.nupkg 1100\r\n",
+			want: checkResult{Version: "1.1.0", URL: "/feed/MyApp-1.1.0-full.nupkg", Size: 1100, SHA1: sha1B},
+		},
+		{
+			name:     "URL absoluta",
+			releases: sha1A + " https://cdn.example.org/MyApp-2.0.0-full.nupkg 2000\n",
+			want:     checkResult{Version: "2.0.0", URL: "https://cdn.example.org/MyApp-2.0.0-full.nupkg", Size: 2000, SHA1: strings.ToLower(sha1A)},
+		},
+		{
+			name:     "prerelease ignorada",
+			releases: sha1A + " MyApp-1.0.0-full.nupkg 1000\n" + sha1B + " MyApp-2.0.0-beta1-full.nupkg 2000\n",
+			want:     checkResult{Version: "1.0.0", URL: "/feed/MyApp-1.0.0-full.nupkg", Size: 1000, SHA1: strings.ToLower(sha1A)},
+		},
+		{
+			name:     "prerelease com include_prereleases",
+			releases: sha1A + " MyApp-1.0.0-full.nupkg 1000\n" + sha1B + " MyApp-2.0.0-beta1-full.nupkg 2000\n",
+			conf:     map[string]string{"include_prereleases": "true"},
+			want:     checkResult{Version: "2.0.0-beta1", URL: "/feed/MyApp-2.0.0-beta1-full.nupkg", Size: 2000, SHA1: sha1B},
+		},
+		{
+			name:     "filtro de pacote",
+			releases: sha1A + " MyApp-1.0.0-full.nupkg 1000\n" + sha1B + " Other-9.0.0-full.nupkg 9000\n",
+			conf:     map[string]string{"package": "myapp"},
+			want:     checkResult{Version: "1.0.0", URL: "/feed/MyApp-1.0.0-full.nupkg", Size: 1000, SHA1: strings.ToLower(sha1A)},
+		},
+		{
+			name:     "linhas estranhas e comentários ignorados",
+			releases: "# comentário\nlixo\n" + sha1A + " MyApp-1.0.0-full.nupkg\n" + sha1B + " MyApp-1.0.1-full.nupkg 1001 extra\n" + sha1A + " MyApp-1.0.0-full.nupkg 1000\n",
+			want:     checkResult{Version: "1.0.0", URL: "/feed/MyApp-1.0.0-full.nupkg", Size: 1000, SHA1: strings.ToLower(sha1A)},
+		},
+		{name: "tamanho inválido", releases: sha1A + " MyApp-1.0.0-full.nupkg mil\n", wantErr: "linha inválida"},
+		{name: "tamanho negativo", releases: sha1A + " MyApp-1.0.0-full.nupkg -5\n", wantErr: "linha inválida"},
+		{name: "tamanho fora do int64", releases: sha1A + " MyApp-1.0.0-full.nupkg 99999999999999999999\n", wantErr: "linha inválida"},
+		{name: "SHA-1 curto", releases: "abc123 MyApp-1.0.0-full.nupkg 1000\n", wantErr: "linha inválida"},
+		{name: "só deltas", releases: sha1A + " MyApp-1.0.0-delta.nupkg 50\n", wantErr: "nenhum pacote"},
+		{name: "vazio", releases: "", wantErr: "nenhum pacote"},
+		{name: "sem url", conf: map[string]string{"url": ""}, wantErr: "'url' é obrigatório"},
+		{name: "pattern inválido", conf: map[string]string{"pattern": "("}, wantErr: "pattern inválido"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.releases))
+			}))
+			defer server.Close()
+
+			conf := ConfigMap{"url": server.URL + "/feed/RELEASES"}
+			for k, v := range tt.conf {
+				conf[k] = v
+			}
+			res, err := checkSquirrel(context.Background(), SourceApp{ID: "myapp", Strategy: "squirrel", Config: conf})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("erro = %v, esperado contendo %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tt.want
+			if strings.HasPrefix(want.URL, "/") {
+				want.URL = server.URL + want.URL
+			}
+			want.SHA1 = strings.ToLower(want.SHA1)
+			if !reflect.DeepEqual(res, want) {
+				t.Errorf("resultado = %+v\nesperado  %+v", res, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"time"
+)
+
+// ==========================================
+// ESTRATÉGIA: LINK ESTÁTICO
+// ==========================================
+
+// genericVersionRegex acha uma versão com pelo menos dois componentes num nome de arquivo.
+var genericVersionRegex = regexp.MustCompile(`(\d+(?:\.\d+)+)`)
+
+// checkDirectStatic resolve links fixos (ex: ".../google-chrome-stable_current_amd64.deb"), que não
+// trazem a versão na URL configurada. Um HEAD descobre, em ordem de preferência:
+//  1. a versão no nome do arquivo (Content-Disposition ou URL final após redirects), com o
+//     1º grupo de 'regex' se configurado ou uma versão genérica (ex: 1.2.3);
+//  2. a data do Last-Modified (ex: 2024.05.01);
+//  3. em último caso, a data de hoje como versão provisória — só o hash do download decide
+//     se houve mudança.
+func checkDirectStatic(ctx context.Context, src SourceApp) (checkResult, error) {
+	startURL := src.Config["url"]
+	provisional := checkResult{Version: time.Now().Format("2006.01.02"), URL: startURL, Provisional: true}
+
+	re := genericVersionRegex
+	if pattern := src.Config["regex"]; pattern != "" {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			return checkResult{}, fmt.Errorf("regex inválido: %w", err)
+		}
+	}
+
+	client, err := headClient(src.Config)
+	if err != nil {
+		return checkResult{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "HEAD", startURL, nil)
+	if err != nil {
+		return checkResult{}, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36")
+	debugf("     HEAD %s", startURL)
+	if err := applySourceAuth(req, src.Config); err != nil {
+		return checkResult{}, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Alguns servidores não aceitam HEAD; o download ainda resolve pelo hash
+		verbosef("     HEAD falhou (%v); versão provisória", err)
+		return provisional, nil
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 {
+		verbosef("     HEAD respondeu %d; versão provisória", resp.StatusCode)
+		return provisional, nil
+	}
+
+	res := checkResult{URL: startURL, Size: resp.ContentLength, ReleasedAt: lastModified(resp.Header)}
+	if res.Size < 0 {
+		res.Size = 0
+	}
+
+	// Só o nome após um redirect diz algo sobre a versão; o da URL configurada é fixo
+	finalURL := resp.Request.URL.String()
+	candidates := []string{attachmentFilename(resp.Header)}
+	if finalURL != startURL {
+		candidates = append(candidates, path.Base(resp.Request.URL.Path))
+	}
+	for _, name := range candidates {
+		if name == "" {
+			continue
+		}
+		if m := re.FindStringSubmatch(name); len(m) > 1 {
+			verbosef("     versão extraída de %q", name)
+			res.Version, res.Matches = m[1], m
+			return res, nil
+		}
+	}
+
+	if !res.ReleasedAt.IsZero() {
+		verbosef("     versão pela data do Last-Modified")
+		res.Version = res.ReleasedAt.Format("2006.01.02")
+		return res, nil
+	}
+
+	provisional.Size = res.Size
+	return provisional, nil
+}
+
+// attachmentFilename devolve o filename do header Content-Disposition ("" se ausente).
+func attachmentFilename(h http.Header) string {
+	_, params, err := mime.ParseMediaType(h.Get("Content-Disposition"))
+	if err != nil {
+		return ""
+	}
+	return path.Base(params["filename"])
+}
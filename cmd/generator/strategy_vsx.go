@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// ESTRATÉGIA: EXTENSÕES DE EDITOR (OPEN VSX / MARKETPLACE)
+// ==========================================
+
+const (
+	openVSXDefault     = "https://open-vsx.org"
+	vsMarketplaceQuery = "https://marketplace.visualstudio.com/_apis/public/gallery/extensionquery"
+)
+
+// checkVSX resolve o VSIX mais recente de uma extensão de editor. Config aceita:
+//   - extension: ID no formato publisher.nome, ex: "rust-lang.rust-analyzer" (obrigatório)
+//   - registry: "openvsx" (padrão) ou "marketplace" (Visual Studio Marketplace)
+//   - url: instância própria do Open VSX (padrão: open-vsx.org)
+//   - target_platform: VSIX específico de plataforma, ex: "linux-x64" (padrão: universal)
+//   - include_prereleases: "true" aceita versões marcadas como pre-release
+func checkVSX(ctx context.Context, src SourceApp) (checkResult, error) {
+	conf := src.Config
+	publisher, name, ok := strings.Cut(conf["extension"], ".")
+	if !ok || publisher == "" || name == "" {
+		return checkResult{}, fmt.Errorf("vsx: 'extension' deve ser publisher.nome, veio %q", conf["extension"])
+	}
+
+	switch conf["registry"] {
+	case "", "openvsx":
+		return checkOpenVSX(ctx, conf, publisher, name)
+	case "marketplace":
+		return checkVSMarketplace(ctx, conf, publisher, name)
+	default:
+		return checkResult{}, fmt.Errorf("vsx: registry desconhecido: %q (use openvsx ou marketplace)", conf["registry"])
+	}
+}
+
+// openVSXMaxLookback limita quantas versões anteriores são consultadas atrás de uma estável.
+const openVSXMaxLookback = 10
+
+// checkOpenVSX usa /api/{namespace}/{nome}[/{plataforma}], que devolve a versão mais recente;
+// com pre-releases excluídas, a API é consultada de novo pela última versão estável.
+func checkOpenVSX(ctx context.Context, conf map[string]string, publisher, name string) (checkResult, error) {
+	base := strings.TrimSuffix(conf["url"], "/")
+	if base == "" {
+		base = openVSXDefault
+	}
+	endpoint := fmt.Sprintf("%s/api/%s/%s", base, url.PathEscape(publisher), url.PathEscape(name))
+	if platform := conf["target_platform"]; platform != "" {
+		endpoint += "/" + url.PathEscape(platform)
+	}
+
+	type openVSXExtension struct {
+		Version     string            `json:"version"`
+		PreRelease  bool              `json:"preRelease"`
+		Timestamp   time.Time         `json:"timestamp"`
+		Files       map[string]string `json:"files"`
+		AllVersions map[string]string `json:"allVersions"`
+	}
+	var ext openVSXExtension
+	if err := sourceGetJSON(ctx, conf, endpoint, &ext); err != nil {
+		return checkResult{}, fmt.Errorf("vsx: %w", err)
+	}
+
+	// Pre-releases de extensões costumam usar versões comuns (ex: minor ímpar), então só a API
+	// diz quais são estáveis: desce pelas versões anteriores até achar uma
+	if ext.PreRelease && conf["include_prereleases"] != "true" {
+		var older []string
+		for v := range ext.AllVersions {
+			if v != "latest" && v != "pre-release" && compareSemver(v, ext.Version) < 0 {
+				older = append(older, v)
+			}
+		}
+		slices.SortFunc(older, func(a, b string) int { return compareSemver(b, a) })
+
+		found := false
+		for _, v := range older[:min(len(older), openVSXMaxLookback)] {
+			var candidate openVSXExtension
+			if err := sourceGetJSON(ctx, conf, endpoint+"/"+url.PathEscape(v), &candidate); err != nil {
+				return checkResult{}, fmt.Errorf("vsx: %w", err)
+			}
+			if !candidate.PreRelease {
+				verbosef("     %s é pre-release; usando %s", ext.Version, v)
+				ext, found = candidate, true
+				break
+			}
+		}
+		if !found {
+			return checkResult{}, fmt.Errorf("vsx: nenhuma versão estável recente de %s.%s", publisher, name)
+		}
+	}
+
+	if ext.Files["download"] == "" {
+		return checkResult{}, fmt.Errorf("vsx: resposta sem URL de download")
+	}
+	return checkResult{Version: ext.Version, URL: ext.Files["download"], ReleasedAt: ext.Timestamp.UTC()}, nil
+}
+
+// Flags da extensionquery: IncludeVersions | IncludeFiles | IncludeVersionProperties
+const vsMarketplaceFlags = 0x1 | 0x2 | 0x10
+
+// checkVSMarketplace consulta a API (não documentada, mas estável) usada pelo próprio VS Code.
+func checkVSMarketplace(ctx context.Context, conf map[string]string, publisher, name string) (checkResult, error) {
+	query := map[string]any{
+		"filters": []any{map[string]any{
+			"criteria": []any{
+				map[string]any{"filterType": 7, "value": publisher + "." + name}, // ExtensionName
+				map[string]any{"filterType": 8, "value": "Microsoft.VisualStudio.Code"},
+			},
+		}},
+		"flags": vsMarketplaceFlags,
+	}
+	body, _ := json.Marshal(query)
+	req, err := http.NewRequestWithContext(ctx, "POST", vsMarketplaceQuery, bytes.NewReader(body))
+	if err != nil {
+		return checkResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json;api-version=3.0-preview.1")
+	debugf("     POST %s (%s.%s)", vsMarketplaceQuery, publisher, name)
+
+	client := &http.Client{Timeout: time.Duration(cfg.HTTPTimeout)}
+	resp, err := client.Do(req)
+	if err != nil {
+		return checkResult{}, fmt.Errorf("vsx: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return checkResult{}, fmt.Errorf("vsx: marketplace respondeu %d", resp.StatusCode)
+	}
+
+	var out struct {
+		Results []struct {
+			Extensions []struct {
+				Versions []struct {
+					Version        string    `json:"version"`
+					TargetPlatform string    `json:"targetPlatform"`
+					LastUpdated    time.Time `json:"lastUpdated"`
+					Files          []struct {
+						AssetType string `json:"assetType"`
+						Source    string `json:"source"`
+					} `json:"files"`
+					Properties []struct {
+						Key   string `json:"key"`
+						Value string `json:"value"`
+					} `json:"properties"`
+				} `json:"versions"`
+			} `json:"extensions"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, sourceMaxBodySize)).Decode(&out); err != nil {
+		return checkResult{}, fmt.Errorf("vsx: resposta inválida: %w", err)
+	}
+	if len(out.Results) == 0 || len(out.Results[0].Extensions) == 0 {
+		return checkResult{}, fmt.Errorf("vsx: extensão %s.%s não encontrada no marketplace", publisher, name)
+	}
+
+	// As versões vêm da mais nova para a mais antiga
+	for _, v := range out.Results[0].Extensions[0].Versions {
+		if v.TargetPlatform != conf["target_platform"] {
+			continue
+		}
+		preRelease := false
+		for _, p := range v.Properties {
+			if p.Key == "Microsoft.VisualStudio.Code.PreRelease" && p.Value == "true" {
+				preRelease = true
+			}
+		}
+		if preRelease && conf["include_prereleases"] != "true" {
+			continue
+		}
+		for _, f := range v.Files {
+			if f.AssetType == "Microsoft.VisualStudio.Services.VSIXPackage" {
+				return checkResult{Version: v.Version, URL: f.Source, ReleasedAt: v.LastUpdated.UTC()}, nil
+			}
+		}
+	}
+	return checkResult{}, fmt.Errorf("vsx: nenhuma versão elegível de %s.%s no marketplace", publisher, name)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// ==========================================
+// ESTRATÉGIA: PLUGIN WASM (wazero)
+// ==========================================
+
+// ABI entre o gerador (host) e o módulo WASM. Ponteiros/tamanhos são u32 na memória
+// exportada "memory"; pares (ptr, len) devolvidos como u64 são empacotados em ptr<<32 | len.
+//
+// O módulo deve exportar:
+//   - alloc(size u32) -> ptr u32: reserva memória para o host escrever dados
+//   - check(ptr u32, len u32) -> u64: recebe o SourceApp em JSON e devolve o mesmo JSON
+//     da estratégia exec ({version, url, size, checksum, released_at}), ou {"error": "..."}
+//
+// O host oferece, no módulo de importação "updater":
+//   - http_get(url_ptr u32, url_len u32) -> u64: JSON {status, url, headers, body, error}
+//   - log(ptr u32, len u32): mensagem exibida em modo -vv
+//
+// WASI está disponível sem sistema de arquivos, ambiente ou argumentos, para que módulos
+// compilados por TinyGo/Rust (wasm32-wasi) funcionem sem acesso fora do sandbox.
+const wasmHostModule = "updater"
+
+// checkWasm carrega e executa um plugin WASM. Config aceita:
+//   - module: caminho do arquivo .wasm (obrigatório)
+//   - timeout: duração máxima da execução (padrão: 60s)
+func checkWasm(ctx context.Context, src SourceApp) (checkResult, error) {
+	path := src.Config["module"]
+	if path == "" {
+		return checkResult{}, fmt.Errorf("wasm: 'module' não configurado")
+	}
+	wasm, err := os.ReadFile(path)
+	if err != nil {
+		return checkResult{}, err
+	}
+
+	timeout := 60 * time.Second
+	if v := src.Config["timeout"]; v != "" {
+		if timeout, err = time.ParseDuration(v); err != nil {
+			return checkResult{}, fmt.Errorf("wasm: timeout inválido: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	rt := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+	defer rt.Close(ctx)
+
+	wasi_snapshot_preview1.MustInstantiate(ctx, rt)
+
+	_, err = rt.NewHostModuleBuilder(wasmHostModule).
+		NewFunctionBuilder().WithFunc(wasmHostHTTPGet).Export("http_get").
+		NewFunctionBuilder().WithFunc(wasmHostLog).Export("log").
+		Instantiate(ctx)
+	if err != nil {
+		return checkResult{}, err
+	}
+
+	// Módulos "reactor" exportam _initialize em vez de _start; funções ausentes são ignoradas
+	mod, err := rt.InstantiateWithConfig(ctx, wasm, wazero.NewModuleConfig().
+		WithName(src.ID).
+		WithStartFunctions("_initialize"))
+	if err != nil {
+		return checkResult{}, fmt.Errorf("wasm: %w", err)
+	}
+
+	check := mod.ExportedFunction("check")
+	if check == nil || mod.ExportedFunction("alloc") == nil {
+		return checkResult{}, fmt.Errorf("wasm: o módulo deve exportar 'alloc' e 'check'")
+	}
+
+	input, err := json.Marshal(src)
+	if err != nil {
+		return checkResult{}, err
+	}
+	ptr, err := wasmWrite(ctx, mod, input)
+	if err != nil {
+		return checkResult{}, err
+	}
+
+	ret, err := check.Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return checkResult{}, fmt.Errorf("wasm: %w", err)
+	}
+	output, ok := wasmRead(mod, ret[0])
+	if !ok {
+		return checkResult{}, fmt.Errorf("wasm: retorno fora da memória do módulo")
+	}
+
+	var out struct {
+		execOutput
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(output, &out); err != nil {
+		return checkResult{}, fmt.Errorf("wasm: saída inválida: %w", err)
+	}
+	if out.Error != "" {
+		return checkResult{}, fmt.Errorf("wasm: %s", out.Error)
+	}
+	if out.Version == "" || out.URL == "" {
+		return checkResult{}, fmt.Errorf("wasm: 'version' e 'url' são obrigatórios na saída")
+	}
+
+	return checkResult{
+		Version:    out.Version,
+		URL:        out.URL,
+		Size:       out.Size,
+		Checksum:   out.Checksum,
+		ReleasedAt: out.ReleasedAt,
+	}, nil
+}
+
+// wasmWrite copia data para a memória do módulo usando o alloc exportado por ele.
+func wasmWrite(ctx context.Context, mod api.Module, data []byte) (uint32, error) {
+	ret, err := mod.ExportedFunction("alloc").Call(ctx, uint64(len(data)))
+	if err != nil {
+		return 0, fmt.Errorf("wasm: alloc: %w", err)
+	}
+	ptr := uint32(ret[0])
+	if !mod.Memory().Write(ptr, data) {
+		return 0, fmt.Errorf("wasm: alloc devolveu região inválida")
+	}
+	return ptr, nil
+}
+
+// wasmRead lê um par (ptr, len) empacotado da memória do módulo.
+func wasmRead(mod api.Module, packed uint64) ([]byte, bool) {
+	ptr, size := uint32(packed>>32), uint32(packed)
+	view, ok := mod.Memory().Read(ptr, size)
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), view...), true
+}
+
+func wasmPack(ptr uint32, size int) uint64 {
+	return uint64(ptr)<<32 | uint64(uint32(size))
+}
+
+// wasmHostHTTPGet implementa updater.http_get.
+func wasmHostHTTPGet(ctx context.Context, mod api.Module, urlPtr, urlLen uint32) uint64 {
+	type response struct {
+		Status  int               `json:"status"`
+		URL     string            `json:"url"`
+		Headers map[string]string `json:"headers"`
+		Body    string            `json:"body"`
+		Error   string            `json:"error,omitempty"`
+	}
+
+	var res response
+	if raw, ok := mod.Memory().Read(urlPtr, urlLen); !ok {
+		res.Error = "url fora da memória do módulo"
+	} else if err := wasmDoGet(ctx, string(raw), &res.Status, &res.URL, &res.Headers, &res.Body); err != nil {
+		res.Error = err.Error()
+	}
+
+	out, _ := json.Marshal(res)
+	ptr, err := wasmWrite(ctx, mod, out)
+	if err != nil {
+		return 0
+	}
+	return wasmPack(ptr, len(out))
+}
+
+func wasmDoGet(ctx context.Context, url string, status *int, finalURL *string, headers *map[string]string, body *string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	debugf("     [wasm] GET %s", url)
+
+	client := &http.Client{Timeout: time.Duration(cfg.HTTPTimeout)}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, scriptMaxBodySize))
+	if err != nil {
+		return err
+	}
+
+	*status, *finalURL, *body = resp.StatusCode, resp.Request.URL.String(), string(data)
+	*headers = make(map[string]string, len(resp.Header))
+	for k := range resp.Header {
+		(*headers)[strings.ToLower(k)] = resp.Header.Get(k)
+	}
+	return nil
+}
+
+// wasmHostLog implementa updater.log.
+func wasmHostLog(_ context.Context, mod api.Module, ptr, size uint32) {
+	if msg, ok := mod.Memory().Read(ptr, size); ok {
+		debugf("     [wasm] %s", msg)
+	}
+}
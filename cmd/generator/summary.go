@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ==========================================
+// RESUMO EM MARKDOWN
+// ==========================================
+
+// markdownSummary gera uma tabela com as atualizações da execução (ex: "firefox 124.0 → 125.0, +82 MB")
+// e a lista de falhas, pronta para ser usada como comentário de PR ou corpo de commit.
+func markdownSummary(results []appResult) string {
+	var updated, pending, failed, orphaned, removed, stale []appResult
+	for _, res := range results {
+		if res.HasApp && res.App.Stale {
+			stale = append(stale, res)
+		}
+		switch res.Status() {
+		case "updated":
+			updated = append(updated, res)
+		case "pending":
+			pending = append(pending, res)
+		case "failed":
+			failed = append(failed, res)
+		case "orphaned":
+			orphaned = append(orphaned, res)
+		case "removed":
+			removed = append(removed, res)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("## Atualizações do catálogo\n\n")
+
+	if len(updated) == 0 {
+		b.WriteString("Nenhuma alteração.\n")
+	} else {
+		b.WriteString("| App | Versão | Tamanho |\n")
+		b.WriteString("|-----|--------|---------|\n")
+		for _, res := range updated {
+			from := res.OldVersion
+			if from == "" {
+				from = "_novo_"
+			}
+			fmt.Fprintf(&b, "| %s | %s → %s | %s (%s) |\n",
+				res.ID, from, res.App.Version, formatBytes(res.App.Size), formatSizeDelta(res.App.Size-res.OldSize))
+		}
+	}
+
+	if len(pending) > 0 {
+		b.WriteString("\n### Aguardando aprovação\n\n")
+		for _, res := range pending {
+			from := res.OldVersion
+			if from == "" {
+				from = "_novo_"
+			}
+			fmt.Fprintf(&b, "- **%s**: %s → %s\n", res.ID, from, res.NewVersion)
+		}
+	}
+
+	if len(removed) > 0 {
+		b.WriteString("\n### Removidos do catálogo\n\n")
+		for _, res := range removed {
+			fmt.Fprintf(&b, "- **%s** %s (sem fonte)\n", res.ID, res.OldVersion)
+		}
+	}
+
+	if len(orphaned) > 0 {
+		b.WriteString("\n### Sem fonte (orphaned)\n\n")
+		for _, res := range orphaned {
+			fmt.Fprintf(&b, "- **%s** %s — retire com `-prune`\n", res.ID, res.OldVersion)
+		}
+	}
+
+	if len(stale) > 0 {
+		b.WriteString("\n### Sem verificação recente (stale)\n\n")
+		for _, res := range stale {
+			fmt.Fprintf(&b, "- **%s** %s: última checagem em %s, %d falhas seguidas\n",
+				res.ID, res.App.Version, res.App.LastChecked.Format("2006-01-02"), res.App.ConsecutiveFailures)
+		}
+	}
+
+	if len(failed) > 0 {
+		b.WriteString("\n### Falhas\n\n")
+		for _, res := range failed {
+			fmt.Fprintf(&b, "- **%s**: %s\n", res.ID, strings.ReplaceAll(res.Err.Error(), "\n", " "))
+		}
+	}
+
+	return b.String()
+}
+
+// formatBytes formata um tamanho em unidades decimais (kB, MB, GB).
+func formatBytes(n int64) string {
+	const unit = 1000
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value := float64(n)
+	suffixes := []string{"kB", "MB", "GB", "TB"}
+	i := -1
+	for (value >= unit || value <= -unit) && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.0f %s", value, suffixes[i])
+}
+
+// formatSizeDelta formata a variação de tamanho com sinal explícito ("+82 MB", "-3 MB").
+func formatSizeDelta(delta int64) string {
+	if delta >= 0 {
+		return "+" + formatBytes(delta)
+	}
+	return formatBytes(delta)
+}
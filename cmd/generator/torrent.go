@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// TORRENT / MAGNET
+// ==========================================
+
+// TorrentConfig ativa a criação de .torrent para artefatos grandes, permitindo que clientes
+// baixem via P2P e verifiquem o resultado com o checksum do catálogo. Os pedaços são
+// calculados no mesmo stream do download, sem baixar o arquivo de novo.
+type TorrentConfig struct {
+	Dir         string   `json:"dir"`          // Onde gravar os .torrent (vazio = desativado)
+	BaseURL     string   `json:"base_url"`     // URL pública de Dir, para o campo torrent_url
+	Trackers    []string `json:"trackers"`     // Announce URLs (opcional; magnet com web seed funciona sem)
+	MinSize     int64    `json:"min_size"`     // Só gera para artefatos a partir deste tamanho (bytes)
+	PieceLength int64    `json:"piece_length"` // Tamanho do pedaço (padrão: 4 MiB)
+}
+
+func (t TorrentConfig) enabled() bool {
+	return t.Dir != ""
+}
+
+func (t TorrentConfig) pieceLength() int64 {
+	if t.PieceLength > 0 {
+		return t.PieceLength
+	}
+	return 4 << 20
+}
+
+// torrentPieces é um io.Writer que calcula os SHA1 dos pedaços à medida que o stream passa.
+type torrentPieces struct {
+	length  int64
+	current []byte
+	hashes  []byte
+}
+
+func newTorrentPieces(length int64) *torrentPieces {
+	return &torrentPieces{length: length, current: make([]byte, 0, length)}
+}
+
+func (p *torrentPieces) Write(b []byte) (int, error) {
+	n := len(b)
+	for len(b) > 0 {
+		take := min(int(p.length)-len(p.current), len(b))
+		p.current = append(p.current, b[:take]...)
+		b = b[take:]
+		if int64(len(p.current)) == p.length {
+			p.flush()
+		}
+	}
+	return n, nil
+}
+
+func (p *torrentPieces) flush() {
+	sum := sha1.Sum(p.current)
+	p.hashes = append(p.hashes, sum[:]...)
+	p.current = p.current[:0]
+}
+
+// finish fecha o último pedaço (parcial).
+func (p *torrentPieces) finish() {
+	if len(p.current) > 0 {
+		p.flush()
+	}
+}
+
+// writeTorrent grava o .torrent do artefato (com o próprio download_url como web seed)
+// e devolve o magnet link e a URL pública do arquivo.
+func writeTorrent(app CatalogApp, pieces *torrentPieces) (magnet, torrentURL string, err error) {
+	t := cfg.Torrent
+	name := path.Base(app.DownloadURL)
+	if u, err := url.Parse(app.DownloadURL); err == nil && path.Base(u.Path) != "/" {
+		name = path.Base(u.Path)
+	}
+
+	info := map[string]interface{}{
+		"length":       app.Size,
+		"name":         name,
+		"piece length": pieces.length,
+		"pieces":       string(pieces.hashes),
+	}
+	infoBytes := bencode(info)
+	infoHash := sha1.Sum(infoBytes)
+
+	meta := map[string]interface{}{
+		"info":          rawBencode(infoBytes),
+		"url-list":      []interface{}{app.DownloadURL},
+		"created by":    "updater-registry " + resolveGeneratorVersion(),
+		"creation date": time.Now().Unix(),
+	}
+	if len(t.Trackers) > 0 {
+		meta["announce"] = t.Trackers[0]
+		var tiers []interface{}
+		for _, tr := range t.Trackers {
+			tiers = append(tiers, []interface{}{tr})
+		}
+		meta["announce-list"] = tiers
+	}
+
+	file := fmt.Sprintf("%s-%s.torrent", app.ID, app.Version)
+	if err := os.MkdirAll(t.Dir, 0755); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(filepath.Join(t.Dir, file), bencode(meta), 0644); err != nil {
+		return "", "", err
+	}
+
+	q := url.Values{}
+	q.Set("dn", name)
+	q.Set("xl", strconv.FormatInt(app.Size, 10))
+	q.Set("ws", app.DownloadURL)
+	for _, tr := range t.Trackers {
+		q.Add("tr", tr)
+	}
+	magnet = "magnet:?xt=urn:btih:" + hex.EncodeToString(infoHash[:]) + "&" + q.Encode()
+
+	if t.BaseURL != "" {
+		torrentURL = strings.TrimSuffix(t.BaseURL, "/") + "/" + file
+	}
+	return magnet, torrentURL, nil
+}
+
+// rawBencode é um valor já codificado, inserido como está (o info dict precisa ser
+// byte-a-byte o mesmo usado no cálculo do infohash).
+type rawBencode []byte
+
+// bencode codifica strings, inteiros, listas e dicionários (chaves ordenadas).
+func bencode(v interface{}) []byte {
+	var b bytes.Buffer
+	bencodeTo(&b, v)
+	return b.Bytes()
+}
+
+func bencodeTo(b *bytes.Buffer, v interface{}) {
+	switch x := v.(type) {
+	case rawBencode:
+		b.Write(x)
+	case string:
+		fmt.Fprintf(b, "%d:%s", len(x), x)
+	case int64:
+		fmt.Fprintf(b, "i%de", x)
+	case int:
+		fmt.Fprintf(b, "i%de", x)
+	case []interface{}:
+		b.WriteByte('l')
+		for _, item := range x {
+			bencodeTo(b, item)
+		}
+		b.WriteByte('e')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteByte('d')
+		for _, k := range keys {
+			bencodeTo(b, k)
+			bencodeTo(b, x[k])
+		}
+		b.WriteByte('e')
+	default:
+		panic(fmt.Sprintf("bencode: tipo não suportado %T", v))
+	}
+}
@@ -0,0 +1,446 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// METADADOS TUF (THE UPDATE FRAMEWORK)
+// ==========================================
+
+// Com tuf.dir, cada gravação do catálogo de produção gera os metadados TUF (root.json,
+// targets.json, snapshot.json e timestamp.json, spec 1.0) com o próprio catálogo e os
+// artefatos dos apps como targets, para clientes go-tuf (ou python-tuf) verificarem o que
+// baixam com proteção contra rollback e freeze. Os targets dos artefatos seguem o layout de
+// artifacts.dir ({id}/{versão}/{arquivo}), de modo que esse diretório pode servir de base dos
+// targets; a URL de origem vai em custom.url.
+
+type TUFConfig struct {
+	Dir              string   `json:"dir"`                // Onde gravar os metadados (local, s3://, gs:// ou az://; vazio = desativado)
+	RootKey          string   `json:"root_key"`           // Chave Ed25519 (PEM) do papel root
+	TargetsKey       string   `json:"targets_key"`        // Chave do papel targets (vazio = root_key)
+	SnapshotKey      string   `json:"snapshot_key"`       // Chave do papel snapshot (vazio = targets_key)
+	TimestampKey     string   `json:"timestamp_key"`      // Chave do papel timestamp (vazio = snapshot_key)
+	PreviousRootKeys []string `json:"previous_root_keys"` // Chaves root anteriores, que também assinam a nova root numa rotação
+	RootExpires      Duration `json:"root_expires"`       // Validade de cada papel (padrões: 1 ano, 90 dias, 7 dias, 1 dia)
+	TargetsExpires   Duration `json:"targets_expires"`
+	SnapshotExpires  Duration `json:"snapshot_expires"`
+	TimestampExpires Duration `json:"timestamp_expires"`
+}
+
+func (t TUFConfig) enabled() bool {
+	return t.Dir != ""
+}
+
+// tufSpecVersion é a versão da especificação declarada nos metadados.
+const tufSpecVersion = "1.0.31"
+
+// tufRoles são os papéis de topo, na ordem em que os arquivos são gravados: o timestamp por
+// último, pois é por ele que o cliente começa.
+var tufRoles = []string{"root", "targets", "snapshot", "timestamp"}
+
+// expires é a validade do papel: a configurada ou o padrão.
+func (t TUFConfig) expires(role string) time.Duration {
+	configured := map[string]Duration{"root": t.RootExpires, "targets": t.TargetsExpires,
+		"snapshot": t.SnapshotExpires, "timestamp": t.TimestampExpires}[role]
+	if configured > 0 {
+		return time.Duration(configured)
+	}
+	return map[string]time.Duration{"root": 365 * 24 * time.Hour, "targets": 90 * 24 * time.Hour,
+		"snapshot": 7 * 24 * time.Hour, "timestamp": 24 * time.Hour}[role]
+}
+
+// keyFile é o arquivo da chave do papel: a do papel ou, se vazia, a do papel anterior em
+// tufRoles.
+func (t TUFConfig) keyFile(role string) string {
+	chain := []string{t.RootKey, t.TargetsKey, t.SnapshotKey, t.TimestampKey}
+	file := ""
+	for i, r := range tufRoles {
+		if chain[i] != "" {
+			file = chain[i]
+		}
+		if r == role {
+			break
+		}
+	}
+	return file
+}
+
+// tufEnvelope é um arquivo de metadados: o conteúdo assinado e as assinaturas dele.
+type tufEnvelope struct {
+	Signatures []tufSignature  `json:"signatures"`
+	Signed     json.RawMessage `json:"signed"`
+}
+
+type tufSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"` // Ed25519 do JSON canônico de signed, em hexadecimal
+}
+
+// tufCommon são os campos presentes em todo papel.
+type tufCommon struct {
+	Type        string `json:"_type"`
+	SpecVersion string `json:"spec_version"`
+	Version     int    `json:"version"`
+	Expires     string `json:"expires"` // RFC 3339, UTC e sem frações de segundo
+}
+
+type tufKey struct {
+	KeyType string            `json:"keytype"`
+	Scheme  string            `json:"scheme"`
+	KeyVal  map[string]string `json:"keyval"` // {"public": hex}
+}
+
+type tufRole struct {
+	KeyIDs    []string `json:"keyids"`
+	Threshold int      `json:"threshold"`
+}
+
+type tufRoot struct {
+	tufCommon
+	ConsistentSnapshot bool               `json:"consistent_snapshot"`
+	Keys               map[string]tufKey  `json:"keys"`
+	Roles              map[string]tufRole `json:"roles"`
+}
+
+type tufTarget struct {
+	Length int64             `json:"length"`
+	Hashes map[string]string `json:"hashes"`
+	Custom map[string]string `json:"custom,omitempty"`
+}
+
+type tufTargets struct {
+	tufCommon
+	Targets map[string]tufTarget `json:"targets"`
+}
+
+type tufMetaFile struct {
+	Version int               `json:"version"`
+	Length  int64             `json:"length,omitempty"`
+	Hashes  map[string]string `json:"hashes,omitempty"`
+}
+
+// tufMeta serve ao snapshot (targets.json) e ao timestamp (snapshot.json).
+type tufMeta struct {
+	tufCommon
+	Meta map[string]tufMetaFile `json:"meta"`
+}
+
+// tufFile é um arquivo a gravar em tuf.dir.
+type tufFile struct {
+	name string
+	data []byte
+}
+
+// tufMetadata monta os metadados do catálogo data. Um papel cujo conteúdo não mudou e que
+// ainda está longe de expirar é mantido como está (mesma versão, sem regravar); o timestamp é
+// sempre renovado. A root ganha versão nova quando as chaves mudam, e também é gravada como
+// {versão}.root.json, que é por onde os clientes a atualizam.
+func tufMetadata(data []byte, catalog Catalog) ([]tufFile, error) {
+	keys := make(map[string]ed25519.PrivateKey)
+	for _, role := range tufRoles {
+		file := cfg.TUF.keyFile(role)
+		if file == "" {
+			return nil, fmt.Errorf("tuf.root_key não configurada")
+		}
+		key, err := loadSigningKey(file)
+		if err != nil {
+			return nil, err
+		}
+		keys[role] = key
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	var files []tufFile
+
+	// Root
+	root := tufRoot{tufCommon: tufCommon{Type: "root", SpecVersion: tufSpecVersion}, Keys: make(map[string]tufKey), Roles: make(map[string]tufRole)}
+	for _, role := range tufRoles {
+		key := newTUFKey(keys[role].Public().(ed25519.PublicKey))
+		id, err := tufKeyID(key)
+		if err != nil {
+			return nil, err
+		}
+		root.Keys[id] = key
+		root.Roles[role] = tufRole{KeyIDs: []string{id}, Threshold: 1}
+	}
+	var prevRoot tufRoot
+	prevRootVersion, err := readTUF("root", &prevRoot)
+	if err != nil {
+		return nil, err
+	}
+	if tufRenew("root", prevRoot.tufCommon, now) || !tufEqual(prevRoot.Roles, root.Roles) || !tufEqual(prevRoot.Keys, root.Keys) {
+		root.Version, root.Expires = prevRootVersion+1, tufExpiry("root", now)
+		signers := []ed25519.PrivateKey{keys["root"]}
+		for _, file := range cfg.TUF.PreviousRootKeys {
+			key, err := loadSigningKey(file)
+			if err != nil {
+				return nil, err
+			}
+			signers = append(signers, key)
+		}
+		rootData, err := signTUF(root, signers...)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, tufFile{"root.json", rootData}, tufFile{fmt.Sprintf("%d.root.json", root.Version), rootData})
+		infof("     TUF: root versão %d", root.Version)
+	}
+
+	// Targets: o catálogo e os artefatos
+	targets := tufTargets{tufCommon: tufCommon{Type: "targets", SpecVersion: tufSpecVersion}, Targets: catalogTargets(data, catalog)}
+	var prevTargets tufTargets
+	targets.Version, err = readTUF("targets", &prevTargets)
+	if err != nil {
+		return nil, err
+	}
+	targets.Expires = prevTargets.Expires
+	if tufRenew("targets", prevTargets.tufCommon, now) || !tufEqual(prevTargets.Targets, targets.Targets) {
+		targets.Version, targets.Expires = targets.Version+1, tufExpiry("targets", now)
+		targetsData, err := signTUF(targets, keys["targets"])
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, tufFile{"targets.json", targetsData})
+	}
+
+	// Snapshot: a versão do targets
+	snapshot := tufMeta{tufCommon: tufCommon{Type: "snapshot", SpecVersion: tufSpecVersion},
+		Meta: map[string]tufMetaFile{"targets.json": {Version: targets.Version}}}
+	var prevSnapshot tufMeta
+	snapshot.Version, err = readTUF("snapshot", &prevSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Expires = prevSnapshot.Expires
+	snapshotData, err := readBlob(tufPath("snapshot.json"))
+	if tufRenew("snapshot", prevSnapshot.tufCommon, now) || !tufEqual(prevSnapshot.Meta, snapshot.Meta) || err != nil {
+		snapshot.Version, snapshot.Expires = snapshot.Version+1, tufExpiry("snapshot", now)
+		if snapshotData, err = signTUF(snapshot, keys["snapshot"]); err != nil {
+			return nil, err
+		}
+		files = append(files, tufFile{"snapshot.json", snapshotData})
+	}
+
+	// Timestamp: a versão e o hash do snapshot, renovado a cada gravação
+	sum := sha256.Sum256(snapshotData)
+	timestamp := tufMeta{tufCommon: tufCommon{Type: "timestamp", SpecVersion: tufSpecVersion, Expires: tufExpiry("timestamp", now)},
+		Meta: map[string]tufMetaFile{"snapshot.json": {Version: snapshot.Version, Length: int64(len(snapshotData)), Hashes: map[string]string{"sha256": hex.EncodeToString(sum[:])}}}}
+	var prevTimestamp tufMeta
+	if timestamp.Version, err = readTUF("timestamp", &prevTimestamp); err != nil {
+		return nil, err
+	}
+	timestamp.Version++
+	timestampData, err := signTUF(timestamp, keys["timestamp"])
+	if err != nil {
+		return nil, err
+	}
+	return append(files, tufFile{"timestamp.json", timestampData}), nil
+}
+
+// writeTUF grava os arquivos em tuf.dir, na ordem de tufMetadata.
+func writeTUF(files []tufFile) error {
+	for _, file := range files {
+		if err := writeBlob(tufPath(file.name), file.data, "application/json"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshTUF renova os metadados sem regravar o catálogo, para que o timestamp não expire
+// numa execução sem alterações (o cliente trataria a falta de renovação como freeze attack).
+// Os targets vêm do arquivo publicado, que com encrypt não traz os apps privados.
+func refreshTUF() error {
+	data, err := readBlob(cfg.CatalogPath)
+	if err != nil {
+		return err
+	}
+	files, err := tufMetadata(data, parseCatalog(data))
+	if err != nil {
+		return err
+	}
+	return writeTUF(files)
+}
+
+// catalogTargets lista o catálogo e cada artefato com hash e tamanho conhecidos (inclusive
+// os dos canais extras).
+func catalogTargets(data []byte, catalog Catalog) map[string]tufTarget {
+	sum := sha256.Sum256(data)
+	targets := map[string]tufTarget{
+		path.Base(cfg.CatalogPath): {Length: int64(len(data)), Hashes: map[string]string{"sha256": hex.EncodeToString(sum[:])}},
+	}
+	add := func(id, channel, version, downloadURL, checksum, sha512 string, size int64) {
+		if size <= 0 || (checksum == "" && sha512 == "") {
+			return
+		}
+		target := tufTarget{Length: size, Hashes: make(map[string]string), Custom: map[string]string{"id": id, "version": version, "url": downloadURL}}
+		if checksum != "" {
+			target.Hashes["sha256"] = strings.ToLower(checksum)
+		}
+		if sha512 != "" {
+			target.Hashes["sha512"] = strings.ToLower(sha512)
+		}
+		if channel != "" {
+			target.Custom["channel"] = channel
+		}
+		targets[id+"/"+versionDirReplacer.Replace(version)+"/"+artifactName(downloadURL)] = target
+	}
+	for _, id := range slices.Sorted(maps.Keys(catalog.Apps)) {
+		app := catalog.Apps[id]
+		add(id, "", app.Version, app.DownloadURL, app.Checksum, app.SHA512, app.Size)
+		for _, name := range slices.Sorted(maps.Keys(app.Channels)) {
+			rel := app.Channels[name]
+			add(id, name, rel.Version, rel.DownloadURL, rel.Checksum, rel.SHA512, rel.Size)
+		}
+	}
+	return targets
+}
+
+func newTUFKey(pub ed25519.PublicKey) tufKey {
+	return tufKey{KeyType: "ed25519", Scheme: "ed25519", KeyVal: map[string]string{"public": hex.EncodeToString(pub)}}
+}
+
+// tufKeyID é o SHA-256 do JSON canônico da chave, como calculam os clientes.
+func tufKeyID(key tufKey) (string, error) {
+	data, err := canonicalJSON(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func tufExpiry(role string, now time.Time) string {
+	return now.Add(cfg.TUF.expires(role)).Format(time.RFC3339)
+}
+
+// tufRenew diz se o papel precisa de versão nova por validade: quando não existe ou já passou
+// da metade do prazo.
+func tufRenew(role string, prev tufCommon, now time.Time) bool {
+	expires, err := time.Parse(time.RFC3339, prev.Expires)
+	return err != nil || expires.Sub(now) < cfg.TUF.expires(role)/2
+}
+
+// tufEqual compara dois trechos de metadados pelo JSON canônico.
+func tufEqual(a, b any) bool {
+	ca, errA := canonicalJSON(a)
+	cb, errB := canonicalJSON(b)
+	return errA == nil && errB == nil && bytes.Equal(ca, cb)
+}
+
+func tufPath(name string) string {
+	return strings.TrimSuffix(cfg.TUF.Dir, "/") + "/" + name
+}
+
+// readTUF lê o papel gravado anteriormente em signed e devolve a versão dele (0 quando não
+// existe). As assinaturas não são conferidas: o arquivo é do próprio gerador.
+func readTUF(role string, signed any) (int, error) {
+	data, err := readBlob(tufPath(role + ".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s.json: %w", role, err)
+	}
+	var envelope tufEnvelope
+	var common tufCommon
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return 0, fmt.Errorf("%s.json: %w", role, err)
+	}
+	if err := json.Unmarshal(envelope.Signed, signed); err != nil {
+		return 0, fmt.Errorf("%s.json: %w", role, err)
+	}
+	json.Unmarshal(envelope.Signed, &common)
+	return common.Version, nil
+}
+
+// signTUF assina o JSON canônico de signed com cada chave e monta o arquivo.
+func signTUF(signed any, keys ...ed25519.PrivateKey) ([]byte, error) {
+	canonical, err := canonicalJSON(signed)
+	if err != nil {
+		return nil, err
+	}
+	envelope := tufEnvelope{Signed: canonical}
+	for _, key := range keys {
+		id, err := tufKeyID(newTUFKey(key.Public().(ed25519.PublicKey)))
+		if err != nil {
+			return nil, err
+		}
+		envelope.Signatures = append(envelope.Signatures, tufSignature{KeyID: id, Sig: hex.EncodeToString(ed25519.Sign(key, canonical))})
+	}
+	return json.MarshalIndent(envelope, "", "  ")
+}
+
+// canonicalJSON serializa v no JSON canônico usado pelo TUF (OLPC): chaves ordenadas, sem
+// espaços, só inteiros e strings com apenas \ e " escapados.
+func canonicalJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var canonicalReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func writeCanonical(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		fmt.Fprint(buf, v)
+	case json.Number:
+		if _, err := v.Int64(); err != nil {
+			return fmt.Errorf("JSON canônico não aceita o número %s", v)
+		}
+		buf.WriteString(v.String())
+	case string:
+		buf.WriteString(`"` + canonicalReplacer.Replace(v) + `"`)
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		buf.WriteByte('{')
+		for i, key := range slices.Sorted(maps.Keys(v)) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(`"` + canonicalReplacer.Replace(key) + `":`)
+			if err := writeCanonical(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	}
+	return nil
+}
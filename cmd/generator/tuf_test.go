@@ -0,0 +1,201 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"chaves ordenadas e sem espaços", map[string]any{"b": 1, "a": []any{true, false, nil}, "c": map[string]any{"z": "", "y": -2}},
+			`{"a":[true,false,null],"b":1,"c":{"y":-2,"z":""}}`},
+		{"escapa só \\ e \"", map[string]any{"s": "a\"b\\c<d>&é\n\t"}, "{\"s\":\"a\\\"b\\\\c<d>&é\n\t\"}"},
+		{"chave com escape", map[string]any{`k"\`: 0}, `{"k\"\\":0}`},
+		{"struct com tags", tufKey{KeyType: "ed25519", Scheme: "ed25519", KeyVal: map[string]string{"public": "ab"}},
+			`{"keytype":"ed25519","keyval":{"public":"ab"},"scheme":"ed25519"}`},
+		{"inteiro grande", map[string]any{"length": int64(1) << 40}, `{"length":1099511627776}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := canonicalJSON(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("canonicalJSON = %s\nesperado      %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := canonicalJSON(map[string]any{"x": 1.5}); err == nil {
+		t.Error("número não inteiro deveria ser rejeitado")
+	}
+}
+
+// writeTestKey grava uma chave Ed25519 nova em PEM PKCS#8, como a do openssl genpkey.
+func writeTestKey(t *testing.T, path string) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tufSigned devolve o trecho signed do arquivo, antes de qualquer conferência.
+func tufSigned(t *testing.T, data []byte) json.RawMessage {
+	t.Helper()
+	var envelope tufEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	return envelope.Signed
+}
+
+// verifyTUF confere as assinaturas do papel com as chaves e o limiar declarados na root,
+// recalculando o JSON canônico de signed como faz um cliente.
+func verifyTUF(t *testing.T, root tufRoot, role string, data []byte) tufEnvelope {
+	t.Helper()
+	var envelope tufEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatalf("%s: %v", role, err)
+	}
+	var signed any
+	if err := json.Unmarshal(envelope.Signed, &signed); err != nil {
+		t.Fatalf("%s: %v", role, err)
+	}
+	canonical, err := canonicalJSON(signed)
+	if err != nil {
+		t.Fatalf("%s: %v", role, err)
+	}
+
+	valid := 0
+	for _, sig := range envelope.Signatures {
+		key, ok := root.Keys[sig.KeyID]
+		if !ok || !slices.Contains(root.Roles[role].KeyIDs, sig.KeyID) {
+			continue
+		}
+		if id, _ := tufKeyID(key); id != sig.KeyID {
+			t.Errorf("%s: keyid %s não corresponde à chave", role, sig.KeyID)
+		}
+		pub, _ := hex.DecodeString(key.KeyVal["public"])
+		raw, _ := hex.DecodeString(sig.Sig)
+		if ed25519.Verify(pub, canonical, raw) {
+			valid++
+		}
+	}
+	if valid < root.Roles[role].Threshold || valid == 0 {
+		t.Errorf("%s: %d assinaturas válidas, limiar %d", role, valid, root.Roles[role].Threshold)
+	}
+	return envelope
+}
+
+func TestTUFMetadataSignatures(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"root.pem", "targets.pem", "timestamp.pem"} {
+		writeTestKey(t, filepath.Join(dir, name))
+	}
+	saved := cfg
+	t.Cleanup(func() { cfg = saved })
+	cfg.CatalogPath = filepath.Join(dir, "catalog.json")
+	cfg.TUF = TUFConfig{
+		Dir:          filepath.Join(dir, "tuf"),
+		RootKey:      filepath.Join(dir, "root.pem"),
+		TargetsKey:   filepath.Join(dir, "targets.pem"),
+		TimestampKey: filepath.Join(dir, "timestamp.pem"),
+	}
+
+	catalog := Catalog{Apps: map[string]CatalogApp{"foo": {
+		Version: "1.2.3", DownloadURL: "https://acme.org/foo_1.2.3_amd64.deb",
+		Checksum: "AB" + hex.EncodeToString(make([]byte, 31)), Size: 1234,
+	}}}
+	data, err := json.Marshal(catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := tufMetadata(data, catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := make(map[string][]byte)
+	for _, file := range files {
+		written[file.name] = file.data
+	}
+	for _, name := range []string{"root.json", "1.root.json", "targets.json", "snapshot.json", "timestamp.json"} {
+		if written[name] == nil {
+			t.Fatalf("%s não gerado", name)
+		}
+	}
+
+	// A root é autoassinada: confere com as próprias chaves
+	var root tufRoot
+	if err := json.Unmarshal(tufSigned(t, written["root.json"]), &root); err != nil {
+		t.Fatal(err)
+	}
+	verifyTUF(t, root, "root", written["root.json"])
+	if root.Roles["snapshot"].KeyIDs[0] != root.Roles["targets"].KeyIDs[0] {
+		t.Error("snapshot_key vazia deveria herdar targets_key")
+	}
+
+	var targets tufTargets
+	json.Unmarshal(verifyTUF(t, root, "targets", written["targets.json"]).Signed, &targets)
+	sum := sha256.Sum256(data)
+	if got := targets.Targets["catalog.json"]; got.Length != int64(len(data)) || got.Hashes["sha256"] != hex.EncodeToString(sum[:]) {
+		t.Errorf("target do catálogo = %+v", got)
+	}
+	artifact, ok := targets.Targets["foo/1.2.3/foo_1.2.3_amd64.deb"]
+	if !ok || artifact.Length != 1234 || artifact.Hashes["sha256"] != "ab"+hex.EncodeToString(make([]byte, 31)) {
+		t.Errorf("target do artefato = %+v (%v)", artifact, ok)
+	}
+
+	var snapshot tufMeta
+	json.Unmarshal(verifyTUF(t, root, "snapshot", written["snapshot.json"]).Signed, &snapshot)
+	if snapshot.Meta["targets.json"].Version != targets.Version {
+		t.Errorf("snapshot aponta targets versão %d, esperada %d", snapshot.Meta["targets.json"].Version, targets.Version)
+	}
+
+	var timestamp tufMeta
+	json.Unmarshal(verifyTUF(t, root, "timestamp", written["timestamp.json"]).Signed, &timestamp)
+	snapSum := sha256.Sum256(written["snapshot.json"])
+	meta := timestamp.Meta["snapshot.json"]
+	if meta.Version != snapshot.Version || meta.Length != int64(len(written["snapshot.json"])) || meta.Hashes["sha256"] != hex.EncodeToString(snapSum[:]) {
+		t.Errorf("timestamp não descreve o snapshot gravado: %+v", meta)
+	}
+
+	// Segunda execução sem mudanças: só o timestamp ganha versão nova
+	if err := writeTUF(files); err != nil {
+		t.Fatal(err)
+	}
+	again, err := tufMetadata(data, catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 1 || again[0].name != "timestamp.json" {
+		t.Fatalf("segunda execução gerou %d arquivos, esperado só timestamp.json", len(again))
+	}
+	var renewed tufMeta
+	json.Unmarshal(verifyTUF(t, root, "timestamp", again[0].data).Signed, &renewed)
+	if renewed.Version != timestamp.Version+1 || renewed.Meta["snapshot.json"].Hashes["sha256"] != meta.Hashes["sha256"] {
+		t.Errorf("timestamp renovado = %+v", renewed)
+	}
+}
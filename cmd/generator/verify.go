@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ==========================================
+// CONFERÊNCIA DE HASHES PUBLICADOS À PARTE
+// ==========================================
+
+// sha256HexRe reconhece um SHA-256 em hexadecimal.
+var sha256HexRe = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// verifyDownload confere o artefato baixado contra tudo o que a origem publicou sobre ele:
+//   - hashes publicados à parte (SHA-1 do Squirrel, SHA-256 do texto da release ou do .sha256
+//     irmão) sempre reprovam um download divergente;
+//   - checksum e tamanho informados pela estratégia (quando o download acontece mesmo assim) e
+//     um .sha256 irmão ilegível só geram aviso, e o que foi baixado é publicado — a menos que
+//     strict_checksums esteja ativo, quando também reprovam o download.
+func verifyDownload(ctx context.Context, src SourceApp, online *checkResult, dl downloadResult) error {
+	var soft []error
+	if err := loadSiblingChecksum(ctx, src, online); err != nil {
+		soft = append(soft, fmt.Errorf("falha ao ler %s: %w", online.ExpectedFrom, err))
+	}
+	if err := verifyPublishedHashes(*online, dl); err != nil {
+		return err
+	}
+	if online.Checksum != "" && !strings.EqualFold(online.Checksum, dl.Checksum) {
+		soft = append(soft, fmt.Errorf("SHA-256 do download (%s) difere do informado pela estratégia (%s)", dl.Checksum, strings.ToLower(online.Checksum)))
+	}
+	if online.Size > 0 && online.Size != dl.Size {
+		soft = append(soft, fmt.Errorf("tamanho do download (%d bytes) difere do informado pela estratégia (%d)", dl.Size, online.Size))
+	}
+
+	if len(soft) == 0 {
+		return nil
+	}
+	if cfg.StrictChecksums {
+		return errors.Join(soft...)
+	}
+	for _, err := range soft {
+		errorf(" [AVISO] %s: %v. Publicando o que foi baixado (strict_checksums desativado).", src.ID, err)
+	}
+	return nil
+}
+
+// verifyPublishedHashes confere o download contra os hashes que a origem publica mas que não
+// dispensam o download (SHA-1 do Squirrel, SHA-256 colado no texto da release): um arquivo
+// divergente não é publicado.
+func verifyPublishedHashes(online checkResult, dl downloadResult) error {
+	if online.SHA1 != "" && !strings.EqualFold(online.SHA1, dl.SHA1) {
+		return fmt.Errorf("SHA-1 do download (%s) difere do publicado pela origem (%s)", dl.SHA1, online.SHA1)
+	}
+	if online.ExpectedSHA256 != "" && !strings.EqualFold(online.ExpectedSHA256, dl.Checksum) {
+		return fmt.Errorf("SHA-256 do download (%s) difere do publicado em %s (%s)", dl.Checksum, online.ExpectedFrom, online.ExpectedSHA256)
+	}
+	return nil
+}
+
+// bodyChecksum extrai do texto da release o SHA-256 esperado de um asset. O regex
+// (body_checksum_regex) precisa de um grupo de captura com o hash e pode usar {asset}, trocado
+// pelo nome do asset escolhido, para achar a linha certa numa lista de vários arquivos.
+func bodyChecksum(expr, body, asset string) (string, error) {
+	re, err := regexp.Compile(strings.ReplaceAll(expr, "{asset}", regexp.QuoteMeta(asset)))
+	if err != nil {
+		return "", fmt.Errorf("body_checksum_regex inválido: %w", err)
+	}
+	if re.NumSubexp() < 1 {
+		return "", fmt.Errorf("body_checksum_regex precisa de um grupo de captura com o hash")
+	}
+	m := re.FindStringSubmatch(body)
+	if m == nil {
+		return "", fmt.Errorf("body_checksum_regex não casou com o texto da release")
+	}
+	if !sha256HexRe.MatchString(m[1]) {
+		return "", fmt.Errorf("body_checksum_regex capturou %q, que não é um SHA-256", m[1])
+	}
+	return strings.ToLower(m[1]), nil
+}
+
+// ------------------------------------------
+// Arquivos .sha256 irmãos do asset
+// ------------------------------------------
+
+// checksumSiblingSuffixes são os nomes de arquivo de checksum procurados ao lado do asset.
+var checksumSiblingSuffixes = []string{".sha256", ".sha256sum"}
+
+// checksumSiblingMaxSize limita a leitura do arquivo de checksum (uma ou poucas linhas).
+const checksumSiblingMaxSize = 64 << 10
+
+// findChecksumSibling procura, entre os nomes publicados junto ao asset, um "<asset>.sha256" ou
+// "<asset>.sha256sum"; devolve o índice em names ou -1.
+func findChecksumSibling(asset string, names []string) int {
+	for _, suffix := range checksumSiblingSuffixes {
+		for i, name := range names {
+			if strings.EqualFold(name, asset+suffix) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// loadSiblingChecksum lê o .sha256 irmão do asset (ChecksumURL) e preenche ExpectedSHA256.
+// Só é chamado quando o artefato vai ser baixado, para não custar uma requisição a mais nas
+// execuções em que a versão não mudou. Um hash já extraído de outra forma tem precedência.
+func loadSiblingChecksum(ctx context.Context, src SourceApp, online *checkResult) error {
+	if online.ChecksumURL == "" || online.ExpectedSHA256 != "" {
+		return nil
+	}
+	body, _, err := openArtifact(ctx, src, online.ChecksumURL)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// Formato do sha256sum: "<hash>  <arquivo>" (ou "<hash> *<arquivo>"), ou só o hash. Com
+	// várias linhas, vale a do asset; o nome vem da URL publicada.
+	asset := ""
+	if u, err := url.Parse(online.URL); err == nil {
+		asset = path.Base(u.Path)
+	}
+	var sums []string
+	scanner := bufio.NewScanner(io.LimitReader(body, checksumSiblingMaxSize))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || !sha256HexRe.MatchString(fields[0]) {
+			continue
+		}
+		if len(fields) > 1 && path.Base(strings.TrimPrefix(fields[1], "*")) == asset {
+			sums = []string{fields[0]}
+			break
+		}
+		sums = append(sums, fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(sums) != 1 {
+		return fmt.Errorf("esperado um SHA-256, encontrados %d", len(sums))
+	}
+	online.ExpectedSHA256 = strings.ToLower(sums[0])
+	return nil
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ==========================================
+// COMPARAÇÃO DE VERSÕES
+// ==========================================
+
+// compareSemver compara duas versões no estilo semver de forma tolerante: aceita prefixo "v",
+// quantidade variável de componentes ("1.2" < "1.2.1") e sufixo de prerelease ("1.0.0-rc.1" < "1.0.0").
+// Devolve -1, 0 ou 1. Componentes não numéricos são comparados como texto.
+func compareSemver(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+
+	// Metadados de build (+...) não participam da precedência
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+
+	aCore, aPre, _ := strings.Cut(a, "-")
+	bCore, bPre, _ := strings.Cut(b, "-")
+
+	if c := compareIdentifiers(strings.Split(aCore, "."), strings.Split(bCore, "."), true); c != 0 {
+		return c
+	}
+
+	// Sem prerelease tem precedência maior que com prerelease
+	switch {
+	case aPre == "" && bPre == "":
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareIdentifiers(strings.Split(aPre, "."), strings.Split(bPre, "."), false)
+}
+
+// compareIdentifiers compara listas de identificadores separados por ponto.
+// Com padZero, componentes ausentes valem zero ("1.2" == "1.2.0"); sem ele, a lista mais curta é menor.
+func compareIdentifiers(a, b []string, padZero bool) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		if i >= len(a) || i >= len(b) {
+			if !padZero {
+				if i >= len(a) {
+					return -1
+				}
+				return 1
+			}
+		}
+
+		x, y := "0", "0"
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+
+		xn, xErr := strconv.ParseUint(x, 10, 64)
+		yn, yErr := strconv.ParseUint(y, 10, 64)
+		switch {
+		case xErr == nil && yErr == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case xErr == nil: // Numéricos têm precedência menor que alfanuméricos
+			return -1
+		case yErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// ==========================================
+// NORMALIZAÇÃO DE VERSÕES
+// ==========================================
+
+// normalizeVersion deixa a versão resolvida pela estratégia no formato publicado no catálogo,
+// para que "v1.2.3", "1.2.3-1" e "1.2.3_amd64" do mesmo upstream não pareçam versões diferentes
+// entre execuções. Sempre remove espaços e um "v" inicial seguido de dígito; as regras da fonte
+// (config) são aplicadas em seguida, nesta ordem:
+//   - version_regex: regex aplicado à versão; o 1º grupo de captura vira a versão
+//   - version_trim_prefix: prefixos a remover, separados por vírgula (ex: "release-,rel_")
+//   - version_trim_suffix: sufixos a remover, separados por vírgula (ex: "_amd64,-1")
+func normalizeVersion(conf map[string]string, version string) (string, error) {
+	version = strings.TrimSpace(version)
+
+	if pattern := conf["version_regex"]; pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", fmt.Errorf("version_regex inválido: %w", err)
+		}
+		m := re.FindStringSubmatch(version)
+		if len(m) < 2 {
+			return "", fmt.Errorf("version_regex não casou com a versão %q", version)
+		}
+		version = m[1]
+	}
+	for _, prefix := range splitList(conf["version_trim_prefix"]) {
+		version = strings.TrimPrefix(version, prefix)
+	}
+	for _, suffix := range splitList(conf["version_trim_suffix"]) {
+		version = strings.TrimSuffix(version, suffix)
+	}
+
+	if len(version) > 1 && (version[0] == 'v' || version[0] == 'V') && version[1] >= '0' && version[1] <= '9' {
+		version = version[1:]
+	}
+	if version == "" {
+		return "", fmt.Errorf("versão vazia após a normalização")
+	}
+	return version, nil
+}
+
+// ==========================================
+// ESQUEMAS DE VERSÃO
+// ==========================================
+
+// Esquemas aceitos em config.version_scheme. O padrão é semver.
+const (
+	schemeSemver = "semver" // 1.2.3, 1.2.3-rc.1 (tolerante, ver compareSemver)
+	schemeDebian = "debian" // [epoch:]upstream[-revisão], ordem do dpkg (ex: 1:2.0~rc1-3)
+	schemeCalver = "calver" // 2024.05.01, 24.04 — componentes numéricos separados por . - _
+	schemeString = "string" // Texto opaco: só igualdade, sem noção de maior/menor
+)
+
+// versionScheme devolve o esquema declarado pela fonte (padrão: semver).
+func versionScheme(conf map[string]string) string {
+	if s := conf["version_scheme"]; s != "" {
+		return s
+	}
+	return schemeSemver
+}
+
+func validVersionScheme(scheme string) bool {
+	switch scheme {
+	case schemeSemver, schemeDebian, schemeCalver, schemeString:
+		return true
+	}
+	return false
+}
+
+// schemeOrdered indica se o esquema permite dizer qual versão é mais nova
+// (usado para detectar downgrades).
+func schemeOrdered(scheme string) bool {
+	return scheme != schemeString
+}
+
+// compareVersions compara a e b segundo o esquema (-1, 0 ou 1). Para "string", que não tem
+// ordem, a comparação é lexicográfica — serve só para escolher de forma estável entre candidatos.
+func compareVersions(scheme, a, b string) int {
+	switch scheme {
+	case schemeDebian:
+		return compareDebian(a, b)
+	case schemeCalver:
+		split := func(v string) []string {
+			return strings.FieldsFunc(v, func(r rune) bool { return r == '.' || r == '-' || r == '_' })
+		}
+		return compareIdentifiers(split(a), split(b), true)
+	case schemeString:
+		return strings.Compare(a, b)
+	default:
+		return compareSemver(a, b)
+	}
+}
+
+// compareDebian segue o algoritmo do dpkg: epoch numérico, depois upstream e revisão
+// comparados por verrevcmp ("~" ordena antes de tudo, inclusive do fim da string).
+func compareDebian(a, b string) int {
+	aEpoch, aRest := debianEpoch(a)
+	bEpoch, bRest := debianEpoch(b)
+	if aEpoch != bEpoch {
+		if aEpoch < bEpoch {
+			return -1
+		}
+		return 1
+	}
+
+	aUp, aRev := aRest, ""
+	if i := strings.LastIndex(aRest, "-"); i >= 0 {
+		aUp, aRev = aRest[:i], aRest[i+1:]
+	}
+	bUp, bRev := bRest, ""
+	if i := strings.LastIndex(bRest, "-"); i >= 0 {
+		bUp, bRev = bRest[:i], bRest[i+1:]
+	}
+	if c := debianVerRevCmp(aUp, bUp); c != 0 {
+		return c
+	}
+	return debianVerRevCmp(aRev, bRev)
+}
+
+func debianEpoch(v string) (uint64, string) {
+	if i := strings.Index(v, ":"); i >= 0 {
+		if epoch, err := strconv.ParseUint(v[:i], 10, 64); err == nil {
+			return epoch, v[i+1:]
+		}
+	}
+	return 0, v
+}
+
+func debianVerRevCmp(a, b string) int {
+	order := func(c byte) int {
+		switch {
+		case c >= '0' && c <= '9':
+			return 0
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			return int(c)
+		case c == '~':
+			return -1
+		case c != 0:
+			return int(c) + 256
+		}
+		return 0
+	}
+	at := func(s string, i int) byte {
+		if i < len(s) {
+			return s[i]
+		}
+		return 0
+	}
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		// Parte não numérica
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			ac, bc := order(at(a, i)), order(at(b, j))
+			if i < len(a) && isDigit(a[i]) {
+				ac = 0
+			}
+			if j < len(b) && isDigit(b[j]) {
+				bc = 0
+			}
+			if ac != bc {
+				if ac < bc {
+					return -1
+				}
+				return 1
+			}
+			i++
+			j++
+		}
+		// Parte numérica
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		diff := 0
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if diff == 0 {
+				diff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if diff != 0 {
+			if diff < 0 {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
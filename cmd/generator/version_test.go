@@ -0,0 +1,97 @@
+package main
+
+import "testing"
+
+// versionOrder verifica a ordem nos dois sentidos (a < b implica b > a).
+func versionOrder(t *testing.T, scheme, a, b string, want int) {
+	t.Helper()
+	if got := compareVersions(scheme, a, b); got != want {
+		t.Errorf("%s: compare(%q, %q) = %d, esperado %d", scheme, a, b, got, want)
+	}
+	if got := compareVersions(scheme, b, a); got != -want {
+		t.Errorf("%s: compare(%q, %q) = %d, esperado %d", scheme, b, a, got, -want)
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.2", "1.2.1", -1},
+		{"1.9.0", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0+build.5", "1.0.0+build.9", 0},
+		{"1.0.0-rc.1+build.1", "1.0.0", -1},
+	}
+	for _, tt := range tests {
+		versionOrder(t, schemeSemver, tt.a, tt.b, tt.want)
+	}
+}
+
+// A precedência completa do exemplo da especificação semver.
+func TestCompareSemverChain(t *testing.T) {
+	chain := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0",
+	}
+	for i := 1; i < len(chain); i++ {
+		versionOrder(t, schemeSemver, chain[i-1], chain[i], -1)
+	}
+}
+
+func TestCompareDebian(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1:1.0", "2.0", 1},
+		{"0:1.0", "1.0", 0},
+		{"1:1.0", "2:0.1", -1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~~", "1.0~", -1},
+		{"1.0~rc1", "1.0~rc2", -1},
+		{"1.0", "1.0a", -1},
+		{"1.0a", "1.0+b1", -1},
+		{"1.0-1", "1.0-2", -1},
+		{"1.0-9", "1.0-10", -1},
+		{"1.0-1", "1.0.1-1", -1},
+		{"1.0-1ubuntu1", "1.0-1", 1},
+		{"2.30-0ubuntu1~22.04", "2.30-0ubuntu1", -1},
+		{"1.01", "1.1", 0},
+		{"1.2-3-4", "1.2-3-5", -1},
+	}
+	for _, tt := range tests {
+		versionOrder(t, schemeDebian, tt.a, tt.b, tt.want)
+	}
+}
+
+func TestCompareCalver(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2024.01.15", "2024.1.15", 0},
+		{"2024.01", "2024.01.0", 0},
+		{"2024.09", "2024.10", -1},
+		{"2023.12.31", "2024.01.01", -1},
+		{"2024.10.2", "2024.10.10", -1},
+		{"24.04", "24.10", -1},
+		{"2024-10-01", "2024.10.01", 0},
+		{"2024_10_02", "2024.10.01", 1},
+		{"2024.10", "2024.10.1", -1},
+	}
+	for _, tt := range tests {
+		versionOrder(t, schemeCalver, tt.a, tt.b, tt.want)
+	}
+}